@@ -0,0 +1,238 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package age provides a SecretClient implementation reading secrets from a JSON file
+// encrypted with age (https://age-encryption.org), for GitOps workflows committing
+// encrypted secrets to the repository without SOPS. Both binary and ASCII-armored files
+// are supported.
+package age
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	agelib "filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+var (
+	// ErrNoIdentity is returned by LoadSecrets when no identity was configured.
+	ErrNoIdentity = errors.New("no age identity configured")
+
+	// ErrNoMatchingIdentity is returned when none of the configured identities can decrypt
+	// the file, typically because it was encrypted to other recipients.
+	ErrNoMatchingIdentity = errors.New("no age identity matches the file recipients")
+
+	// ErrCorruptedFile is returned when the file isn't a valid age file or fails
+	// authentication, e.g. because it was truncated or tampered with. The error never
+	// includes the file content, whose header holds the wrapped file keys.
+	ErrCorruptedFile = errors.New("age file is malformed or corrupted")
+
+	// ErrMalformedPlaintext is returned when the decrypted file isn't a JSON object of
+	// strings. The error never includes the plaintext.
+	ErrMalformedPlaintext = errors.New("decrypted age file is not a JSON object of strings")
+)
+
+type (
+	// ageSecretClient is an implementation of the SecretClient interface decrypting an
+	// age-encrypted JSON file into an in-memory cache.
+	ageSecretClient struct {
+		path          string            // Path of the encrypted file
+		identityFiles []string          // Files holding the decryption identities
+		identities    []agelib.Identity // Identities provided directly
+
+		mu      sync.RWMutex
+		secrets map[string]redact.String // In-memory cache of secret key-value pairs
+	}
+
+	// Option configures optional behavior of the age client.
+	Option func(*ageSecretClient)
+)
+
+// WithIdentityFile adds the identities of the file at path, either an age identity file
+// as generated by age-keygen, holding one or more AGE-SECRET-KEY-1 lines, or an
+// unencrypted OpenSSH ed25519 or RSA private key. The file is read on every load.
+//
+// Keys held by an ssh-agent through SSH_AUTH_SOCK cannot be used: decrypting age files
+// requires the private key itself, which agents never expose.
+func WithIdentityFile(path string) Option {
+	return func(c *ageSecretClient) {
+		c.identityFiles = append(c.identityFiles, path)
+	}
+}
+
+// WithIdentities adds already parsed identities, such as ones read from a hardware
+// token plugin or a secret injected by the platform.
+func WithIdentities(identities ...agelib.Identity) Option {
+	return func(c *ageSecretClient) {
+		c.identities = append(c.identities, identities...)
+	}
+}
+
+// NewAgeSecretClient creates a client decrypting the age-encrypted JSON file at path
+// with the configured identities.
+//
+// Parameters:
+//   - path: Path of the encrypted JSON file
+//   - opts: Identities used to decrypt the file
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the encrypted file
+func NewAgeSecretClient(path string, opts ...Option) sm.SecretClient {
+	c := &ageSecretClient{
+		path:    path,
+		secrets: make(map[string]redact.String),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// LoadSecrets decrypts the file and replaces the in-memory cache with its JSON object.
+// The cache is left untouched on failure, and errors never include the ciphertext nor
+// the plaintext.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//
+// Returns:
+//   - ErrNoIdentity if no identity was configured
+//   - ErrNoMatchingIdentity if no identity can decrypt the file
+//   - ErrMalformedPlaintext if the decrypted content isn't a JSON object of strings
+//   - An error if a file cannot be read or an identity cannot be parsed
+func (c *ageSecretClient) LoadSecrets(_ context.Context) error {
+	identities, err := c.loadIdentities()
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := c.decrypt(identities)
+	if err != nil {
+		return err
+	}
+
+	var secrets map[string]string
+	if err := json.Unmarshal(plaintext, &secrets); err != nil || secrets == nil {
+		return ErrMalformedPlaintext
+	}
+
+	c.mu.Lock()
+	c.secrets = redact.Map(secrets)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *ageSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
+
+// loadIdentities returns the configured identities followed by those of the identity files.
+func (c *ageSecretClient) loadIdentities() ([]agelib.Identity, error) {
+	identities := append([]agelib.Identity(nil), c.identities...)
+
+	for _, path := range c.identityFiles {
+		parsed, err := parseIdentityFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		identities = append(identities, parsed...)
+	}
+
+	if len(identities) == 0 {
+		return nil, ErrNoIdentity
+	}
+
+	return identities, nil
+}
+
+// decrypt reads and decrypts the encrypted file, unwrapping its ASCII armor if any.
+func (c *ageSecretClient) decrypt(identities []agelib.Identity) ([]byte, error) {
+	f, err := os.Open(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("read age file: %w", err)
+	}
+	defer f.Close()
+
+	in := bufio.NewReader(f)
+
+	var src io.Reader = in
+	if header, _ := in.Peek(len(armor.Header)); string(header) == armor.Header {
+		src = armor.NewReader(in)
+	}
+
+	r, err := agelib.Decrypt(src, identities...)
+	if err != nil {
+		var noMatch *agelib.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, fmt.Errorf("decrypt %s: %w", c.path, ErrNoMatchingIdentity)
+		}
+
+		// Header parsing errors may quote the header, so they're not wrapped
+		return nil, fmt.Errorf("decrypt %s: %w", c.path, ErrCorruptedFile)
+	}
+
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", c.path, ErrCorruptedFile)
+	}
+
+	return plaintext, nil
+}
+
+// parseIdentityFile parses the age or OpenSSH identities of the file at path.
+func parseIdentityFile(path string) ([]agelib.Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read age identity file: %w", err)
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		identity, err := agessh.ParseIdentity(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse ssh identity %s: %w", path, err)
+		}
+
+		return []agelib.Identity{identity}, nil
+	}
+
+	identities, err := agelib.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse age identity file %s: %w", path, err)
+	}
+
+	return identities, nil
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package age_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	agelib "filippo.io/age"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/age"
+)
+
+func TestAgeSecretClient(t *testing.T) {
+	for _, file := range []string{"secrets.json.age", "secrets.json.age.asc"} {
+		t.Run(file, func(t *testing.T) {
+			c := age.NewAgeSecretClient(filepath.Join("testdata", file),
+				age.WithIdentityFile("testdata/other_identity.txt"),
+				age.WithIdentityFile("testdata/identity.txt"),
+			)
+
+			ctx := context.Background()
+			if err := c.LoadSecrets(ctx); err != nil {
+				t.Fatalf("LoadSecrets() error = %v", err)
+			}
+
+			for key, want := range map[string]string{"db_password": "s3cret", "api_token": "t0ken"} {
+				if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+					t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+				}
+			}
+			if _, err := c.GetSecret(ctx, "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+				t.Errorf("GetSecret() error = %v, want ErrSecretNotFound", err)
+			}
+		})
+	}
+}
+
+// encryptFile encrypts plaintext to identity into a new file, returning its path.
+func encryptFile(t *testing.T, identity *agelib.X25519Identity, plaintext string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := agelib.Encrypt(&buf, identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(plaintext)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "secrets.json.age")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestAgeSecretClientErrors(t *testing.T) {
+	identity, err := agelib.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := encryptFile(t, identity, `{"db_password":"s3cret"}`)
+	truncated := filepath.Join(t.TempDir(), "truncated.age")
+	data, _ := os.ReadFile(valid)
+	if err := os.WriteFile(truncated, data[:len(data)-8], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		client  sm.SecretClient
+		wantErr error
+	}{
+		{name: "no identity", client: age.NewAgeSecretClient(valid), wantErr: age.ErrNoIdentity},
+		{
+			name:    "other recipient",
+			client:  age.NewAgeSecretClient(valid, age.WithIdentityFile("testdata/identity.txt")),
+			wantErr: age.ErrNoMatchingIdentity,
+		},
+		{
+			name:    "truncated",
+			client:  age.NewAgeSecretClient(truncated, age.WithIdentities(identity)),
+			wantErr: age.ErrCorruptedFile,
+		},
+		{
+			name:    "not age",
+			client:  age.NewAgeSecretClient("testdata/identity.txt", age.WithIdentities(identity)),
+			wantErr: age.ErrCorruptedFile,
+		},
+		{
+			name:    "not an object",
+			client:  age.NewAgeSecretClient(encryptFile(t, identity, `["s3cret"]`), age.WithIdentities(identity)),
+			wantErr: age.ErrMalformedPlaintext,
+		},
+		{
+			name:    "missing file",
+			client:  age.NewAgeSecretClient(filepath.Join(t.TempDir(), "missing.age"), age.WithIdentities(identity)),
+			wantErr: os.ErrNotExist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.client.LoadSecrets(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error %q exposes a value", err)
+			}
+		})
+	}
+}
+
+func TestAgeSecretClientFailedLoadKeepsCache(t *testing.T) {
+	identity, err := agelib.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := encryptFile(t, identity, `{"db_password":"s3cret"}`)
+	c := age.NewAgeSecretClient(path, age.WithIdentities(identity))
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("garbage"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadSecrets(ctx); !errors.Is(err, age.ErrCorruptedFile) {
+		t.Fatalf("LoadSecrets() error = %v, want ErrCorruptedFile", err)
+	}
+
+	if value, err := c.GetSecret(ctx, "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v, want the cache kept by the failed load", value, err)
+	}
+}
@@ -0,0 +1,420 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package appconfig provides an AWS AppConfig implementation of the SecretClient interface.
+// It retrieves a JSON configuration profile through the AppConfig Data API and exposes its
+// key-value pairs using the consistent API defined by the secretsmanager package.
+package appconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/goxkit/configs"
+	"github.com/goxkit/logging"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/logs"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+const (
+	// DefaultProfile is the configuration profile used when none is provided.
+	DefaultProfile = "secrets"
+
+	// DefaultDrainTimeout is how long Close waits for an in-flight poll by default.
+	DefaultDrainTimeout = 10 * time.Second
+
+	// minPollInterval is the smallest poll interval accepted by the AppConfig Data API.
+	minPollInterval = 15 * time.Second
+
+	// maxBodySize is the largest configuration accepted.
+	maxBodySize = 4 << 20
+)
+
+var (
+	// ErrDrainTimeout is returned by Close when an in-flight poll didn't complete in time.
+	ErrDrainTimeout = errors.New("timed out waiting for the in-flight appconfig poll")
+
+	// ErrMalformedConfiguration is returned when the configuration isn't a JSON object of
+	// strings. The error never includes the configuration content.
+	ErrMalformedConfiguration = errors.New("appconfig configuration is not a JSON object of strings")
+
+	// ErrConfigurationTooLarge is returned when the configuration exceeds the size accepted
+	// by the client.
+	ErrConfigurationTooLarge = errors.New("appconfig configuration is too large")
+)
+
+type (
+	// appConfigDataAPI is the subset of the AppConfig Data client used by this package.
+	// It exists so the client can be replaced by a mock in tests.
+	appConfigDataAPI interface {
+		StartConfigurationSession(
+			ctx context.Context,
+			params *appconfigdata.StartConfigurationSessionInput,
+			optFns ...func(*appconfigdata.Options),
+		) (*appconfigdata.StartConfigurationSessionOutput, error)
+
+		GetLatestConfiguration(
+			ctx context.Context,
+			params *appconfigdata.GetLatestConfigurationInput,
+			optFns ...func(*appconfigdata.Options),
+		) (*appconfigdata.GetLatestConfigurationOutput, error)
+	}
+
+	// appConfigSecretClient is an implementation of the SecretClient interface that uses
+	// AWS AppConfig to retrieve secrets. The configuration is fetched as a JSON document and
+	// kept in an in-memory cache, optionally refreshed by a background poller.
+	appConfigSecretClient struct {
+		logger       logging.Logger
+		client       appConfigDataAPI
+		application  string        // AppConfig application identifier
+		environment  string        // AppConfig environment identifier
+		profile      string        // AppConfig configuration profile identifier
+		pollInterval time.Duration // Background polling interval, zero disables polling
+		onChange     func([]sm.ChangeEvent)
+
+		loadMu sync.Mutex // Serializes session and token handling
+		token  *string    // Next configuration token returned by AppConfig
+
+		mu      sync.RWMutex
+		secrets map[string]redact.String // In-memory cache of secret key-value pairs
+
+		pollOnce     sync.Once
+		closeOnce    sync.Once
+		closeErr     error
+		drainTimeout time.Duration      // How long Close waits for an in-flight poll
+		pollCtx      context.Context    // Context of the polls, canceled when draining times out
+		abort        context.CancelFunc // Cancels pollCtx
+		stop         chan struct{}
+		done         chan struct{}
+	}
+
+	// Option configures optional behavior of the AppConfig client.
+	Option func(*appConfigSecretClient)
+)
+
+// WithProfile sets the AppConfig configuration profile to read. Defaults to DefaultProfile.
+func WithProfile(profile string) Option {
+	return func(c *appConfigSecretClient) {
+		c.profile = profile
+	}
+}
+
+// WithPollInterval enables background polling for new configuration versions after the
+// first successful LoadSecrets. Intervals below the AppConfig minimum of 15 seconds are raised
+// to that minimum. A zero interval disables polling, which is the default.
+func WithPollInterval(interval time.Duration) Option {
+	return func(c *appConfigSecretClient) {
+		c.pollInterval = interval
+	}
+}
+
+// WithDrainTimeout sets how long Close waits for an in-flight poll to complete before
+// canceling it. Defaults to DefaultDrainTimeout.
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(c *appConfigSecretClient) {
+		c.drainTimeout = timeout
+	}
+}
+
+// WithOnChange registers fn to be called with the changed keys whenever a load or a
+// background poll retrieves a configuration version that differs from the cached one.
+// It's called synchronously, after the cache was updated, and never receives values.
+func WithOnChange(fn func([]sm.ChangeEvent)) Option {
+	return func(c *appConfigSecretClient) {
+		c.onChange = fn
+	}
+}
+
+// NewAppConfigSecretClient creates a new instance of the AWS AppConfig client.
+//
+// It initializes the AWS configuration using the default credential providers chain and
+// maps the application configuration onto AppConfig identifiers: the secret key becomes the
+// AppConfig application, the environment becomes the AppConfig environment, and the
+// configuration profile defaults to DefaultProfile.
+//
+// Parameters:
+//   - cfgs: Application configuration containing environment, secret key, and logger
+//   - opts: Optional settings such as the configuration profile or poll interval
+//
+// Returns:
+//   - A SecretClient interface implementation for AWS AppConfig
+//   - An error if AWS configuration cannot be loaded
+func NewAppConfigSecretClient(cfgs *configs.Configs, opts ...Option) (sm.SecretClient, error) {
+	logger := logs.FromConfigs(cfgs)
+
+	awsCfg, err := config.LoadDefaultConfig(context.Background())
+	if err != nil {
+		logger.Error("error get aws configs from env", zap.Error(err))
+		return nil, err
+	}
+
+	pollCtx, abort := context.WithCancel(context.Background())
+
+	c := &appConfigSecretClient{
+		logger:       logger,
+		client:       appconfigdata.NewFromConfig(awsCfg),
+		application:  cfgs.AppConfigs.SecretKey,
+		environment:  cfgs.AppConfigs.Environment.ToString(),
+		profile:      DefaultProfile,
+		secrets:      make(map[string]redact.String),
+		drainTimeout: DefaultDrainTimeout,
+		pollCtx:      pollCtx,
+		abort:        abort,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.pollInterval > 0 && c.pollInterval < minPollInterval {
+		c.pollInterval = minPollInterval
+	}
+
+	return c, nil
+}
+
+// LoadSecrets retrieves the latest configuration from AWS AppConfig into the cache.
+//
+// The first call starts a configuration session. Every call then exchanges the current
+// configuration token for the latest configuration and the token to use on the next call.
+// AppConfig returns an empty payload when the configuration hasn't changed since the previous
+// call, in which case the cache is left untouched.
+//
+// When polling is enabled, the first successful call starts the background poller.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - An error if the configuration cannot be fetched or parsed
+func (c *appConfigSecretClient) LoadSecrets(ctx context.Context) error {
+	if _, err := c.fetch(ctx); err != nil {
+		return err
+	}
+
+	if c.pollInterval > 0 {
+		c.pollOnce.Do(func() { go c.poll() })
+	}
+
+	return nil
+}
+
+// Reload refreshes the in-memory cache by loading the secrets again.
+// It implements the secretsmanager.Reloadable interface.
+func (c *appConfigSecretClient) Reload(ctx context.Context) error {
+	return c.LoadSecrets(ctx)
+}
+
+// Refresh fetches the latest configuration and returns the keys that changed from the
+// cached one, none when AppConfig reports no new version.
+// It implements the secretsmanager.Refresher interface.
+func (c *appConfigSecretClient) Refresh(ctx context.Context) ([]sm.ChangeEvent, error) {
+	return c.fetch(ctx)
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *appConfigSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
+
+// Snapshot returns a copy of the whole in-memory cache.
+// The returned map contains plaintext secret values and should be handled carefully.
+func (c *appConfigSecretClient) Snapshot(_ context.Context) (map[string]string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return redact.RevealMap(c.secrets), nil
+}
+
+// Close stops the background poller, if running, and waits for it to exit.
+//
+// A poll in flight is given the drain timeout to complete so the cache is never left
+// half-updated. When it doesn't complete in time, it is canceled and ErrDrainTimeout is
+// returned. It is safe to call Close more than once.
+func (c *appConfigSecretClient) Close() error {
+	c.closeOnce.Do(func() {
+		defer c.abort()
+
+		// Consuming pollOnce here also prevents a later LoadSecrets from starting a poller
+		polling := true
+		c.pollOnce.Do(func() { polling = false })
+
+		close(c.stop)
+		if !polling {
+			return
+		}
+
+		timer := time.NewTimer(c.drainTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-c.done:
+		case <-timer.C:
+			c.logger.Warn("appconfig poll did not drain in time, canceling it")
+			c.abort()
+			<-c.done
+			c.closeErr = ErrDrainTimeout
+		}
+	})
+
+	return c.closeErr
+}
+
+// fetch starts a configuration session if needed, retrieves the latest configuration and
+// returns the keys it changed, notifying the change callback.
+func (c *appConfigSecretClient) fetch(ctx context.Context) ([]sm.ChangeEvent, error) {
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	if c.token == nil {
+		session, err := c.client.StartConfigurationSession(ctx, &appconfigdata.StartConfigurationSessionInput{
+			ApplicationIdentifier:          aws.String(c.application),
+			EnvironmentIdentifier:          aws.String(c.environment),
+			ConfigurationProfileIdentifier: aws.String(c.profile),
+		})
+		if err != nil {
+			c.logger.Error("error to start appconfig session", zap.Error(err))
+			return nil, err
+		}
+
+		c.token = session.InitialConfigurationToken
+	}
+
+	res, err := c.client.GetLatestConfiguration(ctx, &appconfigdata.GetLatestConfigurationInput{
+		ConfigurationToken: c.token,
+	}, limitResponseBody(maxBodySize))
+	if err != nil {
+		// Tokens are single use and expire, so start a new session on the next attempt
+		c.token = nil
+		c.logger.Error("error to get latest appconfig configuration", zap.Error(err))
+		return nil, err
+	}
+
+	// An empty configuration means nothing changed since the previous call
+	if len(res.Configuration) == 0 {
+		c.token = res.NextPollConfigurationToken
+		return nil, nil
+	}
+
+	// On failure, the next token would only report later changes and never serve this
+	// configuration again, so start a new session returning it in full on the next attempt
+	if len(res.Configuration) > maxBodySize {
+		c.token = nil
+		c.logger.Error("appconfig configuration exceeds the size limit", zap.Int("limit", maxBodySize))
+		return nil, fmt.Errorf("%w: limit %d bytes", ErrConfigurationTooLarge, maxBodySize)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(res.Configuration, &secrets); err != nil {
+		c.token = nil
+		err = describeJSONError(err)
+		c.logger.Error("error parse appconfig configuration", zap.Error(err))
+		return nil, err
+	}
+
+	c.token = res.NextPollConfigurationToken
+
+	c.mu.Lock()
+	previous := c.secrets
+	c.secrets = redact.Map(secrets)
+	c.mu.Unlock()
+
+	changes := sm.DiffSecrets(redact.RevealMap(previous), secrets)
+	if len(changes) > 0 && c.onChange != nil {
+		c.onChange(changes)
+	}
+
+	return changes, nil
+}
+
+// limitResponseBody reads at most limit+1 bytes of the response body, so an oversized
+// configuration is detected without being read in full.
+func limitResponseBody(limit int64) func(*appconfigdata.Options) {
+	return func(o *appconfigdata.Options) {
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			// Added last, so it runs between the transport and the operation deserializer
+			return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("LimitResponseBody",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					middleware.DeserializeOutput, middleware.Metadata, error,
+				) {
+					out, metadata, err := next.HandleDeserialize(ctx, in)
+					if res, ok := out.RawResponse.(*smithyhttp.Response); ok && res.Body != nil {
+						res.Body = struct {
+							io.Reader
+							io.Closer
+						}{io.LimitReader(res.Body, limit+1), res.Body}
+					}
+
+					return out, metadata, err
+				}), middleware.After)
+		})
+	}
+}
+
+// describeJSONError turns a JSON decoding error into one locating the problem by byte
+// offset. The errors of encoding/json quote the offending character, which is part of
+// the configuration, so they're never wrapped nor echoed.
+func describeJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: syntax error at byte offset %d", ErrMalformedConfiguration, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%w: value of the wrong type at byte offset %d", ErrMalformedConfiguration, typeErr.Offset)
+	default:
+		return ErrMalformedConfiguration
+	}
+}
+
+// poll refreshes the cache on every poll interval until Close is called.
+func (c *appConfigSecretClient) poll() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if _, err := c.fetch(c.pollCtx); err != nil {
+				c.logger.Warn("error to poll appconfig configuration", zap.Error(err))
+			}
+		}
+	}
+}
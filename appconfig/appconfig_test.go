@@ -0,0 +1,440 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package appconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/goxkit/configs"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+// mockAppConfigData serves a fixed configuration through the AppConfig Data API.
+type mockAppConfigData struct {
+	configuration []byte
+	sessions      int
+}
+
+func (m *mockAppConfigData) StartConfigurationSession(
+	_ context.Context,
+	_ *appconfigdata.StartConfigurationSessionInput,
+	_ ...func(*appconfigdata.Options),
+) (*appconfigdata.StartConfigurationSessionOutput, error) {
+	m.sessions++
+	return &appconfigdata.StartConfigurationSessionOutput{InitialConfigurationToken: aws.String("initial")}, nil
+}
+
+func (m *mockAppConfigData) GetLatestConfiguration(
+	_ context.Context,
+	_ *appconfigdata.GetLatestConfigurationInput,
+	_ ...func(*appconfigdata.Options),
+) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	return &appconfigdata.GetLatestConfigurationOutput{
+		Configuration:              m.configuration,
+		NextPollConfigurationToken: aws.String("next"),
+	}, nil
+}
+
+func newTestClient(api appConfigDataAPI) *appConfigSecretClient {
+	pollCtx, abort := context.WithCancel(context.Background())
+
+	return &appConfigSecretClient{
+		logger:       zap.NewNop(),
+		client:       api,
+		profile:      DefaultProfile,
+		secrets:      map[string]redact.String{},
+		drainTimeout: DefaultDrainTimeout,
+		pollCtx:      pollCtx,
+		abort:        abort,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+func TestLoadSecrets(t *testing.T) {
+	c := newTestClient(&mockAppConfigData{configuration: []byte(`{"db_password":"s3cret"}`)})
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	value, err := c.GetSecret(context.Background(), "db_password")
+	if err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v, want %q", value, err, "s3cret")
+	}
+
+	if snapshot, _ := c.Snapshot(context.Background()); snapshot["db_password"] != "s3cret" {
+		t.Errorf("Snapshot() = %v, want the plaintext value", snapshot)
+	}
+	if dump := fmt.Sprintf("%v %+v", c.secrets, c); strings.Contains(dump, "s3cret") {
+		t.Errorf("formatting the client exposes a value: %s", dump)
+	}
+}
+
+func TestLoadSecretsMalformedConfiguration(t *testing.T) {
+	tests := map[string]string{
+		"syntax":     `{"db_password":"s3cret",s3cret}`,
+		"type":       `{"db_password":["s3cret"]}`,
+		"not object": `"s3cret"`,
+	}
+
+	for name, configuration := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(&mockAppConfigData{configuration: []byte(configuration)})
+
+			err := c.LoadSecrets(context.Background())
+			if !errors.Is(err, ErrMalformedConfiguration) {
+				t.Fatalf("LoadSecrets() error = %v, want ErrMalformedConfiguration", err)
+			}
+
+			if strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error %q echoes the configuration", err)
+			}
+		})
+	}
+}
+
+func TestLoadSecretsConfigurationTooLarge(t *testing.T) {
+	configuration := `{"key":"` + strings.Repeat("x", maxBodySize) + `"}`
+	c := newTestClient(&mockAppConfigData{configuration: []byte(configuration)})
+
+	if err := c.LoadSecrets(context.Background()); !errors.Is(err, ErrConfigurationTooLarge) {
+		t.Fatalf("LoadSecrets() error = %v, want ErrConfigurationTooLarge", err)
+	}
+
+	if _, err := c.GetSecret(context.Background(), "key"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want the oversized configuration ignored", err)
+	}
+}
+
+// sessionAppConfigData serves the configuration like AppConfig does: in full on the first
+// call of a session, then empty until it changes.
+type sessionAppConfigData struct {
+	configuration []byte
+	sessions      int
+}
+
+func (m *sessionAppConfigData) StartConfigurationSession(
+	_ context.Context,
+	_ *appconfigdata.StartConfigurationSessionInput,
+	_ ...func(*appconfigdata.Options),
+) (*appconfigdata.StartConfigurationSessionOutput, error) {
+	m.sessions++
+	return &appconfigdata.StartConfigurationSessionOutput{InitialConfigurationToken: aws.String("initial")}, nil
+}
+
+func (m *sessionAppConfigData) GetLatestConfiguration(
+	_ context.Context,
+	params *appconfigdata.GetLatestConfigurationInput,
+	_ ...func(*appconfigdata.Options),
+) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	res := &appconfigdata.GetLatestConfigurationOutput{NextPollConfigurationToken: aws.String("next")}
+	if aws.ToString(params.ConfigurationToken) == "initial" {
+		res.Configuration = m.configuration
+	}
+
+	return res, nil
+}
+
+func TestLoadSecretsRetryAfterInvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration string
+		wantErr       error
+	}{
+		{name: "malformed", configuration: `{"db_password":"s3cret",}`, wantErr: ErrMalformedConfiguration},
+		{name: "oversized", configuration: `{"key":"` + strings.Repeat("x", maxBodySize) + `"}`, wantErr: ErrConfigurationTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &sessionAppConfigData{configuration: []byte(tt.configuration)}
+			c := newTestClient(api)
+
+			for attempt := range 2 {
+				if err := c.LoadSecrets(context.Background()); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("LoadSecrets() attempt %d error = %v, want %v", attempt+1, err, tt.wantErr)
+				}
+			}
+
+			api.configuration = []byte(`{"db_password":"fixed"}`)
+			if err := c.LoadSecrets(context.Background()); err != nil {
+				t.Fatalf("LoadSecrets() error = %v once the configuration is fixed", err)
+			}
+			if value, _ := c.GetSecret(context.Background(), "db_password"); value != "fixed" {
+				t.Errorf("GetSecret() = %q, want the configuration fetched again", value)
+			}
+			if api.sessions != 3 {
+				t.Errorf("sessions = %d, want a new session after every failed attempt", api.sessions)
+			}
+		})
+	}
+}
+
+// countingTransport counts the response bytes read by the client.
+type countingTransport struct {
+	read atomic.Int64
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(res.Body, writerFunc(func(p []byte) { t.read.Add(int64(len(p))) })), res.Body}
+	}
+
+	return res, err
+}
+
+type writerFunc func([]byte)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	f(p)
+	return len(p), nil
+}
+
+func TestLoadSecretsLimitsResponseBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/configurationsessions" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"InitialConfigurationToken":"initial"}`)
+			return
+		}
+
+		w.Header().Set("Next-Poll-Configuration-Token", "next")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"key":"`+strings.Repeat("x", 4*maxBodySize)+`"}`)
+	}))
+	defer srv.Close()
+
+	transport := &countingTransport{}
+	c := newTestClient(appconfigdata.New(appconfigdata.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(srv.URL),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   &http.Client{Transport: transport},
+	}))
+
+	if err := c.LoadSecrets(context.Background()); !errors.Is(err, ErrConfigurationTooLarge) {
+		t.Fatalf("LoadSecrets() error = %v, want ErrConfigurationTooLarge", err)
+	}
+
+	// The session response is small, so reading past the limit means the body wasn't capped
+	if read := transport.read.Load(); read > 2*maxBodySize {
+		t.Errorf("read %d bytes of the response, want at most about %d", read, maxBodySize)
+	}
+}
+
+func TestLoadSecretsUnchangedConfiguration(t *testing.T) {
+	api := &mockAppConfigData{configuration: []byte(`{"db_password":"s3cret"}`)}
+	c := newTestClient(api)
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// AppConfig answers with an empty payload when the version didn't change
+	api.configuration = nil
+	if err := c.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if value, _ := c.GetSecret(context.Background(), "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want the cached value kept", value)
+	}
+
+	if api.sessions != 1 {
+		t.Errorf("sessions = %d, want the token reused", api.sessions)
+	}
+}
+
+func TestNewAppConfigSecretClientNilLogger(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: "app"}}
+
+	c, err := NewAppConfigSecretClient(cfgs)
+	if err != nil {
+		t.Fatalf("NewAppConfigSecretClient() error = %v", err)
+	}
+
+	// A canceled load fails before reaching AWS, going through the error logging
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.LoadSecrets(ctx); err == nil {
+		t.Error("LoadSecrets() succeeded with a canceled context")
+	}
+}
+
+// blockingAppConfigData answers the first call right away, then holds every poll until
+// release is closed or its context is canceled.
+type blockingAppConfigData struct {
+	mockAppConfigData
+
+	calls    atomic.Int32
+	inFlight chan struct{} // Receives a value when a poll starts
+	release  chan struct{}
+}
+
+func newBlockingAppConfigData() *blockingAppConfigData {
+	return &blockingAppConfigData{
+		mockAppConfigData: mockAppConfigData{configuration: []byte(`{"api_token":"v1"}`)},
+		inFlight:          make(chan struct{}, 1),
+		release:           make(chan struct{}),
+	}
+}
+
+func (b *blockingAppConfigData) GetLatestConfiguration(
+	ctx context.Context,
+	_ *appconfigdata.GetLatestConfigurationInput,
+	_ ...func(*appconfigdata.Options),
+) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	configuration := []byte(`{"api_token":"v1"}`)
+
+	if b.calls.Add(1) > 1 {
+		select {
+		case b.inFlight <- struct{}{}:
+		default:
+		}
+
+		select {
+		case <-b.release:
+			configuration = []byte(`{"api_token":"v2"}`)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return &appconfigdata.GetLatestConfigurationOutput{
+		Configuration:              configuration,
+		NextPollConfigurationToken: aws.String("next"),
+	}, nil
+}
+
+// startPolling loads c and waits until a background poll is in flight.
+func startPolling(t *testing.T, c *appConfigSecretClient, api *blockingAppConfigData) {
+	t.Helper()
+
+	c.pollInterval = time.Millisecond
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-api.inFlight:
+	case <-time.After(time.Second):
+		t.Fatal("no poll started")
+	}
+}
+
+// TestCloseDrainsInFlightPoll is meant to run with -race.
+func TestCloseDrainsInFlightPoll(t *testing.T) {
+	api := newBlockingAppConfigData()
+	c := newTestClient(api)
+	startPolling(t, c, api)
+
+	closed := make(chan error, 1)
+	go func() { closed <- c.Close() }()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("Close() = %v returned while a poll was in flight", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(api.release)
+
+	if err := <-closed; err != nil {
+		t.Fatalf("Close() error = %v, want the poll drained", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "api_token"); value != "v2" {
+		t.Errorf("GetSecret() = %q, want the drained poll applied", value)
+	}
+
+	// The poller is gone, so no call happens after Close
+	calls := api.calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	if got := api.calls.Load(); got != calls {
+		t.Errorf("calls = %d after Close, want %d", got, calls)
+	}
+}
+
+// TestCloseDrainTimeout is meant to run with -race.
+func TestCloseDrainTimeout(t *testing.T) {
+	api := newBlockingAppConfigData()
+	c := newTestClient(api)
+	c.drainTimeout = 20 * time.Millisecond
+	startPolling(t, c, api)
+
+	if err := c.Close(); !errors.Is(err, ErrDrainTimeout) {
+		t.Fatalf("Close() error = %v, want ErrDrainTimeout", err)
+	}
+	if err := c.Close(); !errors.Is(err, ErrDrainTimeout) {
+		t.Errorf("second Close() error = %v, want the first outcome", err)
+	}
+
+	if value, _ := c.GetSecret(context.Background(), "api_token"); value != "v1" {
+		t.Errorf("GetSecret() = %q, want the cache left as before the canceled poll", value)
+	}
+}
+
+func TestRefreshReportsChanges(t *testing.T) {
+	api := &mockAppConfigData{configuration: []byte(`{"kept":"1","changed":"old","removed":"x"}`)}
+	c := newTestClient(api)
+
+	var notified []sm.ChangeEvent
+	c.onChange = func(changes []sm.ChangeEvent) { notified = changes }
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	api.configuration = []byte(`{"kept":"1","changed":"new","added":"y"}`)
+	changes, err := c.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	want := []sm.ChangeEvent{
+		{Key: "added", Type: sm.ChangeAdded},
+		{Key: "changed", Type: sm.ChangeChanged},
+		{Key: "removed", Type: sm.ChangeRemoved},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Refresh() = %v, want %v", changes, want)
+	}
+	if !reflect.DeepEqual(notified, want) {
+		t.Errorf("onChange received %v, want the changes Refresh returned", notified)
+	}
+
+	// AppConfig answers with an empty payload when the version didn't change
+	api.configuration = nil
+	if changes, err := c.Refresh(context.Background()); err != nil || len(changes) != 0 {
+		t.Errorf("Refresh() = %v, %v for an unchanged version, want none", changes, err)
+	}
+}
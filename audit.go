@@ -0,0 +1,129 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// AuditOutcomeSuccess reports a secret that was returned to the caller.
+	AuditOutcomeSuccess AuditOutcome = "success"
+
+	// AuditOutcomeNotFound reports a secret that doesn't exist.
+	AuditOutcomeNotFound AuditOutcome = "not_found"
+
+	// AuditOutcomeError reports a secret that couldn't be retrieved.
+	AuditOutcomeError AuditOutcome = "error"
+)
+
+type (
+	// AuditOutcome describes the result of an audited secret access.
+	AuditOutcome string
+
+	// AuditEvent describes a single secret access. It never carries the secret value.
+	AuditEvent struct {
+		Key       string       // The secret key requested
+		Principal string       // The caller identity attached to the context, if any
+		Outcome   AuditOutcome // The result of the access
+		Time      time.Time    // When the access happened
+	}
+
+	// AuditSink receives audit events. Implementations must be safe for concurrent use.
+	AuditSink interface {
+		Audit(ctx context.Context, event AuditEvent)
+	}
+
+	// AuditSinkFunc adapts a function to the AuditSink interface.
+	AuditSinkFunc func(ctx context.Context, event AuditEvent)
+
+	// auditClient decorates a SecretClient, emitting an audit event on every GetSecret.
+	auditClient struct {
+		SecretClient
+		sink  AuditSink
+		clock Clock // Source of the event times
+	}
+
+	// AuditOption configures optional behavior of the audit client.
+	AuditOption func(*auditClient)
+
+	// principalKey is the context key holding the audited principal.
+	principalKey struct{}
+)
+
+// Audit calls f(ctx, event).
+func (f AuditSinkFunc) Audit(ctx context.Context, event AuditEvent) {
+	f(ctx, event)
+}
+
+// WithAuditClock sets the clock timestamping the audit events. Defaults to SystemClock.
+func WithAuditClock(clock Clock) AuditOption {
+	return func(a *auditClient) {
+		a.clock = clock
+	}
+}
+
+// ContextWithPrincipal returns a copy of ctx carrying the identity of the caller, which
+// is reported as the principal of the audit events emitted for that context.
+func ContextWithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal attached by ContextWithPrincipal, if any.
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey{}).(string)
+	return principal, ok
+}
+
+// NewAuditClient wraps c so that every GetSecret emits an AuditEvent to sink, holding the
+// key name, the principal from the context and the outcome, but never the value.
+//
+// Auditing is fully disabled, at no cost, when sink is nil: c is then returned as is.
+//
+// Parameters:
+//   - c: The secret client to audit
+//   - sink: The destination of the audit events, or nil to disable auditing
+//   - opts: Optional settings such as the clock
+//
+// Returns:
+//   - A SecretClient emitting audit events
+func NewAuditClient(c SecretClient, sink AuditSink, opts ...AuditOption) SecretClient {
+	if sink == nil {
+		return c
+	}
+
+	a := &auditClient{SecretClient: c, sink: sink, clock: SystemClock}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// GetSecret retrieves the secret from the wrapped client and audits the access.
+func (a *auditClient) GetSecret(ctx context.Context, key string) (string, error) {
+	value, err := a.SecretClient.GetSecret(ctx, key)
+
+	event := AuditEvent{Key: key, Outcome: AuditOutcomeSuccess, Time: a.clock.Now()}
+	event.Principal, _ = PrincipalFromContext(ctx)
+
+	switch {
+	case errors.Is(err, ErrSecretNotFound):
+		event.Outcome = AuditOutcomeNotFound
+	case err != nil:
+		event.Outcome = AuditOutcomeError
+	}
+
+	a.sink.Audit(ctx, event)
+
+	return value, err
+}
+
+// Reload forwards the reload to the wrapped client.
+func (a *auditClient) Reload(ctx context.Context) error {
+	return reload(ctx, a.SecretClient)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package audit provides AuditSink implementations emitting the secretsmanager audit
+// events as structured log entries.
+package audit
+
+import (
+	"context"
+
+	"github.com/goxkit/logging"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// loggerSink is an AuditSink writing every event as a structured log entry.
+type loggerSink struct {
+	logger logging.Logger
+	level  zapcore.Level
+}
+
+// NewLoggerSink creates an AuditSink logging each event at the given level with the
+// "key", "principal", "outcome" and "time" fields. Secret values are never logged.
+//
+// Parameters:
+//   - logger: The logger receiving the audit entries
+//   - level: The level of the audit entries
+//
+// Returns:
+//   - An AuditSink to use with secretsmanager.NewAuditClient
+func NewLoggerSink(logger logging.Logger, level zapcore.Level) sm.AuditSink {
+	return &loggerSink{logger: logger, level: level}
+}
+
+// Audit logs the event at the configured level.
+func (s *loggerSink) Audit(_ context.Context, event sm.AuditEvent) {
+	fields := []zap.Field{
+		zap.String("key", event.Key),
+		zap.String("principal", event.Principal),
+		zap.String("outcome", string(event.Outcome)),
+		zap.Time("time", event.Time),
+	}
+
+	switch s.level {
+	case zapcore.DebugLevel:
+		s.logger.Debug("secret access", fields...)
+	case zapcore.InfoLevel:
+		s.logger.Info("secret access", fields...)
+	case zapcore.WarnLevel:
+		s.logger.Warn("secret access", fields...)
+	default:
+		s.logger.Error("secret access", fields...)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package audit_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/audit"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+func TestLoggerSink(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	sink := audit.NewLoggerSink(zap.New(core), zapcore.WarnLevel)
+
+	inner := fake.NewFakeClient(fake.WithSeed(map[string]string{"db_password": "s3cret"}))
+	if err := inner.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	c := sm.NewAuditClient(inner, sink)
+	ctx := sm.ContextWithPrincipal(context.Background(), "svc-billing")
+
+	if _, err := c.GetSecret(ctx, "db_password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetSecret(ctx, "missing"); err == nil {
+		t.Fatal("GetSecret(missing) succeeded")
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("log entries = %d, want 2", len(entries))
+	}
+
+	for i, want := range []struct{ key, outcome string }{
+		{key: "db_password", outcome: string(sm.AuditOutcomeSuccess)},
+		{key: "missing", outcome: string(sm.AuditOutcomeNotFound)},
+	} {
+		entry := entries[i]
+		if entry.Level != zapcore.WarnLevel || entry.Message != "secret access" {
+			t.Errorf("entry = %s %q, want a warn secret access entry", entry.Level, entry.Message)
+		}
+
+		fields := entry.ContextMap()
+		if fields["key"] != want.key || fields["principal"] != "svc-billing" || fields["outcome"] != want.outcome {
+			t.Errorf("fields = %v, want key %s, principal svc-billing and outcome %s", fields, want.key, want.outcome)
+		}
+		if _, ok := fields["time"].(time.Time); !ok {
+			t.Errorf("time field = %v, want the event time", fields["time"])
+		}
+		if strings.Contains(fmt.Sprint(fields), "s3cret") {
+			t.Errorf("fields = %v carry the secret value", fields)
+		}
+	}
+}
+
+func TestLoggerSinkLevels(t *testing.T) {
+	for _, level := range []zapcore.Level{zapcore.DebugLevel, zapcore.InfoLevel, zapcore.WarnLevel, zapcore.ErrorLevel} {
+		t.Run(level.String(), func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			audit.NewLoggerSink(zap.New(core), level).Audit(context.Background(), sm.AuditEvent{Key: "db_password"})
+
+			if entries := logs.AllUntimed(); len(entries) != 1 || entries[0].Level != level {
+				t.Errorf("entries = %v, want one entry at %s", entries, level)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// recordingSink is an AuditSink keeping every event it receives.
+type recordingSink struct {
+	mu     sync.Mutex
+	events []sm.AuditEvent
+}
+
+func (r *recordingSink) Audit(_ context.Context, event sm.AuditEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func TestAuditClient(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	clock := newManualClock()
+	sink := &recordingSink{}
+
+	backend := &mapClient{values: map[string]string{"db_password": "s3cret"}}
+	c := sm.NewAuditClient(sm.NewLazyClient(func(ctx context.Context, key string) (string, error) {
+		if key == "broken" {
+			return "", errBackend
+		}
+
+		return backend.GetSecret(ctx, key)
+	}), sink, sm.WithAuditClock(clock))
+
+	ctx := sm.ContextWithPrincipal(context.Background(), "svc-billing")
+	tests := []struct {
+		key     string
+		outcome sm.AuditOutcome
+		wantErr error
+	}{
+		{key: "db_password", outcome: sm.AuditOutcomeSuccess},
+		{key: "missing", outcome: sm.AuditOutcomeNotFound, wantErr: sm.ErrSecretNotFound},
+		{key: "broken", outcome: sm.AuditOutcomeError, wantErr: errBackend},
+	}
+
+	for i, tt := range tests {
+		value, err := c.GetSecret(ctx, tt.key)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("GetSecret(%s) error = %v, want %v", tt.key, err, tt.wantErr)
+		}
+		if tt.wantErr == nil && value != "s3cret" {
+			t.Errorf("GetSecret(%s) = %q, want the value of the wrapped client", tt.key, value)
+		}
+
+		event := sink.events[i]
+		want := sm.AuditEvent{Key: tt.key, Principal: "svc-billing", Outcome: tt.outcome, Time: clock.Now()}
+		if event != want {
+			t.Errorf("event = %+v, want %+v", event, want)
+		}
+		if strings.Contains(fmt.Sprintf("%+v", event), "s3cret") {
+			t.Errorf("event = %+v carries the secret value", event)
+		}
+	}
+
+	if len(sink.events) != len(tests) {
+		t.Errorf("events = %d, want %d", len(sink.events), len(tests))
+	}
+}
+
+func TestAuditClientWithoutPrincipal(t *testing.T) {
+	sink := &recordingSink{}
+	c := sm.NewAuditClient(&mapClient{values: map[string]string{"db_password": "s3cret"}}, sink)
+
+	if _, err := c.GetSecret(context.Background(), "db_password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.events) != 1 || sink.events[0].Principal != "" {
+		t.Errorf("events = %+v, want one event without a principal", sink.events)
+	}
+}
+
+func TestAuditClientNilSink(t *testing.T) {
+	inner := &mapClient{values: map[string]string{"db_password": "s3cret"}}
+
+	if c := sm.NewAuditClient(inner, nil); c != sm.SecretClient(inner) {
+		t.Errorf("NewAuditClient(c, nil) = %T, want c returned as is", c)
+	}
+}
+
+func TestAuditClientReload(t *testing.T) {
+	ctx := context.Background()
+	inner := newLoadedClient(t, map[string]string{"api_token": "v1"})
+	c := sm.NewAuditClient(inner, &recordingSink{})
+
+	reloadable, ok := c.(sm.Reloadable)
+	if !ok {
+		t.Fatal("audit client doesn't implement Reloadable")
+	}
+
+	inner.SetSecret("api_token", "v2")
+	if err := reloadable.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if got := inner.LoadCount(); got != 2 {
+		t.Errorf("wrapped client loads = %d, want 2", got)
+	}
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v2" {
+		t.Errorf("GetSecret() = %q after Reload, want v2", value)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package autodetect picks a SecretClient implementation from the environment, for a
+// zero-configuration experience in local and polyglot development setups.
+//
+// Providers are tried in the following order of precedence, the first match winning:
+//
+//  1. AWS Secrets Manager, when AWS credentials or a region are set in the environment
+//     (AWS_REGION, AWS_DEFAULT_REGION, AWS_PROFILE, AWS_ACCESS_KEY_ID,
+//     AWS_WEB_IDENTITY_TOKEN_FILE or the ECS container credentials variables)
+//  2. HashiCorp Vault, when VAULT_ADDR is set; the secret is obtained by unwrapping the
+//     response-wrapping token in VAULT_WRAPPING_TOKEN
+//  3. A local JSON file, when DefaultSecretsFile exists in the working directory
+//  4. A secrets directory, when file.DefaultSecretsDir exists, as mounted by Docker
+//  5. The process environment, serving environment variables as secrets
+package autodetect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/goxkit/configs"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/aws"
+	"github.com/goxkit/secretsmanager/file"
+	"github.com/goxkit/secretsmanager/internal/logs"
+	"github.com/goxkit/secretsmanager/vault"
+)
+
+const (
+	// DefaultSecretsFile is the JSON file picked up from the working directory.
+	DefaultSecretsFile = "secrets.json"
+
+	// ProviderAWS selects AWS Secrets Manager.
+	ProviderAWS Provider = "aws"
+
+	// ProviderVault selects HashiCorp Vault.
+	ProviderVault Provider = "vault"
+
+	// ProviderFile selects the local JSON file.
+	ProviderFile Provider = "file"
+
+	// ProviderDir selects the secrets directory.
+	ProviderDir Provider = "dir"
+
+	// ProviderEnv selects the process environment.
+	ProviderEnv Provider = "env"
+)
+
+var (
+	// ErrVaultTokenMissing is returned when Vault is detected but VAULT_WRAPPING_TOKEN
+	// isn't set.
+	ErrVaultTokenMissing = errors.New("VAULT_ADDR is set but VAULT_WRAPPING_TOKEN is not")
+
+	// awsVariables are the environment variables revealing an AWS setup.
+	awsVariables = []string{
+		"AWS_REGION",
+		"AWS_DEFAULT_REGION",
+		"AWS_PROFILE",
+		"AWS_ACCESS_KEY_ID",
+		"AWS_WEB_IDENTITY_TOKEN_FILE",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI",
+	}
+)
+
+// Provider identifies a provider selected by Detect.
+type Provider string
+
+// Detect returns the provider AutoDetect selects in the current environment, following
+// the precedence documented in the package.
+func Detect() Provider {
+	for _, name := range awsVariables {
+		if os.Getenv(name) != "" {
+			return ProviderAWS
+		}
+	}
+
+	if os.Getenv("VAULT_ADDR") != "" {
+		return ProviderVault
+	}
+
+	if info, err := os.Stat(DefaultSecretsFile); err == nil && info.Mode().IsRegular() {
+		return ProviderFile
+	}
+
+	if info, err := os.Stat(file.DefaultSecretsDir); err == nil && info.IsDir() {
+		return ProviderDir
+	}
+
+	return ProviderEnv
+}
+
+// AutoDetect creates the client of the provider selected by Detect.
+//
+// Parameters:
+//   - cfgs: Application configuration, used by the AWS provider and for logging
+//
+// Returns:
+//   - A SecretClient interface implementation for the detected provider
+//   - An error if the detected provider cannot be created
+func AutoDetect(cfgs *configs.Configs) (sm.SecretClient, error) {
+	provider := Detect()
+	logs.FromConfigs(cfgs).Info("secrets provider detected", zap.String("provider", string(provider)))
+
+	switch provider {
+	case ProviderAWS:
+		return aws.NewAwsSecretClient(cfgs)
+	case ProviderVault:
+		token := os.Getenv("VAULT_WRAPPING_TOKEN")
+		if token == "" {
+			return nil, ErrVaultTokenMissing
+		}
+
+		return vault.NewUnwrapSecretClient(os.Getenv("VAULT_ADDR"), token), nil
+	case ProviderFile:
+		return file.NewFileSecretClient(DefaultSecretsFile), nil
+	case ProviderDir:
+		return file.NewDirSecretClient(file.DefaultSecretsDir), nil
+	default:
+		return sm.NewEnvReferenceClient(unresolvedReference), nil
+	}
+}
+
+// unresolvedReference rejects secret references found in the environment, since no
+// backend is configured to resolve them.
+func unresolvedReference(_ context.Context, _ string) (string, error) {
+	return "", fmt.Errorf("%w: no provider configured to resolve secret references", sm.ErrSecretNotFound)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package autodetect_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/goxkit/configs"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/autodetect"
+	"github.com/goxkit/secretsmanager/file"
+)
+
+// clearEnvironment unsets every variable Detect looks at and moves to an empty working
+// directory, so each test starts from the env provider.
+func clearEnvironment(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{
+		"AWS_REGION",
+		"AWS_DEFAULT_REGION",
+		"AWS_PROFILE",
+		"AWS_ACCESS_KEY_ID",
+		"AWS_WEB_IDENTITY_TOKEN_FILE",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"VAULT_ADDR",
+		"VAULT_WRAPPING_TOKEN",
+	} {
+		t.Setenv(name, "")
+	}
+
+	t.Chdir(t.TempDir())
+
+	if _, err := os.Stat(file.DefaultSecretsDir); err == nil {
+		t.Skipf("%s exists on this machine", file.DefaultSecretsDir)
+	}
+}
+
+func writeSecretsFile(t *testing.T) {
+	t.Helper()
+
+	if err := os.WriteFile(autodetect.DefaultSecretsFile, []byte(`{"db_password":"s3cret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T)
+		want  autodetect.Provider
+	}{
+		{name: "nothing set", setup: func(*testing.T) {}, want: autodetect.ProviderEnv},
+		{name: "local file", setup: writeSecretsFile, want: autodetect.ProviderFile},
+		{
+			name: "secrets file is a directory",
+			setup: func(t *testing.T) {
+				if err := os.Mkdir(autodetect.DefaultSecretsFile, 0o700); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: autodetect.ProviderEnv,
+		},
+		{
+			name: "vault over file",
+			setup: func(t *testing.T) {
+				writeSecretsFile(t)
+				t.Setenv("VAULT_ADDR", "https://vault.internal:8200")
+			},
+			want: autodetect.ProviderVault,
+		},
+		{
+			name: "aws region over vault",
+			setup: func(t *testing.T) {
+				t.Setenv("VAULT_ADDR", "https://vault.internal:8200")
+				t.Setenv("AWS_REGION", "eu-west-1")
+			},
+			want: autodetect.ProviderAWS,
+		},
+		{
+			name:  "aws profile",
+			setup: func(t *testing.T) { t.Setenv("AWS_PROFILE", "dev") },
+			want:  autodetect.ProviderAWS,
+		},
+		{
+			name:  "ecs credentials",
+			setup: func(t *testing.T) { t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/id") },
+			want:  autodetect.ProviderAWS,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnvironment(t)
+			tt.setup(t)
+
+			if got := autodetect.Detect(); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutoDetectFile(t *testing.T) {
+	clearEnvironment(t)
+	writeSecretsFile(t)
+
+	c, err := autodetect.AutoDetect(&configs.Configs{})
+	if err != nil {
+		t.Fatalf("AutoDetect() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if value, err := c.GetSecret(ctx, "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v, want the file value", value, err)
+	}
+}
+
+func TestAutoDetectEnv(t *testing.T) {
+	clearEnvironment(t)
+	t.Setenv("DB_PASSWORD", "s3cret")
+	t.Setenv("API_TOKEN", "sm://project/api-token")
+
+	c, err := autodetect.AutoDetect(nil)
+	if err != nil {
+		t.Fatalf("AutoDetect() error = %v", err)
+	}
+
+	ctx := context.Background()
+	if value, err := c.GetSecret(ctx, "DB_PASSWORD"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v, want the environment value", value, err)
+	}
+	if _, err := c.GetSecret(ctx, "API_TOKEN"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want references rejected without a backend", err)
+	}
+}
+
+func TestAutoDetectVaultTokenMissing(t *testing.T) {
+	clearEnvironment(t)
+	t.Setenv("VAULT_ADDR", "https://vault.internal:8200")
+
+	if _, err := autodetect.AutoDetect(nil); !errors.Is(err, autodetect.ErrVaultTokenMissing) {
+		t.Errorf("AutoDetect() error = %v, want ErrVaultTokenMissing", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"go.uber.org/zap"
+)
+
+// maxBatchSize is the largest number of secret identifiers BatchGetSecretValue accepts.
+const maxBatchSize = 20
+
+// fetchBatch fetches several secrets with BatchGetSecretValue, saving a round-trip per
+// secret, and fails on the first secret that couldn't be loaded like fetchSequentially.
+// When the batch call itself fails, e.g. in a region or account without the API or the
+// secretsmanager:BatchGetSecretValue permission, it falls back to fetchSequentially.
+func (c *awsSecretClient) fetchBatch(ctx context.Context, ids []string) (map[string]map[string]string, error) {
+	values, failures, err := c.batchGet(ctx, ids)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		c.logger.Warn("batch get secret value failed, fetching secrets one by one", zap.Error(err))
+		return c.fetchSequentially(ctx, ids)
+	}
+
+	loaded := make(map[string]map[string]string, len(ids))
+	for _, id := range ids {
+		var secrets map[string]string
+
+		res, ok := values[id]
+		switch {
+		case ok:
+			if secrets, err = c.decode(id, res); err == nil {
+				secrets, err = c.prepare(ctx, id, secrets)
+			}
+		case failures[id] != nil:
+			err = failures[id]
+		default:
+			// Not reported by the batch, fetch it on its own to get a precise outcome
+			secrets, err = c.fetchSecrets(ctx, id)
+		}
+
+		secrets, err = c.tolerateLevel(id, secrets, err)
+		if err != nil {
+			c.logger.Error("error to get secret", zap.String("secretId", id), zap.Error(err))
+			return nil, err
+		}
+
+		loaded[id] = secrets
+	}
+
+	return loaded, nil
+}
+
+// batchGet calls BatchGetSecretValue for ids, following pagination, and returns the
+// values and the per-secret errors indexed by the requested identifier.
+func (c *awsSecretClient) batchGet(ctx context.Context, ids []string) (map[string]*secretsmanager.GetSecretValueOutput, map[string]error, error) {
+	values := make(map[string]*secretsmanager.GetSecretValueOutput, len(ids))
+	failures := make(map[string]error)
+
+	for start := 0; start < len(ids); start += maxBatchSize {
+		chunk := ids[start:min(start+maxBatchSize, len(ids))]
+
+		var token *string
+		for {
+			res, err := c.api().BatchGetSecretValue(ctx, &secretsmanager.BatchGetSecretValueInput{
+				SecretIdList: chunk,
+				NextToken:    token,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			for _, entry := range res.SecretValues {
+				if id, ok := matchSecretId(chunk, aws.ToString(entry.Name), aws.ToString(entry.ARN)); ok {
+					values[id] = &secretsmanager.GetSecretValueOutput{
+						ARN:          entry.ARN,
+						CreatedDate:  entry.CreatedDate,
+						Name:         entry.Name,
+						SecretBinary: entry.SecretBinary,
+						SecretString: entry.SecretString,
+						VersionId:    entry.VersionId,
+					}
+				}
+			}
+
+			for _, failure := range res.Errors {
+				failures[aws.ToString(failure.SecretId)] = batchError(failure)
+			}
+
+			if res.NextToken == nil || *res.NextToken == "" {
+				break
+			}
+
+			token = res.NextToken
+		}
+	}
+
+	return values, failures, nil
+}
+
+// matchSecretId returns the requested identifier designating the secret with the given
+// name and ARN.
+func matchSecretId(ids []string, name, arn string) (string, bool) {
+	for _, id := range ids {
+		if id == name || id == arn {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// batchError converts a per-secret error of BatchGetSecretValue into the typed error
+// GetSecretValue would have returned, so callers can match it with errors.As, mapped
+// like the errors of GetSecretValue to also match sm.ErrSecretNotFound or
+// ErrDecryptionFailure.
+func batchError(failure types.APIErrorType) error {
+	var err error
+	switch aws.ToString(failure.ErrorCode) {
+	case "ResourceNotFoundException":
+		err = &types.ResourceNotFoundException{Message: failure.Message}
+	case "DecryptionFailure":
+		err = &types.DecryptionFailure{Message: failure.Message}
+	case "InternalServiceError":
+		err = &types.InternalServiceError{Message: failure.Message}
+	case "InvalidParameterException":
+		err = &types.InvalidParameterException{Message: failure.Message}
+	case "InvalidRequestException":
+		err = &types.InvalidRequestException{Message: failure.Message}
+	default:
+		err = fmt.Errorf("%s: %s", aws.ToString(failure.ErrorCode), aws.ToString(failure.Message))
+	}
+
+	return mapError(err)
+}
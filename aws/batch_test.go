@@ -0,0 +1,152 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestBatchErrorMatchesSentinels(t *testing.T) {
+	tests := []struct {
+		code     string
+		sentinel error
+		typed    any
+	}{
+		{code: "ResourceNotFoundException", sentinel: sm.ErrSecretNotFound, typed: new(*types.ResourceNotFoundException)},
+		{code: "DecryptionFailure", sentinel: ErrDecryptionFailure, typed: new(*types.DecryptionFailure)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			err := batchError(types.APIErrorType{
+				ErrorCode: aws.String(tt.code),
+				Message:   aws.String("failed"),
+				SecretId:  aws.String("dev/app"),
+			})
+
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("batchError() = %v, want an error matching %v", err, tt.sentinel)
+			}
+			if !errors.As(err, tt.typed) {
+				t.Errorf("batchError() = %v, want the typed AWS error kept", err)
+			}
+		})
+	}
+}
+
+func TestLoadSecretsBatchDecryptionFailure(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"a":"1"}`})
+	api.batchErrors = []types.APIErrorType{{
+		ErrorCode: aws.String("DecryptionFailure"),
+		Message:   aws.String("cannot decrypt"),
+		SecretId:  aws.String("dev/shared"),
+	}}
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/shared"))
+
+	err := c.LoadSecrets(context.Background())
+	if !errors.Is(err, ErrDecryptionFailure) {
+		t.Fatalf("LoadSecrets() error = %v, want ErrDecryptionFailure", err)
+	}
+
+	var decryption *types.DecryptionFailure
+	if !errors.As(err, &decryption) {
+		t.Errorf("LoadSecrets() error = %v, want *types.DecryptionFailure reachable", err)
+	}
+}
+
+func TestLoadSecretsBatchKeepsCreatedDate(t *testing.T) {
+	primary := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	secondary := time.Date(2023, 11, 5, 8, 30, 0, 0, time.UTC)
+
+	api := newMockSecretsManager(map[string]string{
+		"dev/app":    `{"a":"1"}`,
+		"dev/shared": `{"b":"2"}`,
+	})
+	api.created["dev/app"] = primary
+	api.created["dev/shared"] = secondary
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/shared"))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if got := api.Calls("BatchGetSecretValue"); got != 1 {
+		t.Fatalf("BatchGetSecretValue calls = %d, want the secrets fetched in one batch", got)
+	}
+
+	for key, want := range map[string]time.Time{"a": primary, "b": secondary} {
+		if got, ok := c.LastModified(key); !ok || !got.Equal(want) {
+			t.Errorf("LastModified(%q) = %v, %v, want %v", key, got, ok, want)
+		}
+	}
+}
+
+func TestLoadSecretsBatchMixedOutcomes(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app":    `{"db_password":"env"}`,
+		"dev/shared": `{"api_token":"t0ken"}`,
+	})
+	api.batchErrors = []types.APIErrorType{{
+		ErrorCode: aws.String("ResourceNotFoundException"),
+		Message:   aws.String("secret not found"),
+		SecretId:  aws.String("base/app"),
+	}}
+
+	// The missing base level is tolerated by the overlay, the secondary secret isn't
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/shared"), WithBaseOverlay())
+	c.baseId = "base/app"
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	for key, want := range map[string]string{"db_password": "env", "api_token": "t0ken"} {
+		if value, err := c.GetSecret(context.Background(), key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+
+	api.batchErrors[0].SecretId = aws.String("dev/shared")
+	delete(api.secrets, "dev/shared")
+
+	if err := c.LoadSecrets(context.Background()); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("LoadSecrets() error = %v with a failed secret in the batch, want ErrSecretNotFound", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "api_token"); value != "t0ken" {
+		t.Errorf("GetSecret() = %q, want the cache kept by the failed load", value)
+	}
+
+	if got := api.Calls("GetSecretValue"); got != 0 {
+		t.Errorf("GetSecretValue calls = %d, want every outcome taken from the batch", got)
+	}
+}
+
+func TestLoadSecretsBatchFallback(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app":    `{"db_password":"s3cret"}`,
+		"dev/shared": `{"api_token":"t0ken"}`,
+	})
+	api.batchErr = errors.New("unknown operation BatchGetSecretValue")
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/shared"))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if got := api.Calls("GetSecretValue"); got != 2 {
+		t.Errorf("GetSecretValue calls = %d, want one per secret after the batch failed", got)
+	}
+	if value, _ := c.GetSecret(context.Background(), "api_token"); value != "t0ken" {
+		t.Errorf("GetSecret() = %q, want t0ken", value)
+	}
+}
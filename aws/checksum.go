@@ -0,0 +1,71 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// ChecksumKey is the secret key holding the checksum verified by WithChecksumVerification.
+	ChecksumKey = "__checksum"
+)
+
+var (
+	// ErrChecksumMismatch is returned when a secret's checksum is missing or doesn't match
+	// its content.
+	ErrChecksumMismatch = errors.New("secret checksum does not match its content")
+)
+
+// ChecksumSecrets computes the checksum stored under ChecksumKey for the given secrets:
+// the lowercase hex SHA-256 of their canonical JSON encoding, a compact object with the
+// keys sorted and without HTML escaping. ChecksumKey itself is excluded, so the checksum
+// can be computed on the stored secret as is.
+//
+// Parameters:
+//   - secrets: The secret key-value pairs as stored in AWS
+//
+// Returns:
+//   - The checksum to store under ChecksumKey
+func ChecksumSecrets(secrets map[string]string) string {
+	content := make(map[string]string, len(secrets))
+	for key, value := range secrets {
+		if key != ChecksumKey {
+			content[key] = value
+		}
+	}
+
+	// Encoding a map of strings cannot fail, and encoding/json sorts map keys
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(content)
+
+	sum := sha256.Sum256(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksum checks the checksum of secrets and removes it from the map.
+func verifyChecksum(secrets map[string]string) error {
+	expected, ok := secrets[ChecksumKey]
+	if !ok {
+		return fmt.Errorf("%w: %s key is missing", ErrChecksumMismatch, ChecksumKey)
+	}
+
+	actual := ChecksumSecrets(secrets)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) != 1 {
+		return ErrChecksumMismatch
+	}
+
+	delete(secrets, ChecksumKey)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"testing"
+)
+
+// checksummed returns the JSON secret string of secrets with its checksum.
+func checksummed(t *testing.T, secrets map[string]string) string {
+	t.Helper()
+
+	stored := maps.Clone(secrets)
+	stored[ChecksumKey] = ChecksumSecrets(secrets)
+
+	body, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(body)
+}
+
+func TestChecksumSecrets(t *testing.T) {
+	secrets := map[string]string{"db_password": "s3cret", "api_token": "t0ken"}
+
+	// SHA-256 of {"api_token":"t0ken","db_password":"s3cret"}
+	const want = "4323e9ff74e26190f86e563522e6d527b9978ed654d858802339c765f44c6e84"
+	if got := ChecksumSecrets(secrets); got != want {
+		t.Errorf("ChecksumSecrets() = %s, want %s", got, want)
+	}
+
+	secrets[ChecksumKey] = "ignored"
+	if got := ChecksumSecrets(secrets); got != want {
+		t.Errorf("ChecksumSecrets() = %s with a checksum key, want it excluded", got)
+	}
+}
+
+func TestLoadSecretsChecksumVerification(t *testing.T) {
+	secrets := map[string]string{"db_password": "s3cret", "url": "https://example.com/?a=1&b=<2>"}
+
+	tampered := map[string]string{}
+	if err := json.Unmarshal([]byte(checksummed(t, secrets)), &tampered); err != nil {
+		t.Fatal(err)
+	}
+	tampered["db_password"] = "tampered"
+	tamperedBody, _ := json.Marshal(tampered)
+
+	tests := []struct {
+		name    string
+		payload string
+		wantErr error
+	}{
+		{name: "valid", payload: checksummed(t, secrets)},
+		{name: "tampered", payload: string(tamperedBody), wantErr: ErrChecksumMismatch},
+		{name: "missing checksum", payload: `{"db_password":"s3cret"}`, wantErr: ErrChecksumMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(newMockSecretsManager(map[string]string{"dev/app": tt.payload}), "dev/app", WithChecksumVerification())
+
+			err := c.LoadSecrets(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if snapshot, _ := c.Snapshot(context.Background()); !maps.Equal(snapshot, secrets) {
+				t.Errorf("Snapshot() = %v, want the secrets without the checksum", snapshot)
+			}
+		})
+	}
+}
+
+func TestWriteSecretUpdatesChecksum(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": checksummed(t, map[string]string{"db_password": "s3cret"})})
+	c := newTestClient(api, "dev/app", WithChecksumVerification())
+
+	if err := c.WriteSecret(context.Background(), "api_token", "t0ken"); err != nil {
+		t.Fatalf("WriteSecret() error = %v", err)
+	}
+
+	reloaded := newTestClient(api, "dev/app", WithChecksumVerification())
+	if err := reloaded.LoadSecrets(context.Background()); err != nil {
+		t.Errorf("LoadSecrets() error = %v after a write, want a valid checksum", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/goxkit/configs"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// NewAwsSecretFetcher creates a fetch function reading discrete AWS Secrets Manager secrets,
+// meant to back a secretsmanager.LazyClient when every key is stored as its own secret.
+//
+// The key passed to the fetch function is resolved to the secret ID
+// "{environment}/{secretKey}/{key}" and the whole secret payload is returned as the value.
+// Secrets that don't exist are reported as sm.ErrSecretNotFound, like a cache miss of the
+// eager client.
+//
+// Parameters:
+//   - cfgs: Application configuration containing environment, secret key, and logger
+//   - opts: Optional settings such as web identity credentials
+//
+// Returns:
+//   - A fetch function reading one secret per key
+//   - An error if AWS configuration cannot be loaded
+func NewAwsSecretFetcher(cfgs *configs.Configs, opts ...Option) (sm.FetchFunc, error) {
+	client, err := NewAwsSecretClient(cfgs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := client.(*awsSecretClient)
+
+	return func(ctx context.Context, key string) (string, error) {
+		secretId := fmt.Sprintf("%s/%s", c.appSecretId, key)
+
+		res, err := c.getSecretValue(ctx, secretId)
+		if err != nil {
+			c.logger.Error("error to get secret", zap.String("secretId", secretId), zap.Error(err))
+			return "", mapError(err)
+		}
+
+		payload, err := secretPayload(res, c.base64Bin)
+		if err != nil {
+			return "", err
+		}
+
+		return string(payload), nil
+	}, nil
+}
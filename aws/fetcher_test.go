@@ -0,0 +1,42 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/goxkit/configs"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestAwsSecretFetcher(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	srv, requests := newSecretsManagerServer(t, map[string]string{
+		configs.DevelopmentEnv.ToString() + "/app/db_password": "s3cret",
+	})
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: "app"}}
+	fetch, err := NewAwsSecretFetcher(cfgs, WithEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewAwsSecretFetcher() error = %v", err)
+	}
+
+	if value, err := fetch(context.Background(), "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("fetch() = %q, %v, want s3cret", value, err)
+	}
+	if _, err := fetch(context.Background(), "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("fetch() error = %v for a missing secret, want ErrSecretNotFound", err)
+	}
+
+	if got := len(*requests); got != 2 {
+		t.Errorf("requests = %d, want one per fetch", got)
+	}
+}
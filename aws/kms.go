@@ -0,0 +1,113 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// kmsValuePrefix marks a stored value encrypted client-side with KMS.
+const kmsValuePrefix = "kms:"
+
+var (
+	// ErrDecryptionFailure is returned when a secret or value cannot be decrypted with
+	// its KMS key, most often because the encryption context doesn't match the one it
+	// was encrypted with or the caller lacks kms:Decrypt on the key.
+	ErrDecryptionFailure = errors.New("secret cannot be decrypted, check the KMS key and encryption context")
+)
+
+type (
+	// valueCipher encrypts and decrypts individual secret values.
+	valueCipher interface {
+		Encrypt(ctx context.Context, plaintext string) (string, error)
+		Decrypt(ctx context.Context, ciphertext string) (string, error)
+	}
+
+	// kmsAPI is the subset of the KMS client used by this package.
+	// It exists so the client can be replaced by a mock in tests.
+	kmsAPI interface {
+		Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+		Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+	}
+
+	// kmsCipher is a valueCipher using a KMS key.
+	kmsCipher struct {
+		client  kmsAPI
+		keyId   string
+		context map[string]string
+	}
+)
+
+// newKMSCipher creates a valueCipher encrypting with the given KMS key and encryption
+// context, which may be nil.
+func newKMSCipher(client kmsAPI, keyId string, encryptionContext map[string]string) *kmsCipher {
+	return &kmsCipher{client: client, keyId: keyId, context: encryptionContext}
+}
+
+// Encrypt encrypts plaintext and returns it prefixed and base64-encoded.
+func (k *kmsCipher) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	res, err := k.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(k.keyId),
+		Plaintext:         []byte(plaintext),
+		EncryptionContext: k.context,
+	})
+	if err != nil {
+		return "", fmt.Errorf("kms encrypt: %w", err)
+	}
+
+	return kmsValuePrefix + base64.StdEncoding.EncodeToString(res.CiphertextBlob), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values without the prefix are returned as is.
+func (k *kmsCipher) Decrypt(ctx context.Context, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, kmsValuePrefix)
+	if !ok {
+		return value, nil
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("kms ciphertext is not valid base64: %w", err)
+	}
+
+	res, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:             aws.String(k.keyId),
+		CiphertextBlob:    blob,
+		EncryptionContext: k.context,
+	})
+	if err != nil {
+		// KMS reports a mismatched encryption context as an invalid ciphertext
+		var invalid *kmstypes.InvalidCiphertextException
+		if errors.As(err, &invalid) {
+			return "", fmt.Errorf("kms decrypt: %w: %w", ErrDecryptionFailure, err)
+		}
+
+		return "", fmt.Errorf("kms decrypt: %w", err)
+	}
+
+	return string(res.Plaintext), nil
+}
+
+// decryptValues decrypts every encrypted value of secrets in place.
+func (c *awsSecretClient) decryptValues(ctx context.Context, secrets map[string]string) error {
+	for key, value := range secrets {
+		plaintext, err := c.cipher.Decrypt(ctx, value)
+		if err != nil {
+			return fmt.Errorf("secret %q: %w", key, err)
+		}
+
+		secrets[key] = plaintext
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+type (
+	// mockKMS is an in-memory kmsAPI recording every call. Ciphertexts are opaque
+	// handles to the plaintext, key and encryption context they were created with.
+	mockKMS struct {
+		mu       sync.Mutex
+		blobs    map[string]kmsBlob
+		encrypts []*kms.EncryptInput
+		decrypts []*kms.DecryptInput
+	}
+
+	kmsBlob struct {
+		plaintext string
+		keyId     string
+		context   map[string]string
+	}
+)
+
+func newMockKMS() *mockKMS {
+	return &mockKMS{blobs: map[string]kmsBlob{}}
+}
+
+func (m *mockKMS) Encrypt(_ context.Context, params *kms.EncryptInput, _ ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.encrypts = append(m.encrypts, params)
+
+	blob := fmt.Sprintf("blob-%d", len(m.blobs))
+	m.blobs[blob] = kmsBlob{plaintext: string(params.Plaintext), keyId: aws.ToString(params.KeyId), context: maps.Clone(params.EncryptionContext)}
+
+	return &kms.EncryptOutput{CiphertextBlob: []byte(blob), KeyId: params.KeyId}, nil
+}
+
+func (m *mockKMS) Decrypt(_ context.Context, params *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decrypts = append(m.decrypts, params)
+
+	stored, ok := m.blobs[string(params.CiphertextBlob)]
+	if !ok || stored.keyId != aws.ToString(params.KeyId) || !maps.Equal(stored.context, params.EncryptionContext) {
+		return nil, &kmstypes.InvalidCiphertextException{Message: aws.String("invalid ciphertext")}
+	}
+
+	return &kms.DecryptOutput{Plaintext: []byte(stored.plaintext), KeyId: params.KeyId}, nil
+}
+
+func TestKMSEncryptionRoundTrip(t *testing.T) {
+	const keyId = "alias/app-secrets"
+
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret"}`})
+	keys := newMockKMS()
+	c := newTestClient(api, "dev/app", WithKMSEncryption(keyId))
+	c.cipher = newKMSCipher(keys, keyId, nil)
+	ctx := context.Background()
+
+	if err := c.WriteSecret(ctx, "api_token", "t0ken"); err != nil {
+		t.Fatalf("WriteSecret() error = %v", err)
+	}
+
+	if len(keys.encrypts) != 1 {
+		t.Fatalf("Encrypt called %d times, want 1", len(keys.encrypts))
+	}
+	if got := aws.ToString(keys.encrypts[0].KeyId); got != keyId {
+		t.Errorf("Encrypt KeyId = %q, want %q", got, keyId)
+	}
+	if got := string(keys.encrypts[0].Plaintext); got != "t0ken" {
+		t.Errorf("Encrypt Plaintext = %q, want t0ken", got)
+	}
+
+	stored := storedSecrets(t, api, "dev/app")["api_token"]
+	if !strings.HasPrefix(stored, kmsValuePrefix) || strings.Contains(stored, "t0ken") {
+		t.Fatalf("stored value = %q, want the KMS ciphertext", stored)
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if value, err := c.GetSecret(ctx, "api_token"); err != nil || value != "t0ken" {
+		t.Errorf("GetSecret(api_token) = %q, %v, want t0ken", value, err)
+	}
+	if value, err := c.GetSecret(ctx, "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret(db_password) = %q, %v, want the unencrypted value as is", value, err)
+	}
+	if len(keys.decrypts) == 0 || aws.ToString(keys.decrypts[len(keys.decrypts)-1].KeyId) != keyId {
+		t.Errorf("Decrypt calls = %d, want the last one with KeyId %q", len(keys.decrypts), keyId)
+	}
+}
+
+func TestKMSEncryptionContext(t *testing.T) {
+	const keyId = "alias/app-secrets"
+	encryptionContext := map[string]string{"app": "billing", "env": "dev"}
+
+	api := newMockSecretsManager(map[string]string{"dev/app": `{}`})
+	keys := newMockKMS()
+	c := newTestClient(api, "dev/app", WithKMSEncryption(keyId), WithKMSEncryptionContext(encryptionContext))
+	c.cipher = newKMSCipher(keys, c.opts.kmsKeyId, c.opts.kmsContext)
+	ctx := context.Background()
+
+	if err := c.WriteSecret(ctx, "api_token", "t0ken"); err != nil {
+		t.Fatalf("WriteSecret() error = %v", err)
+	}
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for _, call := range keys.encrypts {
+		if !maps.Equal(call.EncryptionContext, encryptionContext) {
+			t.Errorf("Encrypt EncryptionContext = %v, want %v", call.EncryptionContext, encryptionContext)
+		}
+	}
+	if len(keys.decrypts) == 0 {
+		t.Fatal("Decrypt was never called")
+	}
+	for _, call := range keys.decrypts {
+		if !maps.Equal(call.EncryptionContext, encryptionContext) {
+			t.Errorf("Decrypt EncryptionContext = %v, want %v", call.EncryptionContext, encryptionContext)
+		}
+	}
+
+	stored := storedSecrets(t, api, "dev/app")["api_token"]
+	other := newTestClient(api, "dev/app")
+	other.cipher = newKMSCipher(keys, keyId, map[string]string{"app": "billing", "env": "prod"})
+
+	err := other.LoadSecrets(ctx)
+	if !errors.Is(err, ErrDecryptionFailure) {
+		t.Fatalf("LoadSecrets() error = %v, want ErrDecryptionFailure", err)
+	}
+
+	var invalid *kmstypes.InvalidCiphertextException
+	if !errors.As(err, &invalid) {
+		t.Errorf("LoadSecrets() error = %v, want the InvalidCiphertextException reachable", err)
+	}
+
+	encoded := strings.TrimPrefix(stored, kmsValuePrefix)
+	if strings.Contains(err.Error(), encoded) || strings.Contains(err.Error(), "t0ken") {
+		t.Errorf("LoadSecrets() error = %q leaks the ciphertext or the value", err)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"go.uber.org/zap"
+)
+
+type (
+	// Metadata describes the configured AWS secret without exposing any secret value.
+	Metadata struct {
+		Name             string    // The friendly name of the secret
+		ARN              string    // The ARN of the secret
+		CreatedDate      time.Time // When the secret was created
+		LastChangedDate  time.Time // When the secret was last modified
+		RotationEnabled  bool      // Whether automatic rotation is turned on
+		LastRotatedDate  time.Time // When the secret was last rotated, zero if never
+		NextRotationDate time.Time // When the next rotation is scheduled, zero if none
+	}
+
+	// RotationInfo reports the rotation health of one configured secret.
+	RotationInfo struct {
+		SecretId         string        // The configured secret identifier
+		RotationEnabled  bool          // Whether automatic rotation is turned on
+		Interval         time.Duration // Rotation interval from the rotation rules, zero if unset
+		LastRotatedDate  time.Time     // When the secret was last rotated, zero if never
+		NextRotationDate time.Time     // When the next rotation is scheduled, zero if none
+		Overdue          bool          // Whether a rotation should have happened by now
+	}
+
+	// MetadataProvider is implemented by the AWS Secrets Manager client returned by
+	// NewAwsSecretClient. Use a type assertion on the SecretClient to access it.
+	MetadataProvider interface {
+		// SecretMetadata describes the configured secret through DescribeSecret.
+		SecretMetadata(ctx context.Context) (Metadata, error)
+
+		// SecretTags returns the tags of the configured secret through DescribeSecret.
+		SecretTags(ctx context.Context) (map[string]string, error)
+
+		// RotationStatus reports the rotation health of every configured secret.
+		RotationStatus(ctx context.Context) ([]RotationInfo, error)
+
+		// CheckResourcePolicy reports whether the secret's resource policy lets the caller read it.
+		CheckResourcePolicy(ctx context.Context) (PolicyReport, error)
+	}
+)
+
+// SecretMetadata retrieves the creation, modification and rotation details of the
+// configured secret by calling DescribeSecret. No secret value is read.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - The metadata of the secret
+//   - An error if the secret cannot be described
+func (c *awsSecretClient) SecretMetadata(ctx context.Context) (Metadata, error) {
+	res, err := c.describeSecret(ctx)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	return Metadata{
+		Name:             deref(res.Name),
+		ARN:              deref(res.ARN),
+		CreatedDate:      deref(res.CreatedDate),
+		LastChangedDate:  deref(res.LastChangedDate),
+		RotationEnabled:  deref(res.RotationEnabled),
+		LastRotatedDate:  deref(res.LastRotatedDate),
+		NextRotationDate: deref(res.NextRotationDate),
+	}, nil
+}
+
+// deref returns the value pointed to by p, or the zero value when p is nil.
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+
+	return *p
+}
+
+// SecretTags retrieves the tags of the configured secret by calling DescribeSecret, such
+// as the ownership or rotation policy encoded by governance tooling. No secret value is read.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - The tags of the secret indexed by tag key
+//   - An error if the secret cannot be described
+func (c *awsSecretClient) SecretTags(ctx context.Context) (map[string]string, error) {
+	res, err := c.describeSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(res.Tags))
+	for _, tag := range res.Tags {
+		tags[deref(tag.Key)] = deref(tag.Value)
+	}
+
+	return tags, nil
+}
+
+// RotationStatus reports, for every configured secret, whether rotation is enabled and
+// overdue, for compliance dashboards flagging stale secrets. It calls DescribeSecret for
+// each secret; no secret value is read.
+//
+// A secret is overdue when rotation is enabled and either its next scheduled rotation is
+// in the past, or its last rotation, or creation if it never rotated, is older than the
+// rotation interval of its rules, both widened by WithSkewTolerance. Secrets without
+// rotation are never overdue.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - The rotation status of each configured secret, in precedence order
+//   - An error if a secret cannot be described
+func (c *awsSecretClient) RotationStatus(ctx context.Context) ([]RotationInfo, error) {
+	now := c.clock.Now()
+
+	ids := c.secretIds()
+	infos := make([]RotationInfo, 0, len(ids))
+
+	for _, id := range ids {
+		res, err := c.describe(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		info := RotationInfo{
+			SecretId:         id,
+			RotationEnabled:  deref(res.RotationEnabled),
+			LastRotatedDate:  deref(res.LastRotatedDate),
+			NextRotationDate: deref(res.NextRotationDate),
+		}
+
+		if res.RotationRules != nil {
+			info.Interval = time.Duration(deref(res.RotationRules.AutomaticallyAfterDays)) * 24 * time.Hour
+		}
+
+		if info.RotationEnabled {
+			last := info.LastRotatedDate
+			if last.IsZero() {
+				last = deref(res.CreatedDate)
+			}
+
+			skew := c.opts.clockSkew
+			scheduledMissed := !info.NextRotationDate.IsZero() && now.After(info.NextRotationDate.Add(skew))
+			intervalElapsed := info.Interval > 0 && !last.IsZero() && now.Sub(last) > info.Interval+skew
+			info.Overdue = scheduledMissed || intervalElapsed
+		}
+
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// HealthCheck calls DescribeSecret for the primary secret, checking that Secrets Manager
+// is reachable and the secret readable by the caller without fetching its value. It
+// implements the secretsmanager.HealthChecker interface.
+func (c *awsSecretClient) HealthCheck(ctx context.Context) error {
+	_, err := c.describeSecret(ctx)
+	return err
+}
+
+// describeSecret calls DescribeSecret for the configured secret.
+func (c *awsSecretClient) describeSecret(ctx context.Context) (*secretsmanager.DescribeSecretOutput, error) {
+	return c.describe(ctx, c.appSecretId)
+}
+
+// describe calls DescribeSecret for secretId.
+func (c *awsSecretClient) describe(ctx context.Context, secretId string) (*secretsmanager.DescribeSecretOutput, error) {
+	res, err := c.api().DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{
+		SecretId: &secretId,
+	})
+	if err != nil {
+		c.logger.Error("error to describe secret", zap.Error(err))
+		return nil, mapError(err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestSecretMetadata(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotated := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	next := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret"}`})
+	api.described = map[string]*secretsmanager.DescribeSecretOutput{
+		"dev/app": {
+			Name:             aws.String("dev/app"),
+			ARN:              aws.String("arn:aws:secretsmanager:eu-west-1:111111111111:secret:dev/app-AbCdEf"),
+			CreatedDate:      &created,
+			LastChangedDate:  &rotated,
+			RotationEnabled:  aws.Bool(true),
+			LastRotatedDate:  &rotated,
+			NextRotationDate: &next,
+		},
+	}
+
+	c := newTestClient(api, "dev/app")
+
+	got, err := c.SecretMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("SecretMetadata() error = %v", err)
+	}
+
+	want := Metadata{
+		Name:             "dev/app",
+		ARN:              "arn:aws:secretsmanager:eu-west-1:111111111111:secret:dev/app-AbCdEf",
+		CreatedDate:      created,
+		LastChangedDate:  rotated,
+		RotationEnabled:  true,
+		LastRotatedDate:  rotated,
+		NextRotationDate: next,
+	}
+	if got != want {
+		t.Errorf("SecretMetadata() = %+v, want %+v", got, want)
+	}
+
+	if calls := api.Calls("GetSecretValue"); calls != 0 {
+		t.Errorf("GetSecretValue calls = %d, want metadata read without values", calls)
+	}
+}
+
+func TestSecretMetadataWithoutRotation(t *testing.T) {
+	api := newMockSecretsManager(nil)
+	api.described = map[string]*secretsmanager.DescribeSecretOutput{"dev/app": {Name: aws.String("dev/app")}}
+
+	got, err := newTestClient(api, "dev/app").SecretMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("SecretMetadata() error = %v", err)
+	}
+	if got.RotationEnabled || !got.LastRotatedDate.IsZero() || !got.NextRotationDate.IsZero() {
+		t.Errorf("SecretMetadata() = %+v, want zero rotation details", got)
+	}
+
+	if _, err := newTestClient(api, "dev/missing").SecretMetadata(context.Background()); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("SecretMetadata() error = %v for a missing secret, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSecretTags(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret"}`})
+	api.described = map[string]*secretsmanager.DescribeSecretOutput{
+		"dev/app": {Tags: []types.Tag{
+			{Key: aws.String("owner"), Value: aws.String("payments")},
+			{Key: aws.String("rotation"), Value: aws.String("30d")},
+		}},
+		"dev/untagged": {},
+	}
+
+	tags, err := newTestClient(api, "dev/app").SecretTags(context.Background())
+	if err != nil {
+		t.Fatalf("SecretTags() error = %v", err)
+	}
+	if want := map[string]string{"owner": "payments", "rotation": "30d"}; !maps.Equal(tags, want) {
+		t.Errorf("SecretTags() = %v, want %v", tags, want)
+	}
+
+	if tags, err := newTestClient(api, "dev/untagged").SecretTags(context.Background()); err != nil || len(tags) != 0 {
+		t.Errorf("SecretTags() = %v, %v for an untagged secret, want no tags", tags, err)
+	}
+	if calls := api.Calls("GetSecretValue"); calls != 0 {
+		t.Errorf("GetSecretValue calls = %d, want tags read without values", calls)
+	}
+}
+
+func TestRotationStatus(t *testing.T) {
+	clock := newManualClock()
+	now := clock.Now()
+	day := 24 * time.Hour
+
+	describe := func(enabled bool, days int64, last, next time.Time) *secretsmanager.DescribeSecretOutput {
+		res := &secretsmanager.DescribeSecretOutput{
+			RotationEnabled: aws.Bool(enabled),
+			RotationRules:   &types.RotationRulesType{AutomaticallyAfterDays: aws.Int64(days)},
+			CreatedDate:     aws.Time(now.Add(-365 * day)),
+		}
+		if !last.IsZero() {
+			res.LastRotatedDate = &last
+		}
+		if !next.IsZero() {
+			res.NextRotationDate = &next
+		}
+
+		return res
+	}
+
+	api := newMockSecretsManager(nil)
+	api.described = map[string]*secretsmanager.DescribeSecretOutput{
+		"dev/healthy":       describe(true, 30, now.Add(-10*day), now.Add(20*day)),
+		"dev/interval":      describe(true, 30, now.Add(-31*day), time.Time{}),
+		"dev/schedule":      describe(true, 30, now.Add(-29*day), now.Add(-time.Hour)),
+		"dev/never-rotated": describe(true, 30, time.Time{}, time.Time{}),
+		"dev/disabled":      describe(false, 30, now.Add(-90*day), time.Time{}),
+	}
+
+	tests := map[string]bool{
+		"dev/healthy":       false,
+		"dev/interval":      true,
+		"dev/schedule":      true,
+		"dev/never-rotated": true,
+		"dev/disabled":      false,
+	}
+
+	for id, wantOverdue := range tests {
+		t.Run(id, func(t *testing.T) {
+			infos, err := newTestClient(api, id, WithClock(clock)).RotationStatus(context.Background())
+			if err != nil {
+				t.Fatalf("RotationStatus() error = %v", err)
+			}
+			if len(infos) != 1 || infos[0].SecretId != id {
+				t.Fatalf("RotationStatus() = %+v, want one entry for %s", infos, id)
+			}
+
+			info := infos[0]
+			if info.Overdue != wantOverdue {
+				t.Errorf("Overdue = %v, want %v", info.Overdue, wantOverdue)
+			}
+			if info.Interval != 30*day {
+				t.Errorf("Interval = %v, want 30 days", info.Interval)
+			}
+		})
+	}
+
+	infos, err := newTestClient(api, "dev/healthy", WithClock(clock), WithSecondarySecret("dev/interval")).RotationStatus(context.Background())
+	if err != nil {
+		t.Fatalf("RotationStatus() error = %v", err)
+	}
+	if len(infos) != 2 || infos[0].SecretId != "dev/healthy" || infos[1].SecretId != "dev/interval" {
+		t.Errorf("RotationStatus() = %+v, want an entry per configured secret", infos)
+	}
+}
@@ -0,0 +1,426 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+const (
+	// BaseEnvironment is the environment segment of the base secret used by WithBaseOverlay.
+	BaseEnvironment = "base"
+
+	// DefaultKeySeparator separates the namespace from the key names by default.
+	DefaultKeySeparator = "."
+
+	// DefaultMaxPayloadSize is the largest secret payload accepted by LoadSecrets by default.
+	DefaultMaxPayloadSize = 4 << 20
+
+	// MaxSecretSize is the largest secret value accepted by AWS Secrets Manager, in bytes.
+	MaxSecretSize = 65536
+
+	// DefaultSizeWarningThreshold is the written payload size above which a warning is
+	// logged by default, 90% of MaxSecretSize.
+	DefaultSizeWarningThreshold = MaxSecretSize * 9 / 10
+)
+
+type (
+	// options holds the optional settings used to build the AWS Secrets Manager client.
+	options struct {
+		webIdentityRoleARN   string                  // Role assumed with the web identity token
+		webIdentityTokenFile string                  // Path to the OIDC web identity token
+		credentials          aws.CredentialsProvider // Provider replacing the default credential chain
+		onlyKeys             []string                // Keys retained in the cache, empty keeps all
+		maxPayloadSize       int                     // Largest accepted secret payload in bytes
+		aliases              map[string]string       // Alternative key names mapped to cached keys
+		secondarySecretId    string                  // Fallback secret consulted for keys missing in the primary
+		namespace            string                  // Key prefix isolating a tenant's secrets
+		separator            string                  // Separator between the namespace and key names
+		partialLoad          bool                    // Keep the secrets loaded before the context deadline
+		plainKey             string                  // Key under which a non-JSON secret is cached
+		noCache              bool                    // Reload the secret on every lookup
+		valueRules           map[string]sm.ValueRule // Quality checks applied to values at load
+		base64Binary         bool                    // Whether binary payloads are base64-encoded
+		clock                sm.Clock                // Source of the current time
+		clockSkew            time.Duration           // Clock skew tolerated by time comparisons
+		profile              string                  // Shared config profile to load
+		kmsKeyId             string                  // KMS key encrypting written values client-side
+		kmsContext           map[string]string       // KMS encryption context bound to encrypted values
+		imdsV2Only           bool                    // Whether EC2 role credentials require IMDSv2
+		baseOverlay          bool                    // Whether the environment secret overlays a base one
+		requireNonEmpty      bool                    // Whether a secret without keys fails the load
+		environmentRegions   map[string]string       // Region of each environment name
+		region               string                  // Region resolved from environmentRegions
+		endpoint             string                  // Custom Secrets Manager endpoint URL
+		verifyChecksum       bool                    // Whether secrets must carry a valid ChecksumKey
+		secretARN            string                  // Full ARN replacing the derived primary secret ID
+		cacheStore           sm.CacheStore           // Store holding the cached secrets
+		sharedCache          bool                    // Whether loads are shared with other clients
+		allowList            map[string]bool         // Keys GetSecret may return, nil allows all
+		sizeWarning          int                     // Written payload size logging a warning
+		sizeLimit            int                     // Written payload size failing the write, zero disables it
+		denyList             map[string]bool         // Keys GetSecret never returns
+		locker               sm.Locker               // Lock held by writes across processes
+		retryBudget          *sm.RetryBudget         // Budget consumed by the retries of AWS calls
+	}
+
+	// Option configures optional behavior of the AWS Secrets Manager client.
+	Option func(*options)
+)
+
+// WithWebIdentity configures credentials from an OIDC web identity token file and the
+// role to assume with it, as used by GitHub Actions or EKS service accounts.
+//
+// The default credential chain already honors the AWS_WEB_IDENTITY_TOKEN_FILE and
+// AWS_ROLE_ARN environment variables; this option is meant for setups where those
+// variables aren't available or the default chain picks another provider first.
+// The option is ignored unless both values are provided.
+func WithWebIdentity(roleARN, tokenFile string) Option {
+	return func(o *options) {
+		o.webIdentityRoleARN = roleARN
+		o.webIdentityTokenFile = tokenFile
+	}
+}
+
+// WithCredentialsProvider uses provider for every AWS call instead of the default
+// credential chain, for environments injecting credentials through a custom mechanism
+// such as a sidecar periodically writing them to a file. The provider is wrapped in an
+// aws.CredentialsCache, so Retrieve is only called when the cached credentials expire.
+// It takes precedence over WithProfile credentials and WithWebIdentity.
+func WithCredentialsProvider(provider aws.CredentialsProvider) Option {
+	return func(o *options) {
+		o.credentials = provider
+	}
+}
+
+// WithSharedCache makes the client share its loads with the other clients of the process
+// created with this option, so several clients pointing at the same secret ID result in a
+// single GetSecretValue call. A load reuses the response another client fetched since the
+// client's own previous load, and fetches the secret otherwise, so every Reload still
+// observes a response at least as recent as the previous one. Concurrent loads of the
+// same secret share one call. Writes always read the secret directly.
+//
+// Responses are only shared between clients running as the same identity in the same
+// region, the identity being resolved once with STS GetCallerIdentity, which needs no IAM
+// permission. Batch fetches are disabled for these clients. Disabled by default, keeping
+// clients isolated.
+func WithSharedCache() Option {
+	return func(o *options) {
+		o.sharedCache = true
+	}
+}
+
+// WithRetryBudget makes every retry of the client's AWS calls, including those of the
+// SDK's retryer and the retry after refreshing expired credentials, consume a token of
+// budget. Once it runs out, failed calls aren't retried and fail fast with an error
+// matching sm.ErrRetryBudgetExhausted. Share budget with the other retrying clients of
+// the process, such as a sm.LazyClient, to bound their total retry rate. By default the
+// SDK's own retry quota applies.
+func WithRetryBudget(budget *sm.RetryBudget) Option {
+	return func(o *options) {
+		o.retryBudget = budget
+	}
+}
+
+// WithAllowList restricts GetSecret to the given keys: any other key is rejected with
+// sm.ErrAccessDenied, whether or not it exists. Unlike OnlyKeys, the rest of the secret is
+// still loaded, so the check can't be mistaken for a missing key. Calling it several
+// times extends the list. By default every key is allowed.
+func WithAllowList(keys ...string) Option {
+	return func(o *options) {
+		if o.allowList == nil {
+			o.allowList = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			o.allowList[key] = true
+		}
+	}
+}
+
+// WithDenyList makes GetSecret reject the given keys with sm.ErrAccessDenied, whether or
+// not they exist. It takes precedence over WithAllowList. Calling it several times
+// extends the list.
+func WithDenyList(keys ...string) Option {
+	return func(o *options) {
+		if o.denyList == nil {
+			o.denyList = make(map[string]bool, len(keys))
+		}
+		for _, key := range keys {
+			o.denyList[key] = true
+		}
+	}
+}
+
+// WithSizeWarningThreshold logs a warning whenever a write produces a payload larger than
+// bytes, so teams notice a secret growing towards the MaxSecretSize hard limit of AWS
+// before PutSecretValue starts failing. Defaults to DefaultSizeWarningThreshold; zero or
+// less disables the warning.
+func WithSizeWarningThreshold(bytes int) Option {
+	return func(o *options) {
+		o.sizeWarning = bytes
+	}
+}
+
+// WithSizeLimit fails writes producing a payload larger than bytes with
+// ErrPayloadTooLarge, without calling AWS, to keep headroom below MaxSecretSize.
+// Disabled by default, leaving AWS to reject payloads above its own limit.
+func WithSizeLimit(bytes int) Option {
+	return func(o *options) {
+		o.sizeLimit = bytes
+	}
+}
+
+// OnlyKeys restricts the cache to the given keys. Every other key of the secret is
+// discarded right after parsing and is reported as not found by GetSecret, reducing the
+// in-memory secret surface of components that only need a few values.
+// By default all keys are retained.
+func OnlyKeys(keys ...string) Option {
+	return func(o *options) {
+		o.onlyKeys = append(o.onlyKeys, keys...)
+	}
+}
+
+// WithMaxPayloadSize sets the largest secret payload, in bytes, that LoadSecrets accepts.
+// Larger payloads are rejected with ErrPayloadTooLarge before being unmarshaled, protecting
+// the process against memory exhaustion. Defaults to DefaultMaxPayloadSize.
+func WithMaxPayloadSize(size int) Option {
+	return func(o *options) {
+		o.maxPayloadSize = size
+	}
+}
+
+// WithAliases registers alternative names for cached keys, mapping each alias to the key
+// actually stored in the secret (e.g. {"pwd": "password"}). GetSecret checks aliases only
+// after a direct lookup misses, easing key renames without duplicating secret data.
+func WithAliases(aliases map[string]string) Option {
+	return func(o *options) {
+		if o.aliases == nil {
+			o.aliases = make(map[string]string, len(aliases))
+		}
+
+		for alias, key := range aliases {
+			o.aliases[alias] = key
+		}
+	}
+}
+
+// WithSecondarySecret configures a secondary secret ID loaded alongside the primary one.
+// Keys present in both secrets resolve to the primary value, while keys only present in
+// the secondary secret are still served. This smooths migrations where keys move between
+// secrets. Both secrets must load successfully.
+func WithSecondarySecret(secretId string) Option {
+	return func(o *options) {
+		o.secondarySecretId = secretId
+	}
+}
+
+// WithNamespace isolates the client to the keys prefixed with "{namespace}.", as used by
+// multi-tenant platforms sharing one secret. Only the namespace keys are loaded and they
+// are served without the prefix, so GetSecret("db") returns the "{namespace}.db" value.
+// The separator can be changed with WithKeySeparator.
+func WithNamespace(namespace string) Option {
+	return func(o *options) {
+		o.namespace = namespace
+	}
+}
+
+// WithKeySeparator sets the separator between the namespace and the key names, so teams
+// whose key names contain "." can pick one that never appears in them, e.g. "/" for
+// "{namespace}/db". Defaults to DefaultKeySeparator.
+func WithKeySeparator(separator string) Option {
+	return func(o *options) {
+		o.separator = separator
+	}
+}
+
+// WithPartialLoad fetches the configured secrets concurrently and honors the context
+// deadline cooperatively: when it expires, the secrets already loaded are kept in the cache
+// and LoadSecrets returns an error wrapping context.DeadlineExceeded that lists the secret
+// IDs which didn't complete. Any other failure still fails the whole load.
+func WithPartialLoad() Option {
+	return func(o *options) {
+		o.partialLoad = true
+	}
+}
+
+// WithPlainKey sets the key under which a single-value secret, one whose payload isn't a
+// JSON object (such as an API token stored as plain text), is cached. Defaults to the
+// SecretKey of the application configuration.
+func WithPlainKey(key string) Option {
+	return func(o *options) {
+		o.plainKey = key
+	}
+}
+
+// WithNoCache makes every GetSecret reload the whole secret from AWS before the lookup,
+// so callers always observe the latest value. Each lookup then costs a GetSecretValue
+// call, which is billed and rate limited: use it only for low-traffic tools.
+func WithNoCache() Option {
+	return func(o *options) {
+		o.noCache = true
+	}
+}
+
+// WithValueRules validates the given keys at load time against a minimum length and
+// entropy, so provisioning mistakes such as a blank or single-character password fail
+// LoadSecrets with an error matching sm.ErrWeakSecret instead of reaching the application.
+// Error messages never include the values.
+func WithValueRules(rules map[string]sm.ValueRule) Option {
+	return func(o *options) {
+		o.valueRules = rules
+	}
+}
+
+// WithBase64Binary declares that binary secrets hold base64-encoded content, such as
+// base64-encoded JSON, which is decoded before being parsed. Without it, binary payloads
+// are parsed as raw bytes. String secrets are never decoded.
+func WithBase64Binary() Option {
+	return func(o *options) {
+		o.base64Binary = true
+	}
+}
+
+// WithClock sets the clock used for time-based behavior such as ReloadIfStale.
+// Defaults to sm.SystemClock.
+func WithClock(clock sm.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithSkewTolerance widens the time comparisons of ReloadIfStale and RotationStatus by d,
+// so the cache is only considered stale, and a rotation overdue, once the clock is past
+// the boundary by more than d. It absorbs clock drift between nodes and avoids flapping
+// around the boundary. Zero by default.
+func WithSkewTolerance(d time.Duration) Option {
+	return func(o *options) {
+		o.clockSkew = d
+	}
+}
+
+// WithProfile selects a named profile from the shared AWS config and credentials files,
+// letting developers with several accounts target the right one without exporting
+// environment variables. The default chain is used when no profile is set.
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithKMSEncryption encrypts values client-side with the given KMS key before WriteSecret
+// stores them, so the plaintext never reaches Secrets Manager, and decrypts them with the
+// same key on load. Encrypted values are stored with the "kms:" prefix followed by the
+// base64-encoded ciphertext; values without the prefix are served as is.
+func WithKMSEncryption(keyId string) Option {
+	return func(o *options) {
+		o.kmsKeyId = keyId
+	}
+}
+
+// WithKMSEncryptionContext sets the encryption context passed to KMS when WithKMSEncryption
+// encrypts and decrypts values. KMS key policies can require specific context entries,
+// and values encrypted with a context can only be decrypted with the same one; a mismatch
+// fails the load with an error matching ErrDecryptionFailure.
+func WithKMSEncryptionContext(encryptionContext map[string]string) Option {
+	return func(o *options) {
+		o.kmsContext = encryptionContext
+	}
+}
+
+// WithIMDSv2Only makes the EC2 role credentials provider require IMDSv2 session tokens
+// and never fall back to IMDSv1. Hosts where IMDSv1 is disabled by the security baseline
+// then fail fast on a token error instead of hanging on the fallback.
+func WithIMDSv2Only() Option {
+	return func(o *options) {
+		o.imdsV2Only = true
+	}
+}
+
+// WithBaseOverlay loads the base secret "base/{SecretKey}" and overlays the environment
+// secret "{environment}/{SecretKey}" on top of it, so values shared by every environment
+// are defined once. Keys present in both resolve to the environment value. Either secret
+// may be missing as long as the other exists; when both are, loading fails with
+// sm.ErrSecretNotFound.
+func WithBaseOverlay() Option {
+	return func(o *options) {
+		o.baseOverlay = true
+	}
+}
+
+// WithRequireNonEmpty fails LoadSecrets with ErrEmptySecret when the loaded secrets hold
+// no key, such as a secret stored as "{}", so a provisioning mistake stops the startup
+// instead of surfacing later as not-found lookups. By default an empty secret loads.
+func WithRequireNonEmpty() Option {
+	return func(o *options) {
+		o.requireNonEmpty = true
+	}
+}
+
+// WithEnvironmentRegions maps environment names to the AWS region holding their secrets,
+// e.g. {"prod": "us-east-1", "staging": "us-west-2"}, so the region follows the configured
+// environment without per-deployment region settings. Environments missing from the map
+// use the region of the default chain.
+func WithEnvironmentRegions(regions map[string]string) Option {
+	return func(o *options) {
+		o.environmentRegions = regions
+	}
+}
+
+// WithEndpoint targets the Secrets Manager API at url instead of the public regional
+// endpoint, such as the DNS name of a VPC interface endpoint in networks without internet
+// egress, e.g. "https://vpce-0123-abcd.secretsmanager.us-east-1.vpce.amazonaws.com".
+// FIPS and dual-stack endpoint variants are disabled since they don't apply to a custom
+// endpoint.
+func WithEndpoint(url string) Option {
+	return func(o *options) {
+		o.endpoint = url
+	}
+}
+
+// WithChecksumVerification requires every loaded secret to hold a ChecksumKey entry
+// matching ChecksumSecrets of its other keys, and fails the load with ErrChecksumMismatch
+// otherwise, so a truncated or corrupted secret is never served. The checksum key is
+// removed from the cache, and WriteSecret keeps it up to date.
+func WithChecksumVerification() Option {
+	return func(o *options) {
+		o.verifyChecksum = true
+	}
+}
+
+// WithSecretARN reads the primary secret from its full ARN instead of the identifier
+// derived from the environment and SecretKey. This is required for secrets owned by
+// another account and shared through a resource policy or AWS RAM, e.g.
+// "arn:aws:secretsmanager:us-east-1:111122223333:secret:shared/db-AbCdEf", since a
+// secret name only resolves within the caller's account. The caller's role needs
+// secretsmanager:GetSecretValue on the secret and kms:Decrypt on its customer managed
+// key, as secrets encrypted with the AWS managed key cannot be shared.
+func WithSecretARN(arn string) Option {
+	return func(o *options) {
+		o.secretARN = arn
+	}
+}
+
+// WithLocker acquires the given lock, named after the primary secret ID, around every
+// write, so writers running in other processes don't overwrite each other's changes.
+// Writes of a single client are always serialized; without this option they aren't
+// coordinated with other processes.
+func WithLocker(locker sm.Locker) Option {
+	return func(o *options) {
+		o.locker = locker
+	}
+}
+
+// WithCacheStore keeps the cached secrets in store instead of process memory, e.g. a
+// shared store letting several processes read a warmed cache. Defaults to a new
+// secretsmanager.MemoryStore.
+func WithCacheStore(store sm.CacheStore) Option {
+	return func(o *options) {
+		o.cacheStore = store
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+var (
+	// ErrEmptyPayload is returned when AWS returns a secret without any string or binary value.
+	ErrEmptyPayload = errors.New("secret payload is empty")
+
+	// ErrPayloadTooLarge is returned when the secret payload exceeds the configured maximum size.
+	ErrPayloadTooLarge = errors.New("secret payload exceeds the maximum size")
+
+	// ErrMalformedPayload is returned when a secret payload looking like a JSON object
+	// cannot be parsed as an object of strings.
+	ErrMalformedPayload = errors.New("secret payload is not a JSON object of strings")
+
+	// ErrEmptySecret is returned with WithRequireNonEmpty when the loaded secrets hold no key.
+	ErrEmptySecret = errors.New("secret holds no key")
+)
+
+// secretPayload extracts the raw secret payload from a GetSecretValue response.
+// Secrets created through the console or CLI are stored as SecretString, while
+// binary secrets are stored as SecretBinary. When base64Binary is set, a binary
+// payload is base64-decoded before being returned.
+func secretPayload(res *secretsmanager.GetSecretValueOutput, base64Binary bool) ([]byte, error) {
+	if res.SecretString != nil && *res.SecretString != "" {
+		return []byte(*res.SecretString), nil
+	}
+
+	if len(res.SecretBinary) > 0 && base64Binary {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(res.SecretBinary)))
+		n, err := base64.StdEncoding.Decode(decoded, bytes.TrimSpace(res.SecretBinary))
+		if err != nil {
+			return nil, fmt.Errorf("secret binary is not valid base64: %w", err)
+		}
+
+		return decoded[:n], nil
+	}
+
+	if len(res.SecretBinary) > 0 {
+		return res.SecretBinary, nil
+	}
+
+	return nil, ErrEmptyPayload
+}
+
+// parseSecrets decodes a secret payload into a new map, detecting its format.
+//
+// A payload holding a JSON object must map every key to a string value, otherwise an
+// error is returned. Any other payload is a single-value secret stored under plainKey:
+// a bare JSON string is stored decoded, anything else is stored as is. A JSON null yields
+// an empty map. It never panics on malformed input.
+func parseSecrets(payload []byte, plainKey string) (map[string]string, error) {
+	trimmed := bytes.TrimSpace(payload)
+
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		secrets := map[string]string{}
+		if err := json.Unmarshal(trimmed, &secrets); err != nil {
+			leading := len(payload) - len(bytes.TrimLeft(payload, " \t\r\n"))
+			return nil, describeJSONError(err, int64(leading))
+		}
+
+		return secrets, nil
+	case bytes.Equal(trimmed, []byte("null")):
+		return map[string]string{}, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(trimmed, &value); err != nil {
+		value = string(payload)
+	}
+
+	return map[string]string{plainKey: value}, nil
+}
+
+// describeJSONError turns a JSON decoding error into one locating the problem by byte
+// offset, with a hint on the usual fix. The errors of encoding/json quote the offending
+// character, which is part of the secret, so they're never wrapped nor echoed.
+func describeJSONError(err error, leading int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: syntax error at byte offset %d, check for missing quotes, commas or braces",
+			ErrMalformedPayload, syntaxErr.Offset+leading)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%w: key %q holds a JSON %s at byte offset %d, quote non-string values",
+			ErrMalformedPayload, typeErr.Field, typeErr.Value, typeErr.Offset+leading)
+	default:
+		return ErrMalformedPayload
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// payloadAPI answers every GetSecretValue call with a fixed binary payload.
+type payloadAPI struct {
+	secretsManagerAPI
+
+	payload []byte
+}
+
+func (p payloadAPI) GetSecretValue(
+	_ context.Context,
+	_ *secretsmanager.GetSecretValueInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.GetSecretValueOutput, error) {
+	return &secretsmanager.GetSecretValueOutput{SecretBinary: p.payload}, nil
+}
+
+func FuzzLoadSecrets(f *testing.F) {
+	f.Add([]byte(`{"db_password":"s3cret"}`))
+	f.Add([]byte(`plain-value`))
+	f.Add([]byte(strings.Repeat("[", 100000)))
+	f.Add([]byte("{" + strings.Repeat(`"k":{`, 10000)))
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		c := newTestClient(payloadAPI{payload: payload}, "dev/app")
+
+		err := c.LoadSecrets(context.Background())
+		switch {
+		case err == nil:
+			if _, err := c.Snapshot(context.Background()); err != nil {
+				t.Fatalf("Snapshot() error = %v after a successful load", err)
+			}
+		case errors.Is(err, ErrMalformedPayload):
+			if trimmed := bytes.TrimSpace(payload); !bytes.HasPrefix(trimmed, []byte("{")) {
+				t.Fatalf("LoadSecrets() = %v for a payload not holding a JSON object", err)
+			}
+		case errors.Is(err, ErrPayloadTooLarge):
+			if len(payload) <= DefaultMaxPayloadSize {
+				t.Fatalf("LoadSecrets() = %v for a %d bytes payload", err, len(payload))
+			}
+		case errors.Is(err, ErrEmptyPayload):
+			if len(payload) > 0 {
+				t.Fatalf("LoadSecrets() = %v for a %d bytes payload", err, len(payload))
+			}
+		default:
+			t.Fatalf("LoadSecrets() error = %v, want nil or a parsing sentinel", err)
+		}
+	})
+}
+
+func TestParseSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    map[string]string
+		wantErr error
+	}{
+		{name: "object", payload: ` {"a":"1","b":"2"}`, want: map[string]string{"a": "1", "b": "2"}},
+		{name: "plain", payload: `value`, want: map[string]string{"app": "value"}},
+		{name: "json string", payload: `"quoted"`, want: map[string]string{"app": "quoted"}},
+		{name: "null", payload: `null`, want: map[string]string{}},
+		{name: "number value", payload: `{"port":5432}`, wantErr: ErrMalformedPayload},
+		{name: "truncated", payload: `{"a":"s3c`, wantErr: ErrMalformedPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSecrets([]byte(tt.payload), "app")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3c") {
+				t.Errorf("error %q echoes the payload", err)
+			}
+			if tt.wantErr == nil && !maps.Equal(got, tt.want) {
+				t.Errorf("parseSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSecretsBinary(t *testing.T) {
+	object := `{"db_password":"s3cret"}`
+
+	tests := []struct {
+		name    string
+		payload string
+		opts    []Option
+		wantErr bool
+	}{
+		{name: "raw JSON", payload: object},
+		{name: "base64 JSON", payload: base64.StdEncoding.EncodeToString([]byte(object)) + "\n", opts: []Option{WithBase64Binary()}},
+		{name: "raw JSON flagged as base64", payload: object, opts: []Option{WithBase64Binary()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(payloadAPI{payload: []byte(tt.payload)}, "dev/app", tt.opts...)
+
+			err := c.LoadSecrets(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadSecrets() succeeded, want a decoding error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSecrets() error = %v", err)
+			}
+
+			if value, err := c.GetSecret(context.Background(), "db_password"); err != nil || value != "s3cret" {
+				t.Errorf("GetSecret() = %q, %v, want s3cret", value, err)
+			}
+		})
+	}
+}
+
+func TestLoadSecretsMalformedPayloadError(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{name: "missing comma", payload: `{"user":"admin" "password":"s3cret"}`, want: []string{"byte offset 17", "missing quotes, commas or braces"}},
+		{name: "leading whitespace", payload: "\n  {\"user\":\"admin\" \"password\":\"s3cret\"}", want: []string{"byte offset 20"}},
+		{name: "non-string value", payload: `{"password":"s3cret","port":5432}`, want: []string{`key "port"`, "JSON number", "quote non-string values"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(newMockSecretsManager(map[string]string{"dev/app": tt.payload}), "dev/app")
+
+			err := c.LoadSecrets(context.Background())
+			if !errors.Is(err, ErrMalformedPayload) {
+				t.Fatalf("LoadSecrets() error = %v, want ErrMalformedPayload", err)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadSecrets() error = %q, want it to contain %q", err, want)
+				}
+			}
+			for _, secret := range []string{"s3cret", "admin", "5432"} {
+				if strings.Contains(err.Error(), secret) {
+					t.Errorf("LoadSecrets() error = %q echoes the payload", err)
+				}
+			}
+		})
+	}
+}
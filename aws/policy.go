@@ -0,0 +1,250 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"go.uber.org/zap"
+)
+
+const (
+	// PolicyAccessAllowed reports a resource policy explicitly allowing the caller to read.
+	PolicyAccessAllowed PolicyAccess = "allowed"
+
+	// PolicyAccessDenied reports a resource policy explicitly denying the caller to read.
+	PolicyAccessDenied PolicyAccess = "denied"
+
+	// PolicyAccessNotGranted reports a resource policy, or its absence, that neither allows
+	// nor denies the caller. Access then depends on the caller's identity policies alone,
+	// which is enough within the secret's account but not across accounts.
+	PolicyAccessNotGranted PolicyAccess = "not_granted"
+)
+
+// readAction is the action a resource policy must allow to read the secret.
+const readAction = "secretsmanager:GetSecretValue"
+
+type (
+	// PolicyAccess summarizes what the resource policy says about the caller reading the secret.
+	PolicyAccess string
+
+	// PolicyReport describes the resource policy of the configured secret with regard to
+	// the identity the client runs as. It never holds secret values.
+	PolicyReport struct {
+		Principal        string       // ARN of the calling identity
+		HasPolicy        bool         // Whether the secret has a resource policy
+		PolicyValid      bool         // Whether ValidateResourcePolicy passed
+		ValidationErrors []string     // Messages reported by ValidateResourcePolicy
+		Access           PolicyAccess // What the statements say about the principal reading
+	}
+
+	// callerIdentityAPI is the subset of the STS client used to identify the caller.
+	callerIdentityAPI interface {
+		GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+	}
+
+	// policyDocument is the part of an IAM policy document used to evaluate access.
+	policyDocument struct {
+		Statement policyStatements `json:"Statement"`
+	}
+
+	// policyStatement is a statement of an IAM policy document.
+	policyStatement struct {
+		Effect    string          `json:"Effect"`
+		Principal json.RawMessage `json:"Principal"`
+		Action    stringOrList    `json:"Action"`
+	}
+
+	// policyStatements accepts a single statement object or a list of them.
+	policyStatements []policyStatement
+
+	// stringOrList accepts a JSON string or a list of strings.
+	stringOrList []string
+)
+
+// CheckResourcePolicy reports whether the resource policy of the configured secret lets
+// the identity the client runs as read it, to debug AccessDenied errors before runtime.
+// It calls GetCallerIdentity, GetResourcePolicy and ValidateResourcePolicy; no secret
+// value is read.
+//
+// The evaluation only considers the Effect, Principal and Action elements of the
+// statements. Conditions, NotPrincipal and NotAction are ignored, so the result is a
+// diagnostic aid rather than an authoritative decision.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - The report on the resource policy
+//   - An error if the caller, the policy or its validation cannot be retrieved
+func (c *awsSecretClient) CheckResourcePolicy(ctx context.Context) (PolicyReport, error) {
+	identity, err := c.identity.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		c.logger.Error("error to get caller identity", zap.Error(err))
+		return PolicyReport{}, err
+	}
+
+	report := PolicyReport{
+		Principal:   aws.ToString(identity.Arn),
+		PolicyValid: true,
+		Access:      PolicyAccessNotGranted,
+	}
+
+	res, err := c.api().GetResourcePolicy(ctx, &secretsmanager.GetResourcePolicyInput{
+		SecretId: aws.String(c.appSecretId),
+	})
+	if err != nil {
+		c.logger.Error("error to get resource policy", zap.Error(err))
+		return PolicyReport{}, mapError(err)
+	}
+
+	policy := aws.ToString(res.ResourcePolicy)
+	if policy == "" {
+		return report, nil
+	}
+
+	report.HasPolicy = true
+
+	validation, err := c.api().ValidateResourcePolicy(ctx, &secretsmanager.ValidateResourcePolicyInput{
+		SecretId:       aws.String(c.appSecretId),
+		ResourcePolicy: aws.String(policy),
+	})
+	if err != nil {
+		c.logger.Error("error to validate resource policy", zap.Error(err))
+		return PolicyReport{}, err
+	}
+
+	report.PolicyValid = validation.PolicyValidationPassed
+	for _, validationErr := range validation.ValidationErrors {
+		report.ValidationErrors = append(report.ValidationErrors, aws.ToString(validationErr.ErrorMessage))
+	}
+
+	var doc policyDocument
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return PolicyReport{}, fmt.Errorf("parse resource policy: %w", err)
+	}
+
+	report.Access = evaluatePolicy(doc, report.Principal, aws.ToString(identity.Account))
+
+	return report, nil
+}
+
+// evaluatePolicy determines what the statements say about principal reading the secret.
+// An explicit deny wins over any allow.
+func evaluatePolicy(doc policyDocument, principal, account string) PolicyAccess {
+	candidates := principalCandidates(principal, account)
+
+	access := PolicyAccessNotGranted
+	for _, stmt := range doc.Statement {
+		if !matchesAction(stmt.Action) || !matchesPrincipal(stmt.Principal, candidates) {
+			continue
+		}
+
+		switch stmt.Effect {
+		case "Deny":
+			return PolicyAccessDenied
+		case "Allow":
+			access = PolicyAccessAllowed
+		}
+	}
+
+	return access
+}
+
+// principalCandidates returns the principal values designating the caller: its ARN, the
+// role ARN of an assumed-role session, and its account.
+func principalCandidates(principal, account string) []string {
+	candidates := []string{principal, account, fmt.Sprintf("arn:aws:iam::%s:root", account)}
+
+	// arn:aws:sts::111122223333:assumed-role/Role/session designates arn:aws:iam::111122223333:role/Role
+	parts := strings.Split(principal, ":")
+	if len(parts) == 6 && parts[2] == "sts" && strings.HasPrefix(parts[5], "assumed-role/") {
+		resource := strings.Split(parts[5], "/")
+		if len(resource) >= 2 {
+			candidates = append(candidates, fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], resource[1]))
+		}
+	}
+
+	return candidates
+}
+
+// matchesAction reports whether the actions include reading the secret value.
+func matchesAction(actions stringOrList) bool {
+	for _, action := range actions {
+		switch strings.ToLower(action) {
+		case "*", "secretsmanager:*", strings.ToLower(readAction):
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPrincipal reports whether the Principal element designates one of candidates.
+func matchesPrincipal(raw json.RawMessage, candidates []string) bool {
+	var wildcard string
+	if json.Unmarshal(raw, &wildcard) == nil {
+		return wildcard == "*"
+	}
+
+	var principals map[string]stringOrList
+	if json.Unmarshal(raw, &principals) != nil {
+		return false
+	}
+
+	for _, value := range principals["AWS"] {
+		if value == "*" {
+			return true
+		}
+
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// UnmarshalJSON accepts a single statement object or a list of them.
+func (s *policyStatements) UnmarshalJSON(data []byte) error {
+	var single policyStatement
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = policyStatements{single}
+		return nil
+	}
+
+	var list []policyStatement
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*s = list
+	return nil
+}
+
+// UnmarshalJSON accepts a JSON string or a list of strings.
+func (l *stringOrList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringOrList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*l = list
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// policyAPI serves a fixed resource policy and its validation result.
+type policyAPI struct {
+	secretsManagerAPI
+
+	policy           string
+	validationErrors []string
+	values           int
+}
+
+func (p *policyAPI) GetResourcePolicy(
+	_ context.Context,
+	params *secretsmanager.GetResourcePolicyInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.GetResourcePolicyOutput, error) {
+	if aws.ToString(params.SecretId) != "dev/app" {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("secret not found")}
+	}
+	if p.policy == "" {
+		return &secretsmanager.GetResourcePolicyOutput{Name: params.SecretId}, nil
+	}
+
+	return &secretsmanager.GetResourcePolicyOutput{Name: params.SecretId, ResourcePolicy: aws.String(p.policy)}, nil
+}
+
+func (p *policyAPI) ValidateResourcePolicy(
+	_ context.Context,
+	_ *secretsmanager.ValidateResourcePolicyInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.ValidateResourcePolicyOutput, error) {
+	res := &secretsmanager.ValidateResourcePolicyOutput{PolicyValidationPassed: len(p.validationErrors) == 0}
+	for _, message := range p.validationErrors {
+		res.ValidationErrors = append(res.ValidationErrors, types.ValidationErrorsEntry{ErrorMessage: aws.String(message)})
+	}
+
+	return res, nil
+}
+
+func (p *policyAPI) GetSecretValue(
+	context.Context,
+	*secretsmanager.GetSecretValueInput,
+	...func(*secretsmanager.Options),
+) (*secretsmanager.GetSecretValueOutput, error) {
+	p.values++
+	return nil, errors.New("secret values must not be read")
+}
+
+func TestCheckResourcePolicy(t *testing.T) {
+	const (
+		account = "111122223333"
+		session = "arn:aws:sts::111122223333:assumed-role/reader/session"
+	)
+
+	tests := []struct {
+		name string
+		api  *policyAPI
+		want PolicyReport
+	}{
+		{
+			name: "no policy",
+			api:  &policyAPI{},
+			want: PolicyReport{Principal: session, PolicyValid: true, Access: PolicyAccessNotGranted},
+		},
+		{
+			name: "role allowed",
+			api: &policyAPI{policy: `{"Version":"2012-10-17","Statement":{"Effect":"Allow",
+				"Principal":{"AWS":"arn:aws:iam::111122223333:role/reader"},"Action":"secretsmanager:GetSecretValue","Resource":"*"}}`},
+			want: PolicyReport{Principal: session, HasPolicy: true, PolicyValid: true, Access: PolicyAccessAllowed},
+		},
+		{
+			name: "account allowed, role denied",
+			api: &policyAPI{policy: `{"Version":"2012-10-17","Statement":[
+				{"Effect":"Allow","Principal":{"AWS":"111122223333"},"Action":["secretsmanager:*"],"Resource":"*"},
+				{"Effect":"Deny","Principal":{"AWS":["arn:aws:iam::111122223333:role/reader"]},"Action":"*","Resource":"*"}]}`},
+			want: PolicyReport{Principal: session, HasPolicy: true, PolicyValid: true, Access: PolicyAccessDenied},
+		},
+		{
+			name: "other principal",
+			api: &policyAPI{
+				policy: `{"Version":"2012-10-17","Statement":{"Effect":"Allow",
+					"Principal":{"AWS":"arn:aws:iam::444455556666:root"},"Action":"secretsmanager:GetSecretValue","Resource":"*"}}`,
+				validationErrors: []string{"policy grants access to an external account"},
+			},
+			want: PolicyReport{
+				Principal:        session,
+				HasPolicy:        true,
+				ValidationErrors: []string{"policy grants access to an external account"},
+				Access:           PolicyAccessNotGranted,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(tt.api, "dev/app")
+			c.identity = &staticIdentity{arn: session, account: account}
+
+			got, err := c.CheckResourcePolicy(context.Background())
+			if err != nil {
+				t.Fatalf("CheckResourcePolicy() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckResourcePolicy() = %+v, want %+v", got, tt.want)
+			}
+			if tt.api.values != 0 {
+				t.Error("CheckResourcePolicy() read the secret value")
+			}
+		})
+	}
+}
+
+func TestCheckResourcePolicyErrors(t *testing.T) {
+	errSTS := errors.New("sts unavailable")
+
+	c := newTestClient(&policyAPI{}, "dev/app")
+	c.identity = &staticIdentity{err: errSTS}
+	if _, err := c.CheckResourcePolicy(context.Background()); !errors.Is(err, errSTS) {
+		t.Errorf("CheckResourcePolicy() error = %v, want the identity error", err)
+	}
+
+	missing := newTestClient(&policyAPI{}, "dev/missing")
+	missing.identity = &staticIdentity{arn: "arn:aws:iam::111122223333:user/ops", account: "111122223333"}
+	if _, err := missing.CheckResourcePolicy(context.Background()); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("CheckResourcePolicy() error = %v for a missing secret, want ErrSecretNotFound", err)
+	}
+}
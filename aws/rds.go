@@ -0,0 +1,52 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+)
+
+// GetRDSAuthToken generates a short-lived IAM authentication token for an RDS database,
+// used as the password instead of a stored one. Tokens are valid for 15 minutes, so a
+// new one should be generated for every connection opened after that.
+//
+// Credentials are resolved like for NewAwsSecretClient, so options such as WithProfile,
+// WithWebIdentity and WithIMDSv2Only apply; options unrelated to credentials are ignored.
+//
+// Parameters:
+//   - ctx: Context for controlling the credentials retrieval
+//   - endpoint: The database host and port, e.g. "db.cluster.rds.amazonaws.com:5432"
+//   - region: The database region, or empty to use the configured default region
+//   - dbUser: The database user granted rds-db:connect
+//   - opts: Optional settings applied to the AWS configuration
+//
+// Returns:
+//   - The signed authentication token
+//   - An error if the credentials cannot be resolved or the token cannot be signed
+func GetRDSAuthToken(ctx context.Context, endpoint, region, dbUser string, opts ...Option) (string, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	awsCfg, err := loadAwsConfig(ctx, o)
+	if err != nil {
+		return "", err
+	}
+
+	if region == "" {
+		region = awsCfg.Region
+	}
+
+	token, err := auth.BuildAuthToken(ctx, endpoint, region, dbUser, awsCfg.Credentials)
+	if err != nil {
+		return "", fmt.Errorf("build rds auth token: %w", err)
+	}
+
+	return token, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestGetRDSAuthToken(t *testing.T) {
+	isolateAWSConfig(t)
+
+	provider := credentials.NewStaticCredentialsProvider("AKIDRDS", "secret-key", "")
+	tests := []struct {
+		name       string
+		region     string
+		wantRegion string
+	}{
+		{name: "explicit region", region: "us-east-2", wantRegion: "us-east-2"},
+		{name: "default region", wantRegion: "eu-west-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := "db.cluster.rds.amazonaws.com:5432"
+			token, err := GetRDSAuthToken(context.Background(), endpoint, tt.region, "app_user", WithCredentialsProvider(provider))
+			if err != nil {
+				t.Fatalf("GetRDSAuthToken() error = %v", err)
+			}
+
+			if !strings.HasPrefix(token, endpoint+"?") {
+				t.Fatalf("token = %q, want it prefixed with %q", token, endpoint+"?")
+			}
+
+			u, err := url.Parse("https://" + token)
+			if err != nil {
+				t.Fatalf("parse token: %v", err)
+			}
+
+			query := u.Query()
+			if got := query.Get("Action"); got != "connect" {
+				t.Errorf("Action = %q, want connect", got)
+			}
+			if got := query.Get("DBUser"); got != "app_user" {
+				t.Errorf("DBUser = %q, want app_user", got)
+			}
+
+			// X-Amz-Credential is <access key>/<date>/<region>/rds-db/aws4_request
+			scope := strings.Split(query.Get("X-Amz-Credential"), "/")
+			if len(scope) != 5 || scope[0] != "AKIDRDS" || scope[2] != tt.wantRegion || scope[3] != "rds-db" {
+				t.Errorf("X-Amz-Credential = %q, want key AKIDRDS in region %s for rds-db", query.Get("X-Amz-Credential"), tt.wantRegion)
+			}
+		})
+	}
+}
+
+func TestGetRDSAuthTokenEndpointWithoutPort(t *testing.T) {
+	isolateAWSConfig(t)
+
+	provider := credentials.NewStaticCredentialsProvider("AKIDRDS", "secret-key", "")
+	token, err := GetRDSAuthToken(context.Background(), "db.cluster.rds.amazonaws.com", "", "app_user", WithCredentialsProvider(provider))
+	if err == nil {
+		t.Fatalf("GetRDSAuthToken() = %q, want an error for an endpoint without a port", token)
+	}
+}
+
+func TestGetRDSAuthTokenMissingCredentials(t *testing.T) {
+	isolateAWSConfig(t)
+
+	token, err := GetRDSAuthToken(context.Background(), "db.cluster.rds.amazonaws.com:5432", "", "app_user")
+	if err == nil {
+		t.Fatalf("GetRDSAuthToken() = %q, want an error without credentials", token)
+	}
+	if token != "" {
+		t.Errorf("token = %q, want empty on error", token)
+	}
+}
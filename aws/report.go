@@ -0,0 +1,64 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// LoadSecretsReport loads every configured secret identifier and reports the outcome of
+// each one, along with the time it took. Unlike LoadSecrets, every identifier is fetched
+// even when another fails, so the report is complete. The cache is only replaced when
+// all identifiers loaded. It implements the secretsmanager.LoadReporter interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - The per-identifier report
+//   - An error if any identifier failed, as returned by the report's Err method, or if
+//     the merged secrets fail validation
+func (c *awsSecretClient) LoadSecretsReport(ctx context.Context) (sm.LoadReport, error) {
+	start := c.clock.Now()
+	ids := c.secretIds()
+
+	report := sm.LoadReport{Results: make([]sm.LoadResult, 0, len(ids))}
+	loaded := make(map[string]map[string]string, len(ids))
+
+	for _, id := range ids {
+		fetchStart := c.clock.Now()
+		secrets, err := c.fetchLevel(ctx, id)
+
+		report.Results = append(report.Results, sm.LoadResult{
+			SecretId: id,
+			Err:      err,
+			Duration: c.clock.Now().Sub(fetchStart),
+			Keys:     len(secrets),
+		})
+
+		if err == nil {
+			loaded[id] = secrets
+		}
+	}
+
+	report.Duration = c.clock.Now().Sub(start)
+
+	if err := report.Err(); err != nil {
+		return report, err
+	}
+
+	secrets, sources, err := c.merge(ids, loaded)
+	if err != nil {
+		return report, err
+	}
+
+	if err := c.store(ctx, secrets, sources); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+type (
+	// sharedRegistry holds the latest GetSecretValue response of each secret fetched by
+	// the clients created with WithSharedCache, so they share one fetch per load.
+	sharedRegistry struct {
+		group singleflight.Group
+
+		mu      sync.Mutex
+		entries map[sharedKey]*sharedEntry
+	}
+
+	// sharedKey identifies a secret in the shared registry. The same secret ID designates
+	// different secrets in other accounts and regions, and other identities may not be
+	// allowed to read it, so responses are only shared between clients running as the
+	// same identity in the same region.
+	sharedKey struct {
+		principal string // ARN of the calling identity
+		region    string // Region of the client
+		secretId  string // Secret identifier as configured
+	}
+
+	// sharedEntry is the latest response of a secret and its generation, incremented by
+	// every fetch.
+	sharedEntry struct {
+		generation uint64
+		res        *secretsmanager.GetSecretValueOutput
+	}
+)
+
+// sharedLoads is the process-wide registry of the clients using WithSharedCache.
+var sharedLoads = &sharedRegistry{entries: make(map[sharedKey]*sharedEntry)}
+
+// String returns the singleflight key of k.
+func (k sharedKey) String() string {
+	return fmt.Sprintf("%q %q %q", k.principal, k.region, k.secretId)
+}
+
+// get returns the response of the secret key fetched after generation seen, calling fetch
+// when no such response exists. Concurrent fetches of the same secret share one call,
+// which uses the context of the first caller. It also returns the generation of the
+// response.
+func (r *sharedRegistry) get(
+	ctx context.Context,
+	key sharedKey,
+	seen uint64,
+	fetch func(context.Context) (*secretsmanager.GetSecretValueOutput, error),
+) (*secretsmanager.GetSecretValueOutput, uint64, error) {
+	r.mu.Lock()
+	entry := r.entries[key]
+	r.mu.Unlock()
+
+	if entry != nil && entry.generation > seen {
+		return entry.res, entry.generation, nil
+	}
+
+	v, err, _ := r.group.Do(key.String(), func() (interface{}, error) {
+		res, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		entry := &sharedEntry{generation: 1, res: res}
+		if prev := r.entries[key]; prev != nil {
+			entry.generation = prev.generation + 1
+		}
+		r.entries[key] = entry
+
+		return entry, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entry = v.(*sharedEntry)
+
+	return entry.res, entry.generation, nil
+}
+
+// fetchShared is fetchRaw going through the shared registry: the client reuses the
+// response another client fetched since its own last load, and fetches it otherwise.
+func (c *awsSecretClient) fetchShared(ctx context.Context, secretId string) (map[string]string, error) {
+	key, err := c.sharedKey(ctx, secretId)
+	if err != nil {
+		c.logger.Error("error to identify the caller of a shared load", zap.String("secretId", secretId), zap.Error(err))
+		return nil, err
+	}
+
+	c.sharedMu.Lock()
+	seen := c.sharedSeen[secretId]
+	c.sharedMu.Unlock()
+
+	res, generation, err := sharedLoads.get(ctx, key, seen, func(ctx context.Context) (*secretsmanager.GetSecretValueOutput, error) {
+		return c.getSecretValue(ctx, secretId)
+	})
+	if err != nil {
+		c.logger.Error("error to get secret", zap.String("secretId", secretId), zap.Error(err))
+		return nil, mapError(err)
+	}
+
+	c.sharedMu.Lock()
+	c.sharedSeen[secretId] = generation
+	c.sharedMu.Unlock()
+
+	return c.decode(secretId, res)
+}
+
+// sharedKey returns the key of secretId in the shared registry. The caller identity is
+// resolved with STS on the first shared load of the client, then reused.
+func (c *awsSecretClient) sharedKey(ctx context.Context, secretId string) (sharedKey, error) {
+	c.sharedMu.Lock()
+	principal := c.principal
+	c.sharedMu.Unlock()
+
+	if principal == "" {
+		identity, err := c.identity.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return sharedKey{}, fmt.Errorf("get caller identity: %w", err)
+		}
+
+		principal = aws.ToString(identity.Arn)
+
+		c.sharedMu.Lock()
+		c.principal = principal
+		c.sharedMu.Unlock()
+	}
+
+	return sharedKey{principal: principal, region: c.region, secretId: secretId}, nil
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// staticIdentity is an STS client reporting a fixed caller.
+type staticIdentity struct {
+	arn     string
+	account string
+	err     error
+	calls   atomic.Int32
+}
+
+func (s *staticIdentity) GetCallerIdentity(
+	_ context.Context,
+	_ *sts.GetCallerIdentityInput,
+	_ ...func(*sts.Options),
+) (*sts.GetCallerIdentityOutput, error) {
+	s.calls.Add(1)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(s.arn), Account: aws.String(s.account)}, nil
+}
+
+// newSharedClient creates a client using the shared cache as principal in region.
+func newSharedClient(api secretsManagerAPI, secretId, region string, identity callerIdentityAPI) *awsSecretClient {
+	c := newTestClient(api, secretId, WithSharedCache())
+	c.region = region
+	c.identity = identity
+
+	return c
+}
+
+func TestSharedCacheScopedByIdentityAndRegion(t *testing.T) {
+	const secretId = "shared-cache-test/scoped"
+
+	api := newMockSecretsManager(map[string]string{secretId: `{"db_password":"s3cret"}`})
+	reader := &staticIdentity{arn: "arn:aws:iam::111111111111:role/reader"}
+	other := &staticIdentity{arn: "arn:aws:iam::222222222222:role/reader"}
+
+	clients := []struct {
+		name      string
+		client    *awsSecretClient
+		wantCalls int
+	}{
+		{name: "first", client: newSharedClient(api, secretId, "eu-west-1", reader), wantCalls: 1},
+		{name: "same identity and region", client: newSharedClient(api, secretId, "eu-west-1", reader), wantCalls: 1},
+		{name: "other region", client: newSharedClient(api, secretId, "us-east-1", reader), wantCalls: 2},
+		{name: "other identity", client: newSharedClient(api, secretId, "eu-west-1", other), wantCalls: 3},
+	}
+
+	for _, tt := range clients {
+		if err := tt.client.LoadSecrets(context.Background()); err != nil {
+			t.Fatalf("%s: LoadSecrets() error = %v", tt.name, err)
+		}
+		if got := api.Calls("GetSecretValue"); got != tt.wantCalls {
+			t.Errorf("%s: GetSecretValue calls = %d, want %d", tt.name, got, tt.wantCalls)
+		}
+		if value, _ := tt.client.GetSecret(context.Background(), "db_password"); value != "s3cret" {
+			t.Errorf("%s: GetSecret() = %q, want the shared value", tt.name, value)
+		}
+	}
+
+	if err := clients[0].client.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := reader.calls.Load(); got != 3 {
+		t.Errorf("GetCallerIdentity calls = %d, want one per client", got)
+	}
+}
+
+func TestSharedCacheIdentityFailure(t *testing.T) {
+	const secretId = "shared-cache-test/identity-failure"
+
+	errSTS := errors.New("sts unavailable")
+	api := newMockSecretsManager(map[string]string{secretId: `{"db_password":"s3cret"}`})
+	c := newSharedClient(api, secretId, "eu-west-1", &staticIdentity{err: errSTS})
+
+	if err := c.LoadSecrets(context.Background()); !errors.Is(err, errSTS) {
+		t.Errorf("LoadSecrets() error = %v, want the STS error", err)
+	}
+	if got := api.Calls("GetSecretValue"); got != 0 {
+		t.Errorf("GetSecretValue calls = %d, want nothing fetched for an unknown caller", got)
+	}
+}
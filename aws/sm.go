@@ -9,27 +9,114 @@ package aws
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 	"github.com/goxkit/configs"
 	"github.com/goxkit/logging"
 	"go.uber.org/zap"
 
 	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/logs"
 )
 
+// secretsManagerAPI is the subset of the AWS Secrets Manager client used by this package.
+// It exists so the client can be replaced by a mock in tests.
+type secretsManagerAPI interface {
+	GetSecretValue(
+		ctx context.Context,
+		params *secretsmanager.GetSecretValueInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.GetSecretValueOutput, error)
+
+	DescribeSecret(
+		ctx context.Context,
+		params *secretsmanager.DescribeSecretInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.DescribeSecretOutput, error)
+
+	PutSecretValue(
+		ctx context.Context,
+		params *secretsmanager.PutSecretValueInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.PutSecretValueOutput, error)
+
+	BatchGetSecretValue(
+		ctx context.Context,
+		params *secretsmanager.BatchGetSecretValueInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.BatchGetSecretValueOutput, error)
+
+	GetResourcePolicy(
+		ctx context.Context,
+		params *secretsmanager.GetResourcePolicyInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.GetResourcePolicyOutput, error)
+
+	ValidateResourcePolicy(
+		ctx context.Context,
+		params *secretsmanager.ValidateResourcePolicyInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.ValidateResourcePolicyOutput, error)
+
+	UpdateSecretVersionStage(
+		ctx context.Context,
+		params *secretsmanager.UpdateSecretVersionStageInput,
+		optFns ...func(*secretsmanager.Options),
+	) (*secretsmanager.UpdateSecretVersionStageOutput, error)
+}
+
 // awsSecretClient is an implementation of the SecretClient interface that uses
 // AWS Secrets Manager to store and retrieve secrets. It maintains an in-memory
 // cache of secrets to minimize API calls and improve performance.
 type awsSecretClient struct {
-	logger      logging.Logger
-	client      *secretsmanager.Client
-	appSecretId string            // The AWS Secrets Manager secret identifier
-	secrets     map[string]string // In-memory cache of secret key-value pairs
+	logger       logging.Logger
+	opts         *options                // Options used to build the AWS configuration
+	client       secretsManagerAPI       // Guarded by mu since it's rebuilt on credential expiry
+	appSecretId  string                  // The AWS Secrets Manager secret identifier
+	region       string                  // Region of the AWS configuration
+	secondaryId  string                  // Optional fallback secret identifier
+	baseId       string                  // Optional base secret overlaid by the environment one
+	namespace    string                  // Key prefix isolating a tenant's secrets
+	separator    string                  // Separator between the namespace and key names
+	partialLoad  bool                    // Whether to keep the secrets loaded before the context deadline
+	plainKey     string                  // Key under which a non-JSON secret is cached
+	noCache      bool                    // Whether every lookup reloads the secret
+	valueRules   map[string]sm.ValueRule // Quality checks applied to values at load
+	base64Bin    bool                    // Whether binary payloads are base64-encoded
+	clock        sm.Clock                // Source of the current time
+	loadedAt     time.Time               // When the cache was last loaded, guarded by mu
+	identity     callerIdentityAPI       // STS client identifying the caller for policy checks
+	cipher       valueCipher             // Client-side encryption of written values, nil disables it
+	writeMu      sync.Mutex              // Serializes read-modify-write cycles of WriteSecret
+	onlyKeys     []string                // Keys retained in the cache, empty keeps all
+	maxPayload   int                     // Largest accepted secret payload in bytes
+	aliases      map[string]string       // Alternative key names mapped to cached keys
+	mu           sync.RWMutex            // Guards the cache store swaps and the fields below
+	cache        sm.CacheStore           // Cache of secret key-value pairs
+	sources      map[string]string       // Secret identifier each cached key was loaded from
+	stale        map[string]bool         // Invalidated keys triggering a reload on next access
+	sharedMu     sync.Mutex              // Guards sharedSeen and principal
+	sharedSeen   map[string]uint64       // Shared registry generation last loaded per secret
+	principal    string                  // ARN of the caller, resolved on the first shared load
+	datesMu      sync.Mutex              // Guards versionDates
+	versionDates map[string]time.Time    // Creation date of the version last read per secret
 }
 
 // NewAwsSecretClient creates a new instance of AWS Secrets Manager client.
@@ -40,91 +127,919 @@ type awsSecretClient struct {
 //
 // Parameters:
 //   - cfgs: Application configuration containing environment, secret key, and logger
+//   - opts: Optional settings such as web identity credentials
 //
 // Returns:
 //   - A SecretClient interface implementation for AWS Secrets Manager
 //   - An error if AWS configuration cannot be loaded
-func NewAwsSecretClient(cfgs *configs.Configs) (sm.SecretClient, error) {
-	logger := cfgs.Logger
+func NewAwsSecretClient(cfgs *configs.Configs, opts ...Option) (sm.SecretClient, error) {
+	logger := logs.FromConfigs(cfgs)
+
+	o := &options{
+		maxPayloadSize: DefaultMaxPayloadSize,
+		clock:          sm.SystemClock,
+		separator:      DefaultKeySeparator,
+		sizeWarning:    DefaultSizeWarningThreshold,
+		locker:         sm.NopLocker,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
 
-	awsCfg, err := config.LoadDefaultConfig(context.Background())
+	if o.separator == "" {
+		o.separator = DefaultKeySeparator
+	}
+
+	if o.locker == nil {
+		o.locker = sm.NopLocker
+	}
+
+	if o.cacheStore == nil {
+		o.cacheStore = sm.NewMemoryStore()
+	}
+
+	env := cfgs.AppConfigs.Environment.ToString()
+
+	// Select the region of the environment, the default chain decides when none is mapped
+	if region, ok := o.environmentRegions[env]; ok {
+		o.region = region
+	}
+
+	awsCfg, err := loadAwsConfig(context.Background(), o)
 	if err != nil {
 		logger.Error("error get aws configs from env", zap.Error(err))
 		return nil, err
 	}
 
 	// Format the secret ID using environment and app secret key
-	appSecretId := fmt.Sprintf("%s/%s", cfgs.AppConfigs.Environment.ToString(), cfgs.AppConfigs.SecretKey)
+	appSecretId := fmt.Sprintf("%s/%s", env, cfgs.AppConfigs.SecretKey)
+	if o.secretARN != "" {
+		appSecretId = o.secretARN
+	}
+
+	// The base secret holds the values shared by every environment
+	var baseId string
+	if o.baseOverlay {
+		baseId = fmt.Sprintf("%s/%s", BaseEnvironment, cfgs.AppConfigs.SecretKey)
+	}
+
+	plainKey := o.plainKey
+	if plainKey == "" {
+		plainKey = cfgs.AppConfigs.SecretKey
+	}
+
+	var cipher valueCipher
+	if o.kmsKeyId != "" {
+		cipher = newKMSCipher(kms.NewFromConfig(awsCfg), o.kmsKeyId, o.kmsContext)
+	}
 
 	return &awsSecretClient{
-		logger:      logger,
-		client:      secretsmanager.NewFromConfig(awsCfg),
-		appSecretId: appSecretId,
-		secrets:     make(map[string]string),
+		cipher:       cipher,
+		logger:       logger,
+		opts:         o,
+		client:       newSecretsManagerClient(awsCfg, o),
+		identity:     sts.NewFromConfig(awsCfg),
+		appSecretId:  appSecretId,
+		region:       awsCfg.Region,
+		secondaryId:  o.secondarySecretId,
+		baseId:       baseId,
+		namespace:    strings.TrimSuffix(o.namespace, o.separator),
+		separator:    o.separator,
+		partialLoad:  o.partialLoad,
+		plainKey:     plainKey,
+		noCache:      o.noCache,
+		valueRules:   o.valueRules,
+		base64Bin:    o.base64Binary,
+		clock:        o.clock,
+		onlyKeys:     o.onlyKeys,
+		maxPayload:   o.maxPayloadSize,
+		aliases:      o.aliases,
+		cache:        o.cacheStore,
+		sources:      make(map[string]string),
+		sharedSeen:   make(map[string]uint64),
+		versionDates: make(map[string]time.Time),
 	}, nil
 }
 
+// loadAwsConfig loads the AWS configuration from the default chain, or the configured
+// shared profile, and applies the credential overrides requested through the options.
+func loadAwsConfig(ctx context.Context, o *options) (aws.Config, error) {
+	awsCfg, err := config.LoadDefaultConfig(ctx, loadOptions(o)...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	if o.credentials == nil && o.webIdentityRoleARN != "" && o.webIdentityTokenFile != "" {
+		provider := stscreds.NewWebIdentityRoleProvider(
+			sts.NewFromConfig(awsCfg),
+			o.webIdentityRoleARN,
+			stscreds.IdentityTokenFile(o.webIdentityTokenFile),
+		)
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	return awsCfg, nil
+}
+
+// loadOptions returns the options of the AWS configuration loader matching o.
+func loadOptions(o *options) []func(*config.LoadOptions) error {
+	var loadOpts []func(*config.LoadOptions) error
+
+	if o.profile != "" {
+		loadOpts = append(loadOpts, config.WithSharedConfigProfile(o.profile))
+	}
+
+	if o.region != "" {
+		loadOpts = append(loadOpts, config.WithRegion(o.region))
+	}
+
+	if o.imdsV2Only {
+		// Require session tokens on every IMDS call instead of falling back to IMDSv1
+		loadOpts = append(loadOpts, config.WithEC2RoleCredentialOptions(func(eo *ec2rolecreds.Options) {
+			eo.Client = imds.New(imds.Options{EnableFallback: aws.FalseTernary})
+		}))
+	}
+
+	if o.credentials != nil {
+		// Bypasses the default chain; the loader wraps the provider in a credentials cache
+		loadOpts = append(loadOpts, config.WithCredentialsProvider(o.credentials))
+	}
+
+	if o.retryBudget != nil {
+		// Replaces the retry quota of the standard retryer by the shared budget
+		loadOpts = append(loadOpts, config.WithRetryer(func() aws.Retryer {
+			return retry.NewStandard(func(so *retry.StandardOptions) {
+				so.RateLimiter = budgetLimiter{budget: o.retryBudget}
+			})
+		}))
+	}
+
+	return loadOpts
+}
+
+// budgetLimiter adapts a sm.RetryBudget to the retry quota of the SDK's standard retryer.
+// Every retry costs one token, whatever the cost the retryer assigns to it.
+type budgetLimiter struct {
+	budget *sm.RetryBudget
+}
+
+// GetToken consumes a token of the budget, failing with sm.ErrRetryBudgetExhausted when
+// none is left.
+func (l budgetLimiter) GetToken(_ context.Context, _ uint) (func() error, error) {
+	if !l.budget.Allow() {
+		return nil, sm.ErrRetryBudgetExhausted
+	}
+
+	return func() error { return nil }, nil
+}
+
+// AddTokens does nothing: the budget refills over time rather than on successful calls.
+func (budgetLimiter) AddTokens(uint) error {
+	return nil
+}
+
+// newSecretsManagerClient creates the Secrets Manager client, targeting the endpoint
+// configured with WithEndpoint if any.
+func newSecretsManagerClient(awsCfg aws.Config, o *options) *secretsmanager.Client {
+	return secretsmanager.NewFromConfig(awsCfg, func(so *secretsmanager.Options) {
+		if o.endpoint == "" {
+			return
+		}
+
+		// FIPS and dual-stack variants cannot be combined with a custom endpoint
+		so.BaseEndpoint = aws.String(o.endpoint)
+		so.EndpointOptions.UseFIPSEndpoint = aws.FIPSEndpointStateDisabled
+		so.EndpointOptions.UseDualStackEndpoint = aws.DualStackEndpointStateDisabled
+	})
+}
+
 // LoadSecrets retrieves all secrets from AWS Secrets Manager for the configured secret ID.
 //
 // This method makes an API call to AWS Secrets Manager to fetch the secret value as a JSON blob,
 // then unmarshals it into an in-memory map of string keys to string values. This approach
 // enables fast access to secrets without requiring repeated calls to AWS for each secret lookup.
+// A secret that isn't a JSON object is treated as a single value cached under the plain key,
+// which defaults to the configured secret key and can be changed with WithPlainKey.
 //
 // The method should be called during application initialization to ensure secrets are available
 // when needed. If the secret values change in AWS Secrets Manager, the application would need
 // to be restarted or this method called again to refresh the cached values.
 //
+// When several secrets are configured, they are fetched with a single BatchGetSecretValue
+// call, falling back to one GetSecretValue call per secret when the batch API fails.
+// When several secrets are configured and WithPartialLoad is set, they are fetched
+// concurrently and the secrets loaded before the context deadline are kept in the cache
+// even though an error wrapping context.DeadlineExceeded is returned.
+//
 // Parameters:
 //   - ctx: Context for controlling the request lifecycle
 //
 // Returns:
-//   - An error if the secret cannot be fetched or parsed
+//   - An error if the secret cannot be fetched or parsed, matching sm.ErrSecretNotFound
+//     when the secret doesn't exist. Errors returned by AWS are wrapped, never replaced,
+//     so callers can match the SDK types with errors.As, e.g. *types.DecryptionFailure.
 func (c *awsSecretClient) LoadSecrets(ctx context.Context) error {
+	secrets, sources, err := c.load(ctx)
+	if secrets == nil {
+		return err
+	}
+
+	if storeErr := c.store(ctx, secrets, sources); storeErr != nil {
+		return storeErr
+	}
+
+	return err
+}
+
+// Refresh reloads the secrets like LoadSecrets and returns the keys that changed from
+// the previous cache. It implements the secretsmanager.Refresher interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - The added, changed and removed keys, without their values
+//   - An error if the secrets cannot be loaded, in which case the cache is kept
+func (c *awsSecretClient) Refresh(ctx context.Context) ([]sm.ChangeEvent, error) {
+	secrets, sources, err := c.load(ctx)
+	if secrets == nil {
+		return nil, err
+	}
+
+	previous, snapErr := sm.StoreSnapshot(ctx, c.cache)
+	if snapErr != nil {
+		return nil, snapErr
+	}
+
+	if storeErr := c.store(ctx, secrets, sources); storeErr != nil {
+		return nil, storeErr
+	}
+
+	return sm.DiffSecrets(previous, secrets), err
+}
+
+// store replaces the cache with the given secrets and their sources.
+func (c *awsSecretClient) store(ctx context.Context, secrets, sources map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.cache.SetAll(ctx, secrets); err != nil {
+		c.logger.Error("error to store secrets in the cache", zap.Error(err))
+		return err
+	}
+
+	c.sources = sources
+	c.stale = nil
+	c.loadedAt = c.clock.Now()
+
+	return nil
+}
+
+// load fetches, merges, filters and validates the configured secrets without touching
+// the cache. With a partial load, the secrets are returned along with the deadline error.
+func (c *awsSecretClient) load(ctx context.Context) (map[string]string, map[string]string, error) {
+	ids := c.secretIds()
+
+	var (
+		loaded map[string]map[string]string
+		err    error
+	)
+
+	switch {
+	case c.partialLoad:
+		loaded, err = c.fetchConcurrently(ctx, ids)
+	case len(ids) > 1 && !c.opts.sharedCache:
+		loaded, err = c.fetchBatch(ctx, ids)
+	default:
+		loaded, err = c.fetchSequentially(ctx, ids)
+	}
+
+	if loaded == nil {
+		return nil, nil, err
+	}
+
+	secrets, sources, mergeErr := c.merge(ids, loaded)
+	if mergeErr != nil {
+		return nil, nil, mergeErr
+	}
+
+	return secrets, sources, err
+}
+
+// merge combines the fetched secrets by precedence, then filters and validates them.
+func (c *awsSecretClient) merge(ids []string, loaded map[string]map[string]string) (map[string]string, map[string]string, error) {
+	if err := c.checkLevels(loaded); err != nil {
+		c.logger.Error("error to get secret", zap.Error(err))
+		return nil, nil, err
+	}
+
+	secrets, sources := mergeSecrets(ids, loaded)
+
+	if c.opts.requireNonEmpty && len(secrets) == 0 {
+		err := fmt.Errorf("%w: %s", ErrEmptySecret, strings.Join(ids, ", "))
+		c.logger.Error("loaded secret is empty", zap.Error(err))
+		return nil, nil, err
+	}
+
+	if len(c.onlyKeys) > 0 {
+		secrets = retainKeys(secrets, c.onlyKeys)
+	}
+
+	if err := sm.ValidateSecrets(secrets, c.valueRules); err != nil {
+		c.logger.Error("secret values failed validation", zap.Error(err))
+		return nil, nil, err
+	}
+
+	return secrets, sources, nil
+}
+
+// secretIds returns the configured secret identifiers by decreasing precedence.
+func (c *awsSecretClient) secretIds() []string {
+	ids := []string{c.appSecretId}
+	if c.secondaryId != "" {
+		ids = append(ids, c.secondaryId)
+	}
+	if c.baseId != "" {
+		ids = append(ids, c.baseId)
+	}
+
+	return ids
+}
+
+// fetchLevel fetches the secret stored under secretId. With the base overlay enabled, a
+// missing environment or base secret yields a nil map instead of an error, since either
+// level may be absent as long as the other one exists.
+func (c *awsSecretClient) fetchLevel(ctx context.Context, secretId string) (map[string]string, error) {
+	secrets, err := c.fetchSecrets(ctx, secretId)
+	return c.tolerateLevel(secretId, secrets, err)
+}
+
+// tolerateLevel turns the not-found error of a missing environment or base secret into
+// a nil map when the base overlay is enabled, and returns the other results unchanged.
+func (c *awsSecretClient) tolerateLevel(secretId string, secrets map[string]string, err error) (map[string]string, error) {
+	if c.baseId == "" || (secretId != c.appSecretId && secretId != c.baseId) {
+		return secrets, err
+	}
+
+	if errors.Is(err, sm.ErrSecretNotFound) {
+		c.logger.Info("secret level not found, relying on the other level", zap.String("secretId", secretId))
+		return nil, nil
+	}
+
+	return secrets, err
+}
+
+// checkLevels reports sm.ErrSecretNotFound when the base overlay is enabled and neither
+// the environment nor the base secret exists.
+func (c *awsSecretClient) checkLevels(loaded map[string]map[string]string) error {
+	if c.baseId == "" {
+		return nil
+	}
+
+	env, envFetched := loaded[c.appSecretId]
+	base, baseFetched := loaded[c.baseId]
+	if envFetched && baseFetched && env == nil && base == nil {
+		return fmt.Errorf("%w: neither %s nor %s exists", sm.ErrSecretNotFound, c.appSecretId, c.baseId)
+	}
+
+	return nil
+}
+
+// fetchSequentially fetches every secret in turn, failing on the first error.
+func (c *awsSecretClient) fetchSequentially(ctx context.Context, ids []string) (map[string]map[string]string, error) {
+	loaded := make(map[string]map[string]string, len(ids))
+	for _, id := range ids {
+		secrets, err := c.fetchLevel(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		loaded[id] = secrets
+	}
+
+	return loaded, nil
+}
+
+// fetchConcurrently fetches every secret at once. When the context deadline expires
+// first, the secrets fetched so far are returned along with an error wrapping
+// context.DeadlineExceeded that lists the identifiers which didn't complete.
+func (c *awsSecretClient) fetchConcurrently(ctx context.Context, ids []string) (map[string]map[string]string, error) {
+	type result struct {
+		id      string
+		secrets map[string]string
+		err     error
+	}
+
+	// Buffered so fetches finishing after the deadline never block
+	results := make(chan result, len(ids))
+	for _, id := range ids {
+		go func(id string) {
+			secrets, err := c.fetchLevel(ctx, id)
+			results <- result{id: id, secrets: secrets, err: err}
+		}(id)
+	}
+
+	loaded := make(map[string]map[string]string, len(ids))
+	for range ids {
+		select {
+		case res := <-results:
+			if res.err != nil && !errors.Is(res.err, context.DeadlineExceeded) {
+				return nil, res.err
+			}
+
+			if res.err == nil {
+				loaded[res.id] = res.secrets
+			}
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if len(loaded) == len(ids) {
+		return loaded, nil
+	}
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, ctx.Err()
+	}
+
+	var pending []string
+	for _, id := range ids {
+		if _, ok := loaded[id]; !ok {
+			pending = append(pending, id)
+		}
+	}
+
+	c.logger.Warn("secrets load deadline exceeded", zap.Strings("pending", pending))
+	err := fmt.Errorf("%w: secrets not loaded: %s", context.DeadlineExceeded, strings.Join(pending, ", "))
+
+	// Keep the previous cache rather than replacing it with nothing
+	if len(loaded) == 0 {
+		return nil, err
+	}
+
+	return loaded, err
+}
+
+// mergeSecrets combines the loaded secrets by precedence: a key is taken from the first
+// secret of ids defining it. It also returns the secret identifier each key came from.
+func mergeSecrets(ids []string, loaded map[string]map[string]string) (map[string]string, map[string]string) {
+	secrets := make(map[string]string)
+	sources := make(map[string]string)
+
+	for _, id := range ids {
+		for key, value := range loaded[id] {
+			if _, ok := secrets[key]; !ok {
+				secrets[key] = value
+				sources[key] = id
+			}
+		}
+	}
+
+	return secrets, sources
+}
+
+// fetchSecrets retrieves and parses the secret stored under secretId, decrypting the
+// client-side encrypted values and scoping the keys to the configured namespace.
+func (c *awsSecretClient) fetchSecrets(ctx context.Context, secretId string) (map[string]string, error) {
+	fetch := c.fetchRaw
+	if c.opts.sharedCache {
+		fetch = c.fetchShared
+	}
+
+	secrets, err := fetch(ctx, secretId)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.prepare(ctx, secretId, secrets)
+}
+
+// prepare verifies the checksum of secrets as stored in AWS, decrypts them and scopes
+// them to the configured namespace.
+func (c *awsSecretClient) prepare(ctx context.Context, secretId string, secrets map[string]string) (map[string]string, error) {
+	if c.opts.verifyChecksum {
+		if err := verifyChecksum(secrets); err != nil {
+			c.logger.Error("secret checksum verification failed", zap.String("secretId", secretId), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if c.cipher != nil {
+		if err := c.decryptValues(ctx, secrets); err != nil {
+			c.logger.Error("error to decrypt secret values", zap.String("secretId", secretId), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if c.namespace != "" {
+		secrets = scopeNamespace(secrets, c.namespace, c.separator)
+	}
+
+	return secrets, nil
+}
+
+// fetchRaw retrieves and parses the secret stored under secretId as stored in AWS.
+func (c *awsSecretClient) fetchRaw(ctx context.Context, secretId string) (map[string]string, error) {
+	secrets, _, err := c.fetchRawVersion(ctx, secretId)
+	return secrets, err
+}
+
+// fetchRawVersion is fetchRaw also returning the identifier of the version read.
+func (c *awsSecretClient) fetchRawVersion(ctx context.Context, secretId string) (map[string]string, string, error) {
 	// Call AWS Secrets Manager API to get the secret value
-	res, err := c.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
-		SecretId: &c.appSecretId,
-	})
+	res, err := c.getSecretValue(ctx, secretId)
+	if err != nil {
+		c.logger.Error("error to get secret", zap.String("secretId", secretId), zap.Error(err))
+		return nil, "", mapError(err)
+	}
 
+	secrets, err := c.decode(secretId, res)
 	if err != nil {
-		c.logger.Error("error to get secret", zap.Error(err))
-		return err
+		return nil, "", err
+	}
+
+	return secrets, aws.ToString(res.VersionId), nil
+}
+
+// decode extracts and parses the payload of a secret value, enforcing the size limit.
+func (c *awsSecretClient) decode(secretId string, res *secretsmanager.GetSecretValueOutput) (map[string]string, error) {
+	if res.CreatedDate != nil {
+		c.datesMu.Lock()
+		c.versionDates[secretId] = *res.CreatedDate
+		c.datesMu.Unlock()
+	}
+
+	payload, err := secretPayload(res, c.base64Bin)
+	if err != nil {
+		c.logger.Error("error get secret from aws", zap.String("secretId", secretId), zap.Error(err))
+		return nil, err
+	}
+
+	if len(payload) > c.maxPayload {
+		c.logger.Error("secret payload is too large", zap.Int("size", len(payload)), zap.Int("max", c.maxPayload))
+		return nil, fmt.Errorf("%w: %d bytes, max %d", ErrPayloadTooLarge, len(payload), c.maxPayload)
+	}
+
+	// Parse the secret JSON data into a fresh map so a failure keeps the previous cache
+	secrets, err := parseSecrets(payload, c.plainKey)
+	if err != nil {
+		c.logger.Error("error get secret from aws", zap.String("secretId", secretId), zap.Error(err))
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// getSecretValue calls GetSecretValue, retrying once with freshly resolved credentials
+// when the temporary credentials in use have expired.
+func (c *awsSecretClient) getSecretValue(ctx context.Context, secretId string) (*secretsmanager.GetSecretValueOutput, error) {
+	input := &secretsmanager.GetSecretValueInput{SecretId: &secretId}
+
+	res, err := c.api().GetSecretValue(ctx, input)
+	if err == nil || !isExpiredToken(err) {
+		return res, err
+	}
+
+	if c.opts.retryBudget != nil && !c.opts.retryBudget.Allow() {
+		return nil, fmt.Errorf("%w: %w", sm.ErrRetryBudgetExhausted, err)
+	}
+
+	c.logger.Warn("aws credentials expired, refreshing credentials", zap.Error(err))
+
+	if refreshErr := c.refreshCredentials(ctx); refreshErr != nil {
+		c.logger.Error("error to refresh aws credentials", zap.Error(refreshErr))
+		// Keep the original error matchable alongside the refresh failure
+		return nil, fmt.Errorf("%w: refresh credentials: %w", err, refreshErr)
 	}
 
-	// Reset the cache before loading new values
-	c.secrets = map[string]string{}
+	return c.api().GetSecretValue(ctx, input)
+}
 
-	// Parse the secret JSON data into our cache map
-	err = json.Unmarshal(res.SecretBinary, &c.secrets)
+// refreshCredentials re-runs the credential chain, re-assuming any configured role,
+// and replaces the Secrets Manager client with one using the new credentials.
+func (c *awsSecretClient) refreshCredentials(ctx context.Context) error {
+	awsCfg, err := loadAwsConfig(ctx, c.opts)
 	if err != nil {
-		c.logger.Error("error get secret from aws", zap.Error(err))
 		return err
 	}
 
+	c.mu.Lock()
+	c.client = newSecretsManagerClient(awsCfg, c.opts)
+	c.mu.Unlock()
+
 	return nil
 }
 
+// api returns the current Secrets Manager client.
+func (c *awsSecretClient) api() secretsManagerAPI {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.client
+}
+
+// mapError wraps AWS ResourceNotFoundException errors so they also match
+// sm.ErrSecretNotFound, and DecryptionFailure errors so they also match
+// ErrDecryptionFailure, keeping the typed AWS error reachable through errors.As.
+func mapError(err error) error {
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return fmt.Errorf("%w: %w", sm.ErrSecretNotFound, err)
+	}
+
+	var decryption *types.DecryptionFailure
+	if errors.As(err, &decryption) {
+		return fmt.Errorf("%w: %w", ErrDecryptionFailure, err)
+	}
+
+	return err
+}
+
+// isExpiredToken reports whether err was caused by expired temporary credentials.
+func isExpiredToken(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.ErrorCode() {
+	case "ExpiredToken", "ExpiredTokenException":
+		return true
+	default:
+		return false
+	}
+}
+
+// Reload refreshes the in-memory cache by loading the secrets again.
+// It implements the secretsmanager.Reloadable interface.
+func (c *awsSecretClient) Reload(ctx context.Context) error {
+	return c.LoadSecrets(ctx)
+}
+
+// ReloadIfStale reloads the secrets only when the cache is older than maxAge, or was never
+// loaded, using the configured clock. It implements the secretsmanager.StaleReloader interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//   - maxAge: The maximum age of the cache before a reload is needed
+//
+// Returns:
+//   - Whether a reload happened
+//   - An error if the reload fails
+func (c *awsSecretClient) ReloadIfStale(ctx context.Context, maxAge time.Duration) (bool, error) {
+	c.mu.RLock()
+	loadedAt := c.loadedAt
+	c.mu.RUnlock()
+
+	if !loadedAt.IsZero() && c.clock.Now().Sub(loadedAt) <= maxAge+c.opts.clockSkew {
+		return false, nil
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // GetSecret retrieves a specific secret value by its key from the in-memory cache.
 //
 // This method performs a lookup in the in-memory cache that was populated by LoadSecrets.
 // It's designed to be fast and efficient, avoiding repeated calls to AWS Secrets Manager
 // for each secret retrieval. The method will return an error if the requested key does
-// not exist in the cache.
-//
-// Note that the context parameter is not used in this implementation since the lookup
-// is performed on the local cache, but it's included to satisfy the SecretClient interface.
+// not exist in the cache. When the key isn't cached but is a registered alias, the value
+// of the key it points to is returned. A key marked stale by Invalidate, or any key when
+// WithNoCache is set, triggers a reload of the secret before being looked up. Keys
+// rejected by WithAllowList or WithDenyList, including through an alias, are never
+// returned nor reloaded.
 //
 // Parameters:
-//   - ctx: Context (not used in this implementation)
+//   - ctx: Context used only when the secret must be reloaded
 //   - key: The secret key to look up
 //
 // Returns:
 //   - The secret value as a string if found
-//   - An error if the key doesn't exist in the cache
-func (c *awsSecretClient) GetSecret(_ context.Context, key string) (string, error) {
-	value, ok := c.secrets[key]
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+//   - sm.ErrSecretsNotLoaded if the secrets weren't loaded yet or the cache was reset
+//   - sm.ErrAccessDenied if the key isn't allowed by the access lists
+//   - An error if reloading the secret fails
+func (c *awsSecretClient) GetSecret(ctx context.Context, key string) (string, error) {
+	if err := c.checkAccess(key); err != nil {
+		return "", err
+	}
+
+	c.mu.RLock()
+	stale := c.stale[key]
+	c.mu.RUnlock()
+
+	if stale || c.noCache {
+		if err := c.LoadSecrets(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.loadedAt.IsZero() {
+		return "", sm.ErrSecretsNotLoaded
+	}
+
+	if value, ok, err := c.cache.Get(ctx, key); err != nil || ok {
+		return value, err
+	}
+
+	if target, ok := c.aliases[key]; ok {
+		if err := c.checkAccess(target); err != nil {
+			return "", err
+		}
+
+		if value, ok, err := c.cache.Get(ctx, target); err != nil || ok {
+			return value, err
+		}
+	}
+
+	return "", sm.ErrSecretNotFound
+}
+
+// checkAccess returns sm.ErrAccessDenied when key is denied, or not allowed while an
+// allow list is configured.
+func (c *awsSecretClient) checkAccess(key string) error {
+	if c.opts.denyList[key] || (c.opts.allowList != nil && !c.opts.allowList[key]) {
+		return fmt.Errorf("%w: %q", sm.ErrAccessDenied, key)
+	}
+
+	return nil
+}
+
+// Snapshot returns a copy of the in-memory cache, leaving out the keys GetSecret denies
+// through WithAllowList and WithDenyList.
+//
+// The returned map is a defensive copy, so mutating it never affects the client.
+// It contains plaintext secret values and should be handled carefully.
+//
+// Parameters:
+//   - ctx: Context forwarded to the cache store
+//
+// Returns:
+//   - A copy of every accessible cached key-value pair
+//   - An error if the cache store cannot be read
+func (c *awsSecretClient) Snapshot(ctx context.Context) (map[string]string, error) {
+	secrets, err := sm.StoreSnapshot(ctx, c.cache)
+	if err != nil {
+		return nil, err
+	}
+
+	maps.DeleteFunc(secrets, func(key, _ string) bool {
+		return c.checkAccess(key) != nil
+	})
+
+	return secrets, nil
+}
+
+// ResetCache empties the cache without reloading and marks the client as not loaded, so
+// GetSecret returns sm.ErrSecretsNotLoaded until LoadSecrets succeeds again. It's meant
+// for tests and administrative clears. It implements the secretsmanager.CacheResetter interface.
+func (c *awsSecretClient) ResetCache() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.cache.SetAll(context.Background(), map[string]string{}); err != nil {
+		c.logger.Warn("error to clear the cache store", zap.Error(err))
+	}
+
+	c.sources = make(map[string]string)
+	c.stale = nil
+	c.loadedAt = time.Time{}
+}
+
+// Invalidate marks key as stale. Since the whole secret is fetched at once, the next
+// GetSecret for that key reloads the secret before serving it; other keys keep being
+// served from the cache until then.
+func (c *awsSecretClient) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stale == nil {
+		c.stale = make(map[string]bool)
+	}
+
+	c.stale[key] = true
+}
+
+// VerifyIntegrity fetches the secrets again and compares their hash with the hash of the
+// cache, without updating it. It implements the secretsmanager.IntegrityVerifier interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - Whether the cache matches the secrets stored in AWS
+//   - An error if the secrets cannot be fetched
+func (c *awsSecretClient) VerifyIntegrity(ctx context.Context) (bool, error) {
+	fresh, _, err := c.load(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	cached, err := sm.StoreSnapshot(ctx, c.cache)
+	if err != nil {
+		return false, err
+	}
+
+	return sm.HashSecrets(cached) == sm.HashSecrets(fresh), nil
+}
+
+// SourceOf returns the secret identifier the given key was loaded from, resolving
+// aliases like GetSecret does. It only exposes identifiers, never secret values.
+//
+// Parameters:
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The identifier of the secret that supplied the key
+//   - false if the key isn't cached
+func (c *awsSecretClient) SourceOf(key string) (string, bool) {
+	return c.sourceOf(key)
+}
+
+// LastModified returns the creation date of the version of the secret that supplied key,
+// since Secrets Manager stores all keys of a secret in one blob and doesn't track them
+// individually. It implements the secretsmanager.LastModifiedReporter interface.
+func (c *awsSecretClient) LastModified(key string) (time.Time, bool) {
+	secretId, ok := c.sourceOf(key)
 	if !ok {
-		return "", errors.New("secret was not found")
+		return time.Time{}, false
+	}
+
+	c.datesMu.Lock()
+	defer c.datesMu.Unlock()
+
+	date, ok := c.versionDates[secretId]
+	return date, ok
+}
+
+// sourceOf returns the identifier of the secret that supplied key, resolving aliases.
+func (c *awsSecretClient) sourceOf(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.sources[key]; !ok {
+		target, isAlias := c.aliases[key]
+		if _, ok := c.sources[target]; !isAlias || !ok {
+			return "", false
+		}
+
+		key = target
+	}
+
+	return c.sources[key], true
+}
+
+// ListSecrets returns the sorted keys held in the cache, without namespace prefix,
+// leaving out the keys GetSecret denies. It implements the secretsmanager.Lister
+// interface and never returns values.
+func (c *awsSecretClient) ListSecrets(ctx context.Context) ([]string, error) {
+	keys, err := c.cache.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys = slices.DeleteFunc(keys, func(key string) bool {
+		return c.checkAccess(key) != nil
+	})
+
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// scopeNamespace returns the keys prefixed with the namespace and separator, with the
+// prefix removed.
+func scopeNamespace(secrets map[string]string, namespace, separator string) map[string]string {
+	prefix := namespace + separator
+
+	scoped := make(map[string]string)
+	for key, value := range secrets {
+		if name, ok := strings.CutPrefix(key, prefix); ok && name != "" {
+			scoped[name] = value
+		}
+	}
+
+	return scoped
+}
+
+// retainKeys returns a map holding only the given keys that are present in secrets.
+func retainKeys(secrets map[string]string, keys []string) map[string]string {
+	retained := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := secrets[key]; ok {
+			retained[key] = value
+		}
 	}
 
-	return value, nil
+	return retained
 }
@@ -0,0 +1,1355 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/goxkit/configs"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// mockSecretsManager serves secrets from memory. Calls to the operations it doesn't
+// implement panic through the nil embedded interface.
+type mockSecretsManager struct {
+	secretsManagerAPI
+
+	mu          sync.Mutex
+	secrets     map[string]string                               // Secret string by secret identifier
+	created     map[string]time.Time                            // Version creation date by secret identifier
+	batchErrors []types.APIErrorType                            // Per-secret errors reported by BatchGetSecretValue
+	batchErr    error                                           // Error failing BatchGetSecretValue as a whole
+	failures    map[string]error                                // Errors returned by GetSecretValue by secret identifier
+	described   map[string]*secretsmanager.DescribeSecretOutput // DescribeSecret responses by secret identifier
+	versions    map[string]string                               // Current version identifier by secret identifier, v1 when unset
+	pending     map[string]string                               // Secret string of the versions not yet promoted, by version identifier
+	calls       map[string]int                                  // Number of calls by operation
+}
+
+func newMockSecretsManager(secrets map[string]string) *mockSecretsManager {
+	return &mockSecretsManager{
+		secrets:  secrets,
+		created:  map[string]time.Time{},
+		versions: map[string]string{},
+		pending:  map[string]string{},
+		calls:    map[string]int{},
+	}
+}
+
+func (m *mockSecretsManager) GetSecretValue(
+	_ context.Context,
+	params *secretsmanager.GetSecretValueInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.GetSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["GetSecretValue"]++
+
+	id := aws.ToString(params.SecretId)
+	if err, ok := m.failures[id]; ok {
+		return nil, err
+	}
+
+	value, ok := m.secrets[id]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("secret not found")}
+	}
+
+	return &secretsmanager.GetSecretValueOutput{
+		Name:         aws.String(id),
+		SecretString: aws.String(value),
+		CreatedDate:  m.createdDate(id),
+		VersionId:    aws.String(m.version(id)),
+	}, nil
+}
+
+func (m *mockSecretsManager) BatchGetSecretValue(
+	_ context.Context,
+	params *secretsmanager.BatchGetSecretValueInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.BatchGetSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["BatchGetSecretValue"]++
+
+	if m.batchErr != nil {
+		return nil, m.batchErr
+	}
+
+	res := &secretsmanager.BatchGetSecretValueOutput{Errors: m.batchErrors}
+	for _, id := range params.SecretIdList {
+		if value, ok := m.secrets[id]; ok {
+			res.SecretValues = append(res.SecretValues, types.SecretValueEntry{
+				Name:         aws.String(id),
+				SecretString: aws.String(value),
+				CreatedDate:  m.createdDate(id),
+				VersionId:    aws.String(m.version(id)),
+			})
+		}
+	}
+
+	return res, nil
+}
+
+func (m *mockSecretsManager) DescribeSecret(
+	_ context.Context,
+	params *secretsmanager.DescribeSecretInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.DescribeSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["DescribeSecret"]++
+
+	res, ok := m.described[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("secret not found")}
+	}
+
+	return res, nil
+}
+
+func (m *mockSecretsManager) PutSecretValue(
+	_ context.Context,
+	params *secretsmanager.PutSecretValueInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.PutSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["PutSecretValue"]++
+
+	id := aws.ToString(params.SecretId)
+	versionId := fmt.Sprintf("v%d", m.calls["PutSecretValue"]+1)
+
+	// A version staged under another label than AWSCURRENT waits for its promotion
+	if len(params.VersionStages) > 0 && !slices.Contains(params.VersionStages, currentStage) {
+		m.pending[versionId] = aws.ToString(params.SecretString)
+	} else {
+		m.secrets[id] = aws.ToString(params.SecretString)
+		m.versions[id] = versionId
+	}
+
+	return &secretsmanager.PutSecretValueOutput{Name: params.SecretId, VersionId: aws.String(versionId)}, nil
+}
+
+func (m *mockSecretsManager) UpdateSecretVersionStage(
+	_ context.Context,
+	params *secretsmanager.UpdateSecretVersionStageInput,
+	_ ...func(*secretsmanager.Options),
+) (*secretsmanager.UpdateSecretVersionStageOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["UpdateSecretVersionStage"]++
+
+	id := aws.ToString(params.SecretId)
+	if aws.ToString(params.RemoveFromVersionId) != m.version(id) {
+		return nil, &types.InvalidParameterException{Message: aws.String("the staging label isn't attached to the version")}
+	}
+
+	versionId := aws.ToString(params.MoveToVersionId)
+	m.secrets[id] = m.pending[versionId]
+	m.versions[id] = versionId
+	delete(m.pending, versionId)
+
+	return &secretsmanager.UpdateSecretVersionStageOutput{Name: params.SecretId}, nil
+}
+
+// version returns the current version identifier of id. The caller must hold mu.
+func (m *mockSecretsManager) version(id string) string {
+	if versionId, ok := m.versions[id]; ok {
+		return versionId
+	}
+
+	return "v1"
+}
+
+// Secret returns the secret string currently stored under id.
+func (m *mockSecretsManager) Secret(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.secrets[id]
+}
+
+// createdDate returns the creation date of the version of id, nil when unset. The caller
+// must hold mu.
+func (m *mockSecretsManager) createdDate(id string) *time.Time {
+	if created, ok := m.created[id]; ok {
+		return &created
+	}
+
+	return nil
+}
+
+// Calls returns the number of calls made to operation.
+func (m *mockSecretsManager) Calls(operation string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls[operation]
+}
+
+// manualClock is a sm.Clock only moving when advanced.
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// newTestClient creates a client reading appSecretId through api, configured like
+// NewAwsSecretClient but without resolving AWS credentials.
+func newTestClient(api secretsManagerAPI, appSecretId string, opts ...Option) *awsSecretClient {
+	o := &options{
+		maxPayloadSize: DefaultMaxPayloadSize,
+		clock:          sm.SystemClock,
+		separator:      DefaultKeySeparator,
+		sizeWarning:    DefaultSizeWarningThreshold,
+		locker:         sm.NopLocker,
+		cacheStore:     sm.NewMemoryStore(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	plainKey := o.plainKey
+	if plainKey == "" {
+		plainKey = "value"
+	}
+
+	return &awsSecretClient{
+		logger:       zap.NewNop(),
+		opts:         o,
+		client:       api,
+		appSecretId:  appSecretId,
+		namespace:    strings.TrimSuffix(o.namespace, o.separator),
+		secondaryId:  o.secondarySecretId,
+		separator:    o.separator,
+		partialLoad:  o.partialLoad,
+		plainKey:     plainKey,
+		noCache:      o.noCache,
+		valueRules:   o.valueRules,
+		base64Bin:    o.base64Binary,
+		clock:        o.clock,
+		onlyKeys:     o.onlyKeys,
+		maxPayload:   o.maxPayloadSize,
+		aliases:      o.aliases,
+		cache:        o.cacheStore,
+		sources:      make(map[string]string),
+		sharedSeen:   make(map[string]uint64),
+		versionDates: make(map[string]time.Time),
+	}
+}
+
+func TestSnapshotOmitsDeniedKeys(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app": `{"public":"1","internal":"2","admin":"3"}`,
+	})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]string
+	}{
+		{
+			name: "deny list",
+			opts: []Option{WithDenyList("admin")},
+			want: map[string]string{"public": "1", "internal": "2"},
+		},
+		{
+			name: "allow list",
+			opts: []Option{WithAllowList("public", "admin"), WithDenyList("admin")},
+			want: map[string]string{"public": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(api, "dev/app", tt.opts...)
+			if err := c.LoadSecrets(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, err := c.Snapshot(context.Background())
+			if err != nil {
+				t.Fatalf("Snapshot() error = %v", err)
+			}
+			if !reflect.DeepEqual(snapshot, tt.want) {
+				t.Errorf("Snapshot() = %v, want %v", snapshot, tt.want)
+			}
+
+			keys, err := c.ListSecrets(context.Background())
+			if err != nil {
+				t.Fatalf("ListSecrets() error = %v", err)
+			}
+			if want := slices.Sorted(maps.Keys(tt.want)); !reflect.DeepEqual(keys, want) {
+				t.Errorf("ListSecrets() = %v, want %v", keys, want)
+			}
+		})
+	}
+}
+
+func TestSnapshotIsDefensiveCopy(t *testing.T) {
+	c := newTestClient(newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret"}`}), "dev/app")
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := c.Snapshot(context.Background())
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	snapshot["db_password"] = "tampered"
+	snapshot["injected"] = "x"
+	delete(snapshot, "db_password")
+
+	if value, err := c.GetSecret(context.Background(), "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v after mutating a snapshot, want s3cret", value, err)
+	}
+	if _, err := c.GetSecret(context.Background(), "injected"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v for a key added to a snapshot, want ErrSecretNotFound", err)
+	}
+}
+
+func TestNamespaceSeparators(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app": `{"tenant.db":"dotted","tenant/db":"slashed","other.db":"x","tenant":"bare"}`,
+	})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "default", opts: []Option{WithNamespace("tenant")}, want: "dotted"},
+		{name: "dot", opts: []Option{WithNamespace("tenant."), WithKeySeparator(".")}, want: "dotted"},
+		{name: "slash", opts: []Option{WithNamespace("tenant"), WithKeySeparator("/")}, want: "slashed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(api, "dev/app", tt.opts...)
+			if err := c.LoadSecrets(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if value, err := c.GetSecret(context.Background(), "db"); err != nil || value != tt.want {
+				t.Errorf("GetSecret() = %q, %v, want %q", value, err, tt.want)
+			}
+
+			keys, _ := c.ListSecrets(context.Background())
+			if !reflect.DeepEqual(keys, []string{"db"}) {
+				t.Errorf("ListSecrets() = %v, want only the namespace keys", keys)
+			}
+		})
+	}
+}
+
+func TestNewAwsSecretClientNilLogger(t *testing.T) {
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: "app"}}
+
+	c, err := NewAwsSecretClient(cfgs)
+	if err != nil {
+		t.Fatalf("NewAwsSecretClient() error = %v", err)
+	}
+
+	// A canceled load fails before reaching AWS, going through the error logging
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.LoadSecrets(ctx); err == nil {
+		t.Error("LoadSecrets() succeeded with a canceled context")
+	}
+}
+
+// isolateAWSConfig points the default AWS configuration chain at empty files and clears
+// the credential environment variables, so tests never pick up the host's setup.
+func isolateAWSConfig(t *testing.T) {
+	t.Helper()
+
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE",
+		"AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ENDPOINT_URL",
+	} {
+		t.Setenv(name, "")
+	}
+
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+}
+
+func TestLoadAwsConfigWebIdentity(t *testing.T) {
+	isolateAWSConfig(t)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("oidc-t0ken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		form = r.PostForm
+
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = io.WriteString(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDWEBIDENTITY</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`)
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL_STS", srv.URL)
+
+	const roleARN = "arn:aws:iam::111111111111:role/ci"
+	o := &options{}
+	WithWebIdentity(roleARN, tokenFile)(o)
+
+	awsCfg, err := loadAwsConfig(context.Background(), o)
+	if err != nil {
+		t.Fatalf("loadAwsConfig() error = %v", err)
+	}
+
+	creds, err := awsCfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if creds.AccessKeyID != "AKIDWEBIDENTITY" {
+		t.Errorf("AccessKeyID = %q, want the assumed role credentials", creds.AccessKeyID)
+	}
+
+	if got := form.Get("Action"); got != "AssumeRoleWithWebIdentity" {
+		t.Errorf("STS Action = %q, want AssumeRoleWithWebIdentity", got)
+	}
+	if got := form.Get("RoleArn"); got != roleARN {
+		t.Errorf("STS RoleArn = %q, want %q", got, roleARN)
+	}
+	if got := form.Get("WebIdentityToken"); got != "oidc-t0ken" {
+		t.Errorf("STS WebIdentityToken = %q, want the token file contents", got)
+	}
+}
+
+func TestOnlyKeys(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret","api_token":"t0ken","debug":"1"}`})
+
+	c := newTestClient(api, "dev/app", OnlyKeys("db_password", "api_token", "missing"))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{"db_password": "s3cret", "api_token": "t0ken"} {
+		if value, err := c.GetSecret(context.Background(), key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+	for _, key := range []string{"debug", "missing"} {
+		if _, err := c.GetSecret(context.Background(), key); !errors.Is(err, sm.ErrSecretNotFound) {
+			t.Errorf("GetSecret(%q) error = %v, want ErrSecretNotFound", key, err)
+		}
+	}
+
+	if keys, _ := c.ListSecrets(context.Background()); !reflect.DeepEqual(keys, []string{"api_token", "db_password"}) {
+		t.Errorf("ListSecrets() = %v, want only the retained keys", keys)
+	}
+}
+
+func TestMaxPayloadSize(t *testing.T) {
+	payload := `{"db_password":"s3cret"}`
+
+	tests := []struct {
+		name    string
+		max     int
+		wantErr error
+	}{
+		{name: "at the limit", max: len(payload)},
+		{name: "over the limit", max: len(payload) - 1, wantErr: ErrPayloadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(newMockSecretsManager(map[string]string{"dev/app": payload}), "dev/app", WithMaxPayloadSize(tt.max))
+
+			err := c.LoadSecrets(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error %q echoes the payload", err)
+			}
+		})
+	}
+}
+
+func TestAliases(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"password":"s3cret","pwd":"direct"}`})
+
+	c := newTestClient(api, "dev/app", WithAliases(map[string]string{
+		"pwd":      "password",
+		"db_pass":  "password",
+		"dangling": "missing",
+	}))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key     string
+		want    string
+		wantErr error
+	}{
+		{key: "pwd", want: "direct"},
+		{key: "db_pass", want: "s3cret"},
+		{key: "dangling", wantErr: sm.ErrSecretNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, err := c.GetSecret(context.Background(), tt.key)
+			if !errors.Is(err, tt.wantErr) || value != tt.want {
+				t.Errorf("GetSecret(%q) = %q, %v, want %q, %v", tt.key, value, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecondarySecretPrecedence(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app":    `{"both":"primary","primary_only":"p"}`,
+		"dev/legacy": `{"both":"secondary","secondary_only":"s"}`,
+	})
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/legacy"))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]string{"both": "primary", "primary_only": "p", "secondary_only": "s"} {
+		if value, err := c.GetSecret(context.Background(), key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+
+	missing := newTestClient(api, "dev/app", WithSecondarySecret("dev/missing"))
+	if err := missing.LoadSecrets(context.Background()); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("LoadSecrets() error = %v with a missing secondary secret, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSourceOf(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app":    `{"both":"primary","primary_only":"p"}`,
+		"dev/legacy": `{"both":"secondary","secondary_only":"s"}`,
+	})
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/legacy"), WithAliases(map[string]string{"legacy": "secondary_only"}))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"both":           "dev/app",
+		"primary_only":   "dev/app",
+		"secondary_only": "dev/legacy",
+		"legacy":         "dev/legacy",
+	}
+	for key, want := range tests {
+		if source, ok := c.SourceOf(key); !ok || source != want {
+			t.Errorf("SourceOf(%q) = %q, %v, want %q", key, source, ok, want)
+		}
+	}
+
+	if source, ok := c.SourceOf("missing"); ok {
+		t.Errorf("SourceOf() = %q for a missing key, want false", source)
+	}
+}
+
+func TestLoadSecretsRefreshesExpiredCredentials(t *testing.T) {
+	isolateAWSConfig(t)
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("X-Amz-Target = %q, want secretsmanager.GetSecretValue", target)
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"__type":"ExpiredTokenException","message":"The security token included in the request is expired"}`)
+			return
+		}
+
+		_, _ = io.WriteString(w, `{"Name":"dev/app","SecretString":"{\"db_password\":\"s3cret\"}","VersionId":"v2"}`)
+	}))
+	defer srv.Close()
+
+	var retrievals atomic.Int32
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		retrievals.Add(1)
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}, nil
+	})
+
+	c := newTestClient(nil, "dev/app", WithEndpoint(srv.URL), WithCredentialsProvider(provider))
+
+	awsCfg, err := loadAwsConfig(context.Background(), c.opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.client = newSecretsManagerClient(awsCfg, c.opts)
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want s3cret", value)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("GetSecretValue requests = %d, want one retry after the expired token", got)
+	}
+	if got := retrievals.Load(); got != 2 {
+		t.Errorf("credential retrievals = %d, want the credentials resolved again", got)
+	}
+}
+
+// newSecretsManagerServer serves GetSecretValue over the AWS JSON protocol from secrets,
+// answering ResourceNotFoundException for unknown identifiers. It records the requests.
+func newSecretsManagerServer(t *testing.T, secrets map[string]string) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+
+		var input struct{ SecretId string }
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+
+		value, ok := secrets[input.SecretId]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = io.WriteString(w, `{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"Name": input.SecretId, "SecretString": value, "VersionId": "v1"})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestLoadSecretsNotFound(t *testing.T) {
+	c := newTestClient(newMockSecretsManager(nil), "dev/app")
+
+	err := c.LoadSecrets(context.Background())
+	if !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("LoadSecrets() error = %v, want ErrSecretNotFound", err)
+	}
+
+	var notFound *types.ResourceNotFoundException
+	if !errors.As(err, &notFound) {
+		t.Errorf("LoadSecrets() error = %v, want the AWS error kept", err)
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{
+		"dev/app": `{"tenantA.db":"a","tenantA.api":"a-api","tenantB.db":"b","shared":"x"}`,
+	})
+
+	c := newTestClient(api, "dev/app", WithNamespace("tenantA"))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := c.GetSecret(context.Background(), "db"); err != nil || value != "a" {
+		t.Errorf("GetSecret() = %q, %v, want the tenant value", value, err)
+	}
+	for _, key := range []string{"tenantA.db", "tenantB.db", "shared"} {
+		if _, err := c.GetSecret(context.Background(), key); !errors.Is(err, sm.ErrSecretNotFound) {
+			t.Errorf("GetSecret(%q) error = %v, want ErrSecretNotFound outside the namespace", key, err)
+		}
+	}
+
+	if keys, _ := c.ListSecrets(context.Background()); !reflect.DeepEqual(keys, []string{"api", "db"}) {
+		t.Errorf("ListSecrets() = %v, want the namespace keys without prefix", keys)
+	}
+}
+
+// slowSecretsManager is a mockSecretsManager never answering for the blocked secrets
+// before the context is done.
+type slowSecretsManager struct {
+	*mockSecretsManager
+
+	blocked map[string]bool
+}
+
+func (s *slowSecretsManager) GetSecretValue(
+	ctx context.Context,
+	params *secretsmanager.GetSecretValueInput,
+	optFns ...func(*secretsmanager.Options),
+) (*secretsmanager.GetSecretValueOutput, error) {
+	if s.blocked[aws.ToString(params.SecretId)] {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return s.mockSecretsManager.GetSecretValue(ctx, params, optFns...)
+}
+
+func TestPartialLoadOnDeadline(t *testing.T) {
+	api := &slowSecretsManager{
+		mockSecretsManager: newMockSecretsManager(map[string]string{
+			"dev/app":    `{"db_password":"s3cret"}`,
+			"dev/legacy": `{"api_token":"t0ken"}`,
+		}),
+		blocked: map[string]bool{"dev/legacy": true},
+	}
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/legacy"), WithPartialLoad())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.LoadSecrets(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LoadSecrets() error = %v, want DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "dev/legacy") || strings.Contains(err.Error(), "dev/app") {
+		t.Errorf("LoadSecrets() error = %q, want only the pending secret listed", err)
+	}
+
+	if value, err := c.GetSecret(context.Background(), "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v, want the secret loaded before the deadline", value, err)
+	}
+	if _, err := c.GetSecret(context.Background(), "api_token"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v for a pending secret, want ErrSecretNotFound", err)
+	}
+}
+
+func TestPartialLoadNothingLoaded(t *testing.T) {
+	api := &slowSecretsManager{
+		mockSecretsManager: newMockSecretsManager(map[string]string{
+			"dev/app":    `{"db_password":"s3cret"}`,
+			"dev/legacy": `{"api_token":"t0ken"}`,
+		}),
+	}
+
+	c := newTestClient(api, "dev/app", WithSecondarySecret("dev/legacy"), WithPartialLoad())
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	api.blocked = map[string]bool{"dev/app": true, "dev/legacy": true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := c.LoadSecrets(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("LoadSecrets() error = %v, want DeadlineExceeded", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "api_token"); value != "t0ken" {
+		t.Errorf("GetSecret() = %q, want the previous cache kept", value)
+	}
+}
+
+func TestLoadSecretsPlainKey(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	env := configs.DevelopmentEnv.ToString()
+	srv, _ := newSecretsManagerServer(t, map[string]string{
+		env + "/object": `{"api_token":"t0ken"}`,
+		env + "/string": `"t0ken"`,
+		env + "/plain":  `t0ken`,
+	})
+
+	tests := []struct {
+		name      string
+		secretKey string
+		opts      []Option
+		key       string
+	}{
+		{name: "JSON object", secretKey: "object", key: "api_token"},
+		{name: "JSON string", secretKey: "string", key: "string"},
+		{name: "plain text", secretKey: "plain", key: "plain"},
+		{name: "plain text with a plain key", secretKey: "plain", opts: []Option{WithPlainKey("api_token")}, key: "api_token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: tt.secretKey}}
+
+			c, err := NewAwsSecretClient(cfgs, append(tt.opts, WithEndpoint(srv.URL))...)
+			if err != nil {
+				t.Fatalf("NewAwsSecretClient() error = %v", err)
+			}
+			if err := c.LoadSecrets(context.Background()); err != nil {
+				t.Fatalf("LoadSecrets() error = %v", err)
+			}
+
+			if value, err := c.GetSecret(context.Background(), tt.key); err != nil || value != "t0ken" {
+				t.Errorf("GetSecret(%q) = %q, %v, want t0ken", tt.key, value, err)
+			}
+		})
+	}
+}
+
+func TestReloadIfStale(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"api_token":"v1"}`})
+	clock := newManualClock()
+	c := newTestClient(api, "dev/app", WithClock(clock))
+	ctx := context.Background()
+
+	if reloaded, err := c.ReloadIfStale(ctx, time.Minute); err != nil || !reloaded {
+		t.Fatalf("ReloadIfStale() = %v, %v before the first load, want a reload", reloaded, err)
+	}
+
+	api.mu.Lock()
+	api.secrets["dev/app"] = `{"api_token":"v2"}`
+	api.mu.Unlock()
+
+	clock.Advance(time.Minute)
+	if reloaded, err := c.ReloadIfStale(ctx, time.Minute); err != nil || reloaded {
+		t.Errorf("ReloadIfStale() = %v, %v with a fresh cache, want no reload", reloaded, err)
+	}
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v1" {
+		t.Errorf("GetSecret() = %q, want the cached value kept", value)
+	}
+
+	clock.Advance(time.Second)
+	if reloaded, err := c.ReloadIfStale(ctx, time.Minute); err != nil || !reloaded {
+		t.Errorf("ReloadIfStale() = %v, %v with a stale cache, want a reload", reloaded, err)
+	}
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v2" {
+		t.Errorf("GetSecret() = %q, want the reloaded value", value)
+	}
+
+	if got := api.Calls("GetSecretValue"); got != 2 {
+		t.Errorf("GetSecretValue calls = %d, want 2", got)
+	}
+}
+
+func TestLoadAwsConfigProfile(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_REGION", "")
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+	files := map[string]string{
+		configFile:      "[default]\nregion = us-east-2\n\n[profile staging]\nregion = us-west-2\n",
+		credentialsFile: "[default]\naws_access_key_id = AKIDDEFAULT\naws_secret_access_key = secret\n\n[staging]\naws_access_key_id = AKIDSTAGING\naws_secret_access_key = secret\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantRegion string
+		wantKeyId  string
+	}{
+		{name: "default chain", wantRegion: "us-east-2", wantKeyId: "AKIDDEFAULT"},
+		{name: "profile", opts: []Option{WithProfile("staging")}, wantRegion: "us-west-2", wantKeyId: "AKIDSTAGING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &options{}
+			for _, opt := range tt.opts {
+				opt(o)
+			}
+
+			awsCfg, err := loadAwsConfig(context.Background(), o)
+			if err != nil {
+				t.Fatalf("loadAwsConfig() error = %v", err)
+			}
+			if awsCfg.Region != tt.wantRegion {
+				t.Errorf("Region = %q, want %q", awsCfg.Region, tt.wantRegion)
+			}
+
+			creds, err := awsCfg.Credentials.Retrieve(context.Background())
+			if err != nil || creds.AccessKeyID != tt.wantKeyId {
+				t.Errorf("Retrieve() = %q, %v, want %q", creds.AccessKeyID, err, tt.wantKeyId)
+			}
+		})
+	}
+
+	if _, err := loadAwsConfig(context.Background(), &options{profile: "missing"}); err == nil {
+		t.Error("loadAwsConfig() succeeded with an unknown profile")
+	}
+}
+
+func TestResetCache(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret"}`})
+	c := newTestClient(api, "dev/app")
+	ctx := context.Background()
+
+	if _, err := c.GetSecret(ctx, "db_password"); !errors.Is(err, sm.ErrSecretsNotLoaded) {
+		t.Errorf("GetSecret() error = %v before loading, want ErrSecretsNotLoaded", err)
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var resetter sm.CacheResetter = c
+	resetter.ResetCache()
+
+	if _, err := c.GetSecret(ctx, "db_password"); !errors.Is(err, sm.ErrSecretsNotLoaded) {
+		t.Errorf("GetSecret() error = %v after a reset, want ErrSecretsNotLoaded", err)
+	}
+	if keys, _ := c.ListSecrets(ctx); len(keys) != 0 {
+		t.Errorf("ListSecrets() = %v after a reset, want no keys", keys)
+	}
+	if _, ok := c.SourceOf("db_password"); ok {
+		t.Error("SourceOf() still reports a source after a reset")
+	}
+	if got := api.Calls("GetSecretValue"); got != 1 {
+		t.Errorf("GetSecretValue calls = %d, want a reset without reload", got)
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.GetSecret(ctx, "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v after reloading, want s3cret", value, err)
+	}
+}
+
+func TestLoadOptionsIMDSv2Only(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{name: "default"},
+		{name: "IMDSv2 only", opts: []Option{WithIMDSv2Only()}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &options{}
+			for _, opt := range tt.opts {
+				opt(o)
+			}
+
+			var lo config.LoadOptions
+			for _, fn := range loadOptions(o) {
+				if err := fn(&lo); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := lo.EC2RoleCredentialOptions != nil; got != tt.want {
+				t.Fatalf("EC2 role credential options set = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+
+			var eo ec2rolecreds.Options
+			lo.EC2RoleCredentialOptions(&eo)
+			if _, ok := eo.Client.(*imds.Client); !ok {
+				t.Errorf("EC2 role credentials client = %T, want a dedicated IMDS client", eo.Client)
+			}
+		})
+	}
+}
+
+func TestBaseOverlay(t *testing.T) {
+	const (
+		base = `{"db_host":"base-host","db_password":"base"}`
+		env  = `{"db_password":"env","api_token":"t0ken"}`
+	)
+
+	tests := []struct {
+		name    string
+		secrets map[string]string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name:    "both levels",
+			secrets: map[string]string{"base/app": base, "dev/app": env},
+			want:    map[string]string{"db_host": "base-host", "db_password": "env", "api_token": "t0ken"},
+		},
+		{
+			name:    "base only",
+			secrets: map[string]string{"base/app": base},
+			want:    map[string]string{"db_host": "base-host", "db_password": "base"},
+		},
+		{
+			name:    "environment only",
+			secrets: map[string]string{"dev/app": env},
+			want:    map[string]string{"db_password": "env", "api_token": "t0ken"},
+		},
+		{
+			name:    "neither",
+			secrets: map[string]string{},
+			wantErr: sm.ErrSecretNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(newMockSecretsManager(tt.secrets), "dev/app", WithBaseOverlay())
+			c.baseId = "base/app"
+
+			err := c.LoadSecrets(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			snapshot, _ := c.Snapshot(context.Background())
+			if !maps.Equal(snapshot, tt.want) {
+				t.Errorf("Snapshot() = %v, want %v", snapshot, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireNonEmpty(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		opts    []Option
+		wantErr error
+	}{
+		{name: "empty object allowed by default", payload: `{}`},
+		{name: "empty object required non-empty", payload: `{}`, opts: []Option{WithRequireNonEmpty()}, wantErr: ErrEmptySecret},
+		{name: "filtered out keys", payload: `{"debug":"1"}`, opts: []Option{WithRequireNonEmpty(), WithNamespace("tenant")}, wantErr: ErrEmptySecret},
+		{name: "populated object", payload: `{"db_password":"s3cret"}`, opts: []Option{WithRequireNonEmpty()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(newMockSecretsManager(map[string]string{"dev/app": tt.payload}), "dev/app", tt.opts...)
+
+			if err := c.LoadSecrets(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnvironmentRegions(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	regions := WithEnvironmentRegions(map[string]string{
+		configs.ProductionEnv.ToString(): "us-east-1",
+		configs.StagingEnv.ToString():    "us-west-2",
+	})
+
+	tests := []struct {
+		env  configs.Environment
+		want string
+	}{
+		{env: configs.ProductionEnv, want: "us-east-1"},
+		{env: configs.StagingEnv, want: "us-west-2"},
+		{env: configs.DevelopmentEnv, want: "eu-west-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env.ToString(), func(t *testing.T) {
+			cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: tt.env, SecretKey: "app"}}
+
+			c, err := NewAwsSecretClient(cfgs, regions)
+			if err != nil {
+				t.Fatalf("NewAwsSecretClient() error = %v", err)
+			}
+			if got := c.(*awsSecretClient).region; got != tt.want {
+				t.Errorf("region = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	// Custom endpoints cannot have FIPS nor dual-stack variants, so both must be ignored
+	t.Setenv("AWS_USE_FIPS_ENDPOINT", "true")
+	t.Setenv("AWS_USE_DUALSTACK_ENDPOINT", "true")
+
+	env := configs.DevelopmentEnv.ToString()
+	srv, requests := newSecretsManagerServer(t, map[string]string{env + "/app": `{"db_password":"s3cret"}`})
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: "app"}}
+	c, err := NewAwsSecretClient(cfgs, WithEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewAwsSecretClient() error = %v", err)
+	}
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want s3cret", value)
+	}
+
+	if len(*requests) != 1 {
+		t.Fatalf("endpoint requests = %d, want 1", len(*requests))
+	}
+	if host := (*requests)[0].Host; host != strings.TrimPrefix(srv.URL, "http://") {
+		t.Errorf("request host = %q, want the configured endpoint", host)
+	}
+}
+
+func TestRefreshReportsChanges(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"kept":"1","changed":"old","removed":"x"}`})
+	c := newTestClient(api, "dev/app")
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	api.mu.Lock()
+	api.secrets["dev/app"] = `{"kept":"1","changed":"new","added":"y"}`
+	api.mu.Unlock()
+
+	changes, err := c.Refresh(ctx)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	want := []sm.ChangeEvent{
+		{Key: "added", Type: sm.ChangeAdded},
+		{Key: "changed", Type: sm.ChangeChanged},
+		{Key: "removed", Type: sm.ChangeRemoved},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Refresh() = %v, want %v", changes, want)
+	}
+
+	if changes, err := c.Refresh(ctx); err != nil || len(changes) != 0 {
+		t.Errorf("Refresh() = %v, %v without changes, want none", changes, err)
+	}
+}
+
+func TestSecretARNCrossAccount(t *testing.T) {
+	isolateAWSConfig(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	const arn = "arn:aws:secretsmanager:us-east-1:111122223333:secret:shared/db-AbCdEf"
+	srv, _ := newSecretsManagerServer(t, map[string]string{arn: `{"db_password":"s3cret"}`})
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: "app"}}
+	c, err := NewAwsSecretClient(cfgs, WithSecretARN(arn), WithEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewAwsSecretClient() error = %v", err)
+	}
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want the shared secret value", value)
+	}
+	if source, _ := c.(*awsSecretClient).SourceOf("db_password"); source != arn {
+		t.Errorf("SourceOf() = %q, want the cross-account ARN", source)
+	}
+}
+
+func TestLoadSecretsKeepsTypedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		typed    any
+		sentinel error
+	}{
+		{
+			name:     "resource not found",
+			err:      &types.ResourceNotFoundException{Message: aws.String("not found")},
+			typed:    new(*types.ResourceNotFoundException),
+			sentinel: sm.ErrSecretNotFound,
+		},
+		{
+			name:     "decryption failure",
+			err:      &types.DecryptionFailure{Message: aws.String("cannot decrypt")},
+			typed:    new(*types.DecryptionFailure),
+			sentinel: ErrDecryptionFailure,
+		},
+		{
+			name:  "internal service error",
+			err:   &types.InternalServiceError{Message: aws.String("internal")},
+			typed: new(*types.InternalServiceError),
+		},
+		{
+			name:  "invalid parameter",
+			err:   &types.InvalidParameterException{Message: aws.String("invalid parameter")},
+			typed: new(*types.InvalidParameterException),
+		},
+		{
+			name:  "invalid request",
+			err:   &types.InvalidRequestException{Message: aws.String("scheduled for deletion")},
+			typed: new(*types.InvalidRequestException),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newMockSecretsManager(map[string]string{})
+			api.failures = map[string]error{"dev/app": tt.err}
+
+			err := newTestClient(api, "dev/app").LoadSecrets(context.Background())
+			if !errors.As(err, tt.typed) {
+				t.Errorf("LoadSecrets() error = %v, want it to match %T", err, tt.err)
+			}
+			if tt.sentinel != nil && !errors.Is(err, tt.sentinel) {
+				t.Errorf("LoadSecrets() error = %v, want it to also match %v", err, tt.sentinel)
+			}
+		})
+	}
+}
+
+func TestReloadIfStaleSkewTolerance(t *testing.T) {
+	tests := []struct {
+		name         string
+		advance      time.Duration
+		wantReloaded bool
+	}{
+		{name: "within the skew", advance: time.Minute + 10*time.Second},
+		{name: "past the skew", advance: time.Minute + 10*time.Second + time.Nanosecond, wantReloaded: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newManualClock()
+			api := newMockSecretsManager(map[string]string{"dev/app": `{"api_token":"v1"}`})
+			c := newTestClient(api, "dev/app", WithClock(clock), WithSkewTolerance(10*time.Second))
+
+			if err := c.LoadSecrets(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			clock.Advance(tt.advance)
+			if reloaded, err := c.ReloadIfStale(context.Background(), time.Minute); err != nil || reloaded != tt.wantReloaded {
+				t.Errorf("ReloadIfStale() = %v, %v, want %v", reloaded, err, tt.wantReloaded)
+			}
+		})
+	}
+}
+
+func TestCredentialsProvider(t *testing.T) {
+	isolateAWSConfig(t)
+
+	// Credentials of the default chain, which the provider must bypass
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDENVIRONMENT")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	env := configs.DevelopmentEnv.ToString()
+	srv, requests := newSecretsManagerServer(t, map[string]string{env + "/app": `{"db_password":"s3cret"}`})
+
+	var retrievals atomic.Int32
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		retrievals.Add(1)
+		return aws.Credentials{AccessKeyID: "AKIDSIDECAR", SecretAccessKey: "secret", Source: "sidecar"}, nil
+	})
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.DevelopmentEnv, SecretKey: "app"}}
+	c, err := NewAwsSecretClient(cfgs, WithCredentialsProvider(provider), WithEndpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewAwsSecretClient() error = %v", err)
+	}
+
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if got := retrievals.Load(); got == 0 {
+		t.Error("credentials provider never called")
+	}
+	if auth := (*requests)[0].Header.Get("Authorization"); !strings.Contains(auth, "Credential=AKIDSIDECAR/") {
+		t.Errorf("Authorization = %q, want the request signed with the provider credentials", auth)
+	}
+}
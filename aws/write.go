@@ -0,0 +1,313 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+const (
+	// currentStage is the staging label AWS serves by default.
+	currentStage = "AWSCURRENT"
+
+	// pendingWriteStage labels a version written by WriteSecretIfVersion until it's
+	// promoted to currentStage.
+	pendingWriteStage = "GOXKIT_PENDING_WRITE"
+
+	// PreviewUnchanged replaces the values a previewed write leaves untouched.
+	PreviewUnchanged = "<redacted>"
+
+	// PreviewChanged replaces the values a previewed write modifies.
+	PreviewChanged = "<redacted:changed>"
+
+	// PreviewAdded replaces the values a previewed write adds.
+	PreviewAdded = "<redacted:added>"
+)
+
+// WriteSecret stores value under key in the primary secret and updates the cache.
+//
+// The secret is read, modified and written back as a new version with PutSecretValue, so
+// the other keys are preserved. The key is prefixed with the namespace when one is
+// configured, and the value is encrypted client-side when WithKMSEncryption is set.
+// Writes from other processes between the read and the write are overwritten unless they
+// share a lock configured with WithLocker; otherwise use WriteSecretIfVersion when several
+// writers may run at once.
+// It implements the secretsmanager.Writer interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//   - key: The secret key to write
+//   - value: The plaintext value to store
+//
+// Returns:
+//   - An error if the secret cannot be read, encrypted or written
+func (c *awsSecretClient) WriteSecret(ctx context.Context, key, value string) error {
+	unlock, err := c.lockWrites(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	body, _, err := c.updatedPayload(ctx, key, value)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.api().PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(c.appSecretId),
+		SecretString: aws.String(body),
+	})
+	if err != nil {
+		c.logger.Error("error to put secret value", zap.String("key", key), zap.Error(err))
+		return mapError(err)
+	}
+
+	c.cacheWrite(ctx, key, value)
+
+	return nil
+}
+
+// PreviewWriteSecret returns the primary secret as WriteSecret would store it, without
+// calling PutSecretValue, so tooling can show the change before applying it. The preview
+// is the JSON object of the stored keys, namespace prefix included, whose values are
+// replaced by PreviewUnchanged, PreviewChanged or PreviewAdded; no value ever appears in it.
+// It implements the secretsmanager.DryRunWriter interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//   - key: The secret key to write
+//   - value: The plaintext value to store
+//
+// Returns:
+//   - The redacted JSON preview of the resulting secret
+//   - An error if the secret cannot be read or its current value decrypted
+func (c *awsSecretClient) PreviewWriteSecret(ctx context.Context, key, value string) (string, error) {
+	current, _, err := c.fetchRawVersion(ctx, c.appSecretId)
+	if err != nil {
+		return "", err
+	}
+
+	preview := make(map[string]string, len(current)+1)
+	for k := range current {
+		preview[k] = PreviewUnchanged
+	}
+
+	stored := c.storedKey(key)
+
+	previous, exists := current[stored]
+	if exists && c.cipher != nil {
+		if previous, err = c.cipher.Decrypt(ctx, previous); err != nil {
+			c.logger.Error("error to decrypt secret value", zap.String("key", key), zap.Error(err))
+			return "", err
+		}
+	}
+
+	switch {
+	case !exists:
+		preview[stored] = PreviewAdded
+	case previous != value:
+		preview[stored] = PreviewChanged
+	}
+
+	if c.opts.verifyChecksum && preview[stored] != PreviewUnchanged {
+		preview[ChecksumKey] = PreviewChanged
+		if _, ok := current[ChecksumKey]; !ok {
+			preview[ChecksumKey] = PreviewAdded
+		}
+	}
+
+	// Keys are sorted by encoding/json, keeping previews comparable
+	body, err := json.MarshalIndent(preview, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// CurrentVersion returns the identifier of the version of the primary secret currently
+// labeled AWSCURRENT, to pass to WriteSecretIfVersion.
+// It implements the secretsmanager.VersionedWriter interface.
+func (c *awsSecretClient) CurrentVersion(ctx context.Context) (string, error) {
+	_, versionId, err := c.fetchRawVersion(ctx, c.appSecretId)
+	return versionId, err
+}
+
+// WriteSecretIfVersion stores value under key like WriteSecret, but only if the current
+// version of the primary secret is still expectedVersionId.
+//
+// The new version is first stored under a private staging label, then promoted to
+// AWSCURRENT with UpdateSecretVersionStage, which AWS only accepts while AWSCURRENT is
+// still attached to expectedVersionId. A concurrent writer promoting its own version in
+// between therefore makes this write fail with sm.ErrConflict instead of being clobbered.
+// It implements the secretsmanager.VersionedWriter interface.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//   - key: The secret key to write
+//   - value: The plaintext value to store
+//   - expectedVersionId: The version the caller based the change on
+//
+// Returns:
+//   - sm.ErrConflict if the secret changed since expectedVersionId
+//   - An error if the secret cannot be read, encrypted or written
+func (c *awsSecretClient) WriteSecretIfVersion(ctx context.Context, key, value, expectedVersionId string) error {
+	unlock, err := c.lockWrites(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	body, versionId, err := c.updatedPayload(ctx, key, value)
+	if err != nil {
+		return err
+	}
+
+	if versionId != expectedVersionId {
+		return fmt.Errorf("%w: current version %s, expected %s", sm.ErrConflict, versionId, expectedVersionId)
+	}
+
+	res, err := c.api().PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:      aws.String(c.appSecretId),
+		SecretString:  aws.String(body),
+		VersionStages: []string{pendingWriteStage},
+	})
+	if err != nil {
+		c.logger.Error("error to put secret value", zap.String("key", key), zap.Error(err))
+		return mapError(err)
+	}
+
+	_, err = c.api().UpdateSecretVersionStage(ctx, &secretsmanager.UpdateSecretVersionStageInput{
+		SecretId:            aws.String(c.appSecretId),
+		VersionStage:        aws.String(currentStage),
+		MoveToVersionId:     res.VersionId,
+		RemoveFromVersionId: aws.String(expectedVersionId),
+	})
+	if err != nil {
+		var invalid *types.InvalidParameterException
+		if errors.As(err, &invalid) {
+			return fmt.Errorf("%w: version %s is no longer current", sm.ErrConflict, expectedVersionId)
+		}
+
+		c.logger.Error("error to promote secret version", zap.String("key", key), zap.Error(err))
+		return mapError(err)
+	}
+
+	c.cacheWrite(ctx, key, value)
+
+	return nil
+}
+
+// lockWrites serializes the writes of the client, then acquires the configured Locker.
+// The returned function releases both; an unlock failure is only logged, since the write
+// already happened and the lock implementation is expected to expire abandoned locks.
+func (c *awsSecretClient) lockWrites(ctx context.Context) (func(), error) {
+	c.writeMu.Lock()
+
+	if err := c.opts.locker.Lock(ctx, c.appSecretId); err != nil {
+		c.writeMu.Unlock()
+		c.logger.Error("error to acquire the write lock", zap.Error(err))
+		return nil, fmt.Errorf("acquire write lock: %w", err)
+	}
+
+	return func() {
+		defer c.writeMu.Unlock()
+
+		// The lock is released even when ctx was cancelled during the write
+		if err := c.opts.locker.Unlock(context.WithoutCancel(ctx), c.appSecretId); err != nil {
+			c.logger.Warn("error to release the write lock", zap.Error(err))
+		}
+	}, nil
+}
+
+// updatedPayload reads the primary secret and returns it encoded with key set to value,
+// along with the version read.
+func (c *awsSecretClient) updatedPayload(ctx context.Context, key, value string) (string, string, error) {
+	current, versionId, err := c.fetchRawVersion(ctx, c.appSecretId)
+	if err != nil {
+		return "", "", err
+	}
+
+	stored := value
+	if c.cipher != nil {
+		if stored, err = c.cipher.Encrypt(ctx, value); err != nil {
+			c.logger.Error("error to encrypt secret value", zap.String("key", key), zap.Error(err))
+			return "", "", err
+		}
+	}
+
+	current[c.storedKey(key)] = stored
+
+	if c.opts.verifyChecksum {
+		delete(current, ChecksumKey)
+		current[ChecksumKey] = ChecksumSecrets(current)
+	}
+
+	body, err := json.Marshal(current)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := c.checkWriteSize(key, len(body)); err != nil {
+		return "", "", err
+	}
+
+	return string(body), versionId, nil
+}
+
+// checkWriteSize fails a write whose payload exceeds the configured size limit, and
+// warns when it exceeds the warning threshold.
+func (c *awsSecretClient) checkWriteSize(key string, size int) error {
+	if c.opts.sizeLimit > 0 && size > c.opts.sizeLimit {
+		c.logger.Error("secret payload exceeds the size limit",
+			zap.String("key", key), zap.Int("size", size), zap.Int("limit", c.opts.sizeLimit))
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrPayloadTooLarge, size, c.opts.sizeLimit)
+	}
+
+	if c.opts.sizeWarning > 0 && size > c.opts.sizeWarning {
+		c.logger.Warn("secret payload is approaching the aws size limit",
+			zap.String("key", key), zap.Int("size", size), zap.Int("max", MaxSecretSize))
+	}
+
+	return nil
+}
+
+// cacheWrite records a written value in the cache. The value is already stored in AWS,
+// so a cache store failure is only logged and the value is served after the next load.
+func (c *awsSecretClient) cacheWrite(ctx context.Context, key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	secrets, err := sm.StoreSnapshot(ctx, c.cache)
+	if err == nil {
+		secrets[key] = value
+		err = c.cache.SetAll(ctx, secrets)
+	}
+	if err != nil {
+		c.logger.Warn("error to update the cache store after a write", zap.String("key", key), zap.Error(err))
+		return
+	}
+
+	c.sources[key] = c.appSecretId
+}
+
+// storedKey returns the key as stored in AWS, prefixed with the namespace if any.
+func (c *awsSecretClient) storedKey(key string) string {
+	if c.namespace == "" {
+		return key
+	}
+
+	return c.namespace + c.separator + key
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// storedSecrets decodes the secret string stored under id.
+func storedSecrets(t *testing.T, api *mockSecretsManager, id string) map[string]string {
+	t.Helper()
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal([]byte(api.Secret(id)), &secrets); err != nil {
+		t.Fatalf("decode stored secret: %v", err)
+	}
+
+	return secrets
+}
+
+// racingSecretsManager is a mockSecretsManager where another writer stores a version
+// right before the first staged write.
+type racingSecretsManager struct {
+	*mockSecretsManager
+
+	raced bool
+}
+
+func (r *racingSecretsManager) PutSecretValue(
+	ctx context.Context,
+	params *secretsmanager.PutSecretValueInput,
+	optFns ...func(*secretsmanager.Options),
+) (*secretsmanager.PutSecretValueOutput, error) {
+	if !r.raced {
+		r.raced = true
+
+		concurrent := &secretsmanager.PutSecretValueInput{SecretId: params.SecretId, SecretString: aws.String(`{"api_token":"concurrent"}`)}
+		if _, err := r.mockSecretsManager.PutSecretValue(ctx, concurrent); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.mockSecretsManager.PutSecretValue(ctx, params, optFns...)
+}
+
+func TestWriteSecretIfVersion(t *testing.T) {
+	api := newMockSecretsManager(map[string]string{"dev/app": `{"api_token":"v1","db_password":"s3cret"}`})
+	c := newTestClient(api, "dev/app")
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	versionId, err := c.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatalf("CurrentVersion() error = %v", err)
+	}
+
+	if err := c.WriteSecretIfVersion(ctx, "api_token", "v2", versionId); err != nil {
+		t.Fatalf("WriteSecretIfVersion() error = %v", err)
+	}
+
+	if got, want := storedSecrets(t, api, "dev/app"), map[string]string{"api_token": "v2", "db_password": "s3cret"}; !maps.Equal(got, want) {
+		t.Errorf("stored secret = %v, want %v", got, want)
+	}
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v2" {
+		t.Errorf("GetSecret() = %q, want the written value cached", value)
+	}
+	if next, _ := c.CurrentVersion(ctx); next == versionId {
+		t.Errorf("CurrentVersion() = %q after the write, want a new version", next)
+	}
+
+	if err := c.WriteSecretIfVersion(ctx, "api_token", "v3", versionId); !errors.Is(err, sm.ErrConflict) {
+		t.Errorf("WriteSecretIfVersion() error = %v with an outdated version, want ErrConflict", err)
+	}
+	if got := api.Calls("PutSecretValue"); got != 1 {
+		t.Errorf("PutSecretValue calls = %d, want none for an outdated version", got)
+	}
+}
+
+func TestWriteSecretIfVersionConcurrentWriter(t *testing.T) {
+	api := &racingSecretsManager{mockSecretsManager: newMockSecretsManager(map[string]string{"dev/app": `{"api_token":"v1"}`})}
+	c := newTestClient(api, "dev/app")
+	ctx := context.Background()
+
+	versionId, err := c.CurrentVersion(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.WriteSecretIfVersion(ctx, "api_token", "mine", versionId); !errors.Is(err, sm.ErrConflict) {
+		t.Fatalf("WriteSecretIfVersion() error = %v, want ErrConflict", err)
+	}
+
+	if got := storedSecrets(t, api.mockSecretsManager, "dev/app"); got["api_token"] != "concurrent" {
+		t.Errorf("stored api_token = %q, want the concurrent write kept", got["api_token"])
+	}
+}
+
+func TestPreviewWriteSecret(t *testing.T) {
+	const stored = `{"tenant.api_token":"t0ken","tenant.db_password":"s3cret","other":"x"}`
+
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  map[string]string
+	}{
+		{
+			name:  "changed",
+			key:   "db_password",
+			value: "rotated",
+			want:  map[string]string{"tenant.api_token": PreviewUnchanged, "tenant.db_password": PreviewChanged, "other": PreviewUnchanged},
+		},
+		{
+			name:  "added",
+			key:   "db_user",
+			value: "admin",
+			want: map[string]string{
+				"tenant.api_token":   PreviewUnchanged,
+				"tenant.db_password": PreviewUnchanged,
+				"tenant.db_user":     PreviewAdded,
+				"other":              PreviewUnchanged,
+			},
+		},
+		{
+			name:  "unchanged",
+			key:   "db_password",
+			value: "s3cret",
+			want:  map[string]string{"tenant.api_token": PreviewUnchanged, "tenant.db_password": PreviewUnchanged, "other": PreviewUnchanged},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newMockSecretsManager(map[string]string{"dev/app": stored})
+			c := newTestClient(api, "dev/app", WithNamespace("tenant"))
+
+			var writer sm.DryRunWriter = c
+			preview, err := writer.PreviewWriteSecret(context.Background(), tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("PreviewWriteSecret() error = %v", err)
+			}
+
+			got := map[string]string{}
+			if err := json.Unmarshal([]byte(preview), &got); err != nil {
+				t.Fatalf("preview %q isn't a JSON object: %v", preview, err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("PreviewWriteSecret() = %v, want %v", got, tt.want)
+			}
+
+			for _, value := range []string{"t0ken", "s3cret", tt.value} {
+				if strings.Contains(preview, value) {
+					t.Errorf("preview %q exposes a value", preview)
+				}
+			}
+
+			if got := api.Calls("PutSecretValue"); got != 0 {
+				t.Errorf("PutSecretValue calls = %d, want none for a preview", got)
+			}
+			if api.Secret("dev/app") != stored {
+				t.Error("PreviewWriteSecret() modified the stored secret")
+			}
+		})
+	}
+}
+
+func TestWriteSecretSizeThresholds(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantWarning bool
+		wantErr     error
+	}{
+		{name: "under the threshold", value: "small"},
+		{name: "near the limit", value: strings.Repeat("x", 120), wantWarning: true},
+		{name: "over the limit", value: strings.Repeat("x", 250), wantErr: ErrPayloadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newMockSecretsManager(map[string]string{"dev/app": `{"db_password":"s3cret"}`})
+			core, logs := observer.New(zap.WarnLevel)
+
+			c := newTestClient(api, "dev/app", WithSizeWarningThreshold(100), WithSizeLimit(200))
+			c.logger = zap.New(core)
+
+			err := c.WriteSecret(context.Background(), "certificate", tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteSecret() error = %v, want %v", err, tt.wantErr)
+			}
+
+			warned := logs.FilterMessage("secret payload is approaching the aws size limit").Len() > 0
+			if warned != tt.wantWarning {
+				t.Errorf("size warning logged = %v, want %v", warned, tt.wantWarning)
+			}
+
+			wantPuts := 1
+			if tt.wantErr != nil {
+				wantPuts = 0
+			}
+			if got := api.Calls("PutSecretValue"); got != wantPuts {
+				t.Errorf("PutSecretValue calls = %d, want %d", got, wantPuts)
+			}
+		})
+	}
+}
+
+// fakeLocker is a sm.Locker shared by several clients, standing in for a distributed lock.
+type fakeLocker struct {
+	sem      chan struct{}
+	mu       sync.Mutex
+	held     int
+	maxHeld  int
+	names    []string
+	lockErr  error
+	unlocked int
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{sem: make(chan struct{}, 1)}
+}
+
+func (l *fakeLocker) Lock(ctx context.Context, name string) error {
+	if l.lockErr != nil {
+		return l.lockErr
+	}
+
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held++
+	l.maxHeld = max(l.maxHeld, l.held)
+	l.names = append(l.names, name)
+
+	return nil
+}
+
+func (l *fakeLocker) Unlock(context.Context, string) error {
+	l.mu.Lock()
+	l.held--
+	l.unlocked++
+	l.mu.Unlock()
+
+	<-l.sem
+	return nil
+}
+
+func TestWriteSecretLocker(t *testing.T) {
+	const writers = 10
+
+	api := newMockSecretsManager(map[string]string{"dev/app": `{}`})
+	locker := newFakeLocker()
+
+	// Clients standing for two instances, only coordinated through the locker
+	instances := []*awsSecretClient{
+		newTestClient(api, "dev/app", WithLocker(locker)),
+		newTestClient(api, "dev/app", WithLocker(locker)),
+	}
+
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			key := fmt.Sprintf("key_%d", i)
+			if err := instances[i%len(instances)].WriteSecret(context.Background(), key, "value"); err != nil {
+				t.Errorf("WriteSecret(%q) error = %v", key, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := storedSecrets(t, api, "dev/app"); len(got) != writers {
+		t.Errorf("stored secret holds %d keys, want every write kept: %v", len(got), got)
+	}
+	if locker.maxHeld != 1 {
+		t.Errorf("concurrent lock holders = %d, want writes serialized", locker.maxHeld)
+	}
+	if locker.unlocked != writers {
+		t.Errorf("unlocks = %d, want %d", locker.unlocked, writers)
+	}
+	for _, name := range locker.names {
+		if name != "dev/app" {
+			t.Errorf("lock name = %q, want the secret ID", name)
+		}
+	}
+}
+
+func TestWriteSecretLockFailure(t *testing.T) {
+	errLock := errors.New("lock table unavailable")
+
+	api := newMockSecretsManager(map[string]string{"dev/app": `{}`})
+	locker := newFakeLocker()
+	locker.lockErr = errLock
+
+	err := newTestClient(api, "dev/app", WithLocker(locker)).WriteSecret(context.Background(), "api_token", "t0ken")
+	if !errors.Is(err, errLock) {
+		t.Errorf("WriteSecret() error = %v, want the lock error", err)
+	}
+	if got := api.Calls("PutSecretValue"); got != 0 {
+		t.Errorf("PutSecretValue calls = %d, want none without the lock", got)
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package azureappconfig provides an Azure App Configuration implementation of the
+// SecretClient interface. It reads the settings labeled with the application environment
+// and resolves Key Vault references to the value of the secret they point to.
+package azureappconfig
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goxkit/configs"
+	"github.com/goxkit/logging"
+	"go.uber.org/zap"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/logs"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+const (
+	// DefaultTimeout bounds every request made by the client.
+	DefaultTimeout = 10 * time.Second
+
+	// appConfigAPIVersion is the App Configuration data plane API version used.
+	appConfigAPIVersion = "1.0"
+
+	// keyVaultAPIVersion is the Key Vault API version used to read referenced secrets.
+	keyVaultAPIVersion = "7.4"
+
+	// keyVaultRefContentType is the content type of settings referencing a Key Vault secret.
+	keyVaultRefContentType = "application/vnd.microsoft.appconfig.keyvaultref+json"
+
+	// maxBodySize is the largest response accepted.
+	maxBodySize = 4 << 20
+)
+
+var (
+	// ErrInvalidConnectionString is returned when the connection string lacks its
+	// Endpoint, Id or Secret.
+	ErrInvalidConnectionString = errors.New("invalid azure app configuration connection string")
+
+	// ErrKeyVaultTokenRequired is returned when a setting references Key Vault but no
+	// token source was configured with WithKeyVaultToken.
+	ErrKeyVaultTokenRequired = errors.New("key vault reference requires a token source")
+
+	// ErrUntrustedKeyVault is returned when a Key Vault reference points to a host outside
+	// the trusted vault domains, so the Key Vault token is never sent to it.
+	ErrUntrustedKeyVault = errors.New("key vault reference points to an untrusted host")
+
+	// defaultVaultDomains are the Key Vault domains of the Azure public and sovereign clouds.
+	defaultVaultDomains = []string{
+		"vault.azure.net",
+		"vault.azure.cn",
+		"vault.usgovcloudapi.net",
+		"vault.microsoftazure.de",
+	}
+)
+
+type (
+	// TokenFunc returns a Microsoft Entra ID access token for the Key Vault resource
+	// (https://vault.azure.net), e.g. obtained from a managed identity.
+	TokenFunc func(ctx context.Context) (string, error)
+
+	// appConfigSecretClient is an implementation of the SecretClient interface reading
+	// the labeled settings of an Azure App Configuration store into an in-memory cache.
+	appConfigSecretClient struct {
+		logger       logging.Logger
+		client       *http.Client
+		endpoint     string    // App Configuration store endpoint
+		credential   string    // Access key identifier
+		secret       []byte    // Decoded access key secret
+		label        string    // Label selecting the environment's settings
+		keyFilter    string    // Filter selecting the keys to read
+		vaultToken   TokenFunc // Source of the Key Vault access token
+		vaultDomains []string  // Domains Key Vault references may point to
+
+		mu       sync.RWMutex
+		secrets  map[string]redact.String // In-memory cache of secret key-value pairs
+		modified map[string]time.Time     // Last modification time of each setting
+	}
+
+	// Option configures optional behavior of the Azure App Configuration client.
+	Option func(*appConfigSecretClient)
+
+	// keyValuePage is a page of the App Configuration key-value listing.
+	keyValuePage struct {
+		Items    []keyValue `json:"items"`
+		NextLink string     `json:"@nextLink"`
+	}
+
+	// keyValue is an App Configuration setting.
+	keyValue struct {
+		Key          string    `json:"key"`
+		ContentType  string    `json:"content_type"`
+		Value        string    `json:"value"`
+		LastModified time.Time `json:"last_modified"`
+	}
+
+	// keyVaultRef is the value of a setting referencing a Key Vault secret.
+	keyVaultRef struct {
+		URI string `json:"uri"`
+	}
+)
+
+// WithHTTPClient replaces the HTTP client used to reach App Configuration and Key Vault.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *appConfigSecretClient) {
+		c.client = client
+	}
+}
+
+// WithKeyFilter restricts the settings read to the keys matching filter, using the App
+// Configuration filter syntax, such as "myapp/*". All keys are read by default.
+func WithKeyFilter(filter string) Option {
+	return func(c *appConfigSecretClient) {
+		c.keyFilter = filter
+	}
+}
+
+// WithLabel reads the settings with label instead of the application environment.
+func WithLabel(label string) Option {
+	return func(c *appConfigSecretClient) {
+		c.label = label
+	}
+}
+
+// WithKeyVaultToken sets the source of the access token used to resolve Key Vault
+// references. Loading a store holding references fails without it.
+func WithKeyVaultToken(fn TokenFunc) Option {
+	return func(c *appConfigSecretClient) {
+		c.vaultToken = fn
+	}
+}
+
+// WithKeyVaultDomains replaces the domains Key Vault references may point to, e.g. to
+// allow a private endpoint. A reference whose host is neither one of the domains nor a
+// subdomain of one fails with ErrUntrustedKeyVault. Defaults to the vault domains of the
+// Azure public and sovereign clouds.
+func WithKeyVaultDomains(domains ...string) Option {
+	return func(c *appConfigSecretClient) {
+		c.vaultDomains = domains
+	}
+}
+
+// NewAzureAppConfigSecretClient creates a client reading an Azure App Configuration store.
+//
+// The connection string is the one shown in the Access keys of the store, in the form
+// "Endpoint=https://{store}.azconfig.io;Id={id};Secret={secret}". Requests are signed
+// with the access key. The environment of the application is used as the label of the
+// settings to read.
+//
+// Parameters:
+//   - cfgs: Application configuration containing the environment and logger
+//   - connectionString: The access key connection string of the store
+//   - opts: Optional settings such as the key filter or the Key Vault token source
+//
+// Returns:
+//   - A SecretClient interface implementation for Azure App Configuration
+//   - ErrInvalidConnectionString if the connection string cannot be parsed
+func NewAzureAppConfigSecretClient(cfgs *configs.Configs, connectionString string, opts ...Option) (sm.SecretClient, error) {
+	endpoint, credential, secret, err := parseConnectionString(connectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &appConfigSecretClient{
+		logger:       logs.FromConfigs(cfgs),
+		client:       &http.Client{Timeout: DefaultTimeout},
+		endpoint:     endpoint,
+		credential:   credential,
+		secret:       secret,
+		label:        cfgs.AppConfigs.Environment.ToString(),
+		vaultDomains: defaultVaultDomains,
+		secrets:      make(map[string]redact.String),
+		modified:     make(map[string]time.Time),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// LoadSecrets reads every setting with the configured label and replaces the in-memory
+// cache with them, keyed by setting key. Key Vault references are resolved to the value
+// of the referenced secret. The cache is left untouched when any request fails.
+//
+// Parameters:
+//   - ctx: Context for controlling the requests lifecycle
+//
+// Returns:
+//   - An error if the settings cannot be listed or a Key Vault reference cannot be resolved
+func (c *appConfigSecretClient) LoadSecrets(ctx context.Context) error {
+	settings, err := c.listSettings(ctx)
+	if err != nil {
+		c.logger.Error("error to list azure app configuration settings", zap.Error(err))
+		return err
+	}
+
+	secrets := make(map[string]string, len(settings))
+	modified := make(map[string]time.Time, len(settings))
+	for _, kv := range settings {
+		value := kv.Value
+
+		if isKeyVaultRef(kv.ContentType) {
+			if value, err = c.resolveKeyVaultRef(ctx, kv.Value); err != nil {
+				c.logger.Error("error to resolve key vault reference", zap.String("key", kv.Key), zap.Error(err))
+				return fmt.Errorf("setting %q: %w", kv.Key, err)
+			}
+		}
+
+		secrets[kv.Key] = value
+		modified[kv.Key] = kv.LastModified
+	}
+
+	c.mu.Lock()
+	c.secrets = redact.Map(secrets)
+	c.modified = modified
+	c.mu.Unlock()
+
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *appConfigSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
+
+// LastModified returns when the setting key was last modified in App Configuration, as
+// of the last load. For a Key Vault reference it's the time the reference itself changed,
+// not the referenced secret. It implements the secretsmanager.LastModifiedReporter
+// interface.
+func (c *appConfigSecretClient) LastModified(key string) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	modified, ok := c.modified[key]
+	return modified, ok && !modified.IsZero()
+}
+
+// listSettings lists the settings matching the label and key filter, following pagination.
+func (c *appConfigSecretClient) listSettings(ctx context.Context) ([]keyValue, error) {
+	query := url.Values{}
+	query.Set("label", c.label)
+	query.Set("api-version", appConfigAPIVersion)
+	if c.keyFilter != "" {
+		query.Set("key", c.keyFilter)
+	}
+
+	var settings []keyValue
+
+	next := "/kv?" + query.Encode()
+	for next != "" {
+		var page keyValuePage
+		if err := c.getSigned(ctx, next, &page); err != nil {
+			return nil, err
+		}
+
+		settings = append(settings, page.Items...)
+		next = strings.TrimPrefix(page.NextLink, c.endpoint)
+	}
+
+	return settings, nil
+}
+
+// getSigned sends a GET request for pathAndQuery to the store, signed with the access
+// key, and decodes the JSON response into out.
+func (c *appConfigSecretClient) getSigned(ctx context.Context, pathAndQuery string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+pathAndQuery, nil)
+	if err != nil {
+		return err
+	}
+
+	c.sign(req, pathAndQuery)
+
+	return c.do(req, "list settings", out)
+}
+
+// sign adds the HMAC-SHA256 authentication headers of App Configuration to a bodiless
+// request.
+func (c *appConfigSecretClient) sign(req *http.Request, pathAndQuery string) {
+	date := time.Now().UTC().Format(http.TimeFormat)
+	emptyHash := sha256.Sum256(nil)
+	contentHash := base64.StdEncoding.EncodeToString(emptyHash[:])
+
+	stringToSign := req.Method + "\n" + pathAndQuery + "\n" + date + ";" + req.URL.Host + ";" + contentHash
+
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	req.Header.Set("x-ms-date", date)
+	req.Header.Set("x-ms-content-sha256", contentHash)
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"HMAC-SHA256 Credential=%s&SignedHeaders=x-ms-date;host;x-ms-content-sha256&Signature=%s",
+		c.credential, signature,
+	))
+}
+
+// resolveKeyVaultRef reads the Key Vault secret referenced by the setting value.
+func (c *appConfigSecretClient) resolveKeyVaultRef(ctx context.Context, value string) (string, error) {
+	if c.vaultToken == nil {
+		return "", ErrKeyVaultTokenRequired
+	}
+
+	var ref keyVaultRef
+	if err := json.Unmarshal([]byte(value), &ref); err != nil {
+		return "", fmt.Errorf("parse key vault reference: %w", err)
+	}
+
+	secretURL, err := url.Parse(ref.URI)
+	if err != nil {
+		return "", fmt.Errorf("parse key vault reference: %w", err)
+	}
+
+	if secretURL.Scheme != "https" || !c.trustedVault(secretURL.Hostname()) {
+		return "", fmt.Errorf("%w: %s", ErrUntrustedKeyVault, secretURL.Host)
+	}
+
+	token, err := c.vaultToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get key vault token: %w", err)
+	}
+
+	query := secretURL.Query()
+	query.Set("api-version", keyVaultAPIVersion)
+	secretURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, secretURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var secret struct {
+		Value string `json:"value"`
+	}
+	if err := c.do(req, "get key vault secret", &secret); err != nil {
+		return "", err
+	}
+
+	return secret.Value, nil
+}
+
+// trustedVault reports whether host is one of the trusted vault domains or a subdomain
+// of one.
+func (c *appConfigSecretClient) trustedVault(host string) bool {
+	for _, domain := range c.vaultDomains {
+		domain = strings.TrimPrefix(domain, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// do sends req and decodes its JSON response into out. Error responses report the
+// status only, since their body may echo request details.
+func (c *appConfigSecretClient) do(req *http.Request, operation string, out interface{}) error {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", operation, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status %d", operation, res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("%s: read response: %w", operation, err)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s: decode response: %w", operation, err)
+	}
+
+	return nil
+}
+
+// isKeyVaultRef reports whether contentType marks a Key Vault reference, ignoring its
+// parameters such as the charset.
+func isKeyVaultRef(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.EqualFold(strings.TrimSpace(mediaType), keyVaultRefContentType)
+}
+
+// parseConnectionString extracts the endpoint, access key identifier and decoded secret
+// of an App Configuration connection string.
+func parseConnectionString(connectionString string) (string, string, []byte, error) {
+	var endpoint, credential, secret string
+
+	for _, part := range strings.Split(connectionString, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.TrimSpace(name) {
+		case "Endpoint":
+			endpoint = strings.TrimSuffix(value, "/")
+		case "Id":
+			credential = value
+		case "Secret":
+			secret = value
+		}
+	}
+
+	if endpoint == "" || credential == "" || secret == "" {
+		return "", "", nil, ErrInvalidConnectionString
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("%w: secret is not valid base64", ErrInvalidConnectionString)
+	}
+
+	return endpoint, credential, decoded, nil
+}
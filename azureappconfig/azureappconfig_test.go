@@ -0,0 +1,236 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package azureappconfig_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goxkit/configs"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/azureappconfig"
+)
+
+var accessKey = []byte("app-config-access-key")
+
+// fakeAzure serves both the App Configuration store and the Key Vault referenced by its
+// settings, failing every request with status when it's set.
+type fakeAzure struct {
+	t        *testing.T
+	srv      *httptest.Server
+	settings []map[string]string
+	status   atomic.Int32
+}
+
+func newFakeAzure(t *testing.T, settings ...map[string]string) *fakeAzure {
+	f := &fakeAzure{t: t, settings: settings}
+	f.srv = httptest.NewTLSServer(f)
+	t.Cleanup(f.srv.Close)
+
+	return f
+}
+
+func (f *fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if status := f.status.Load(); status != 0 {
+		w.WriteHeader(int(status))
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/kv":
+		f.serveSettings(w, r)
+	case r.URL.Path == "/secrets/db-password":
+		if r.Header.Get("Authorization") != "Bearer vault-t0ken" || r.URL.Query().Get("api-version") != "7.4" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"value": "vault-s3cret"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// serveSettings lists the settings one per page, after checking the request signature.
+func (f *fakeAzure) serveSettings(w http.ResponseWriter, r *http.Request) {
+	hash := sha256.Sum256(nil)
+	stringToSign := r.Method + "\n" + r.URL.RequestURI() + "\n" + r.Header.Get("x-ms-date") + ";" + r.Host + ";" +
+		base64.StdEncoding.EncodeToString(hash[:])
+	mac := hmac.New(sha256.New, accessKey)
+	mac.Write([]byte(stringToSign))
+
+	want := "HMAC-SHA256 Credential=key-id&SignedHeaders=x-ms-date;host;x-ms-content-sha256&Signature=" +
+		base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if r.Header.Get("Authorization") != want {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("label") != "staging" || query.Get("api-version") != "1.0" {
+		f.t.Errorf("query = %v, want the environment label", query)
+	}
+
+	var page struct {
+		Items    []map[string]string `json:"items"`
+		NextLink string              `json:"@nextLink,omitempty"`
+	}
+
+	after := 0
+	if query.Get("after") == "1" {
+		after = 1
+	}
+	if after < len(f.settings) {
+		page.Items = f.settings[after : after+1]
+	}
+	if after == 0 && len(f.settings) > 1 {
+		query.Set("after", "1")
+		page.NextLink = f.srv.URL + "/kv?" + query.Encode()
+	}
+
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (f *fakeAzure) connectionString() string {
+	return "Endpoint=" + f.srv.URL + "/;Id=key-id;Secret=" + base64.StdEncoding.EncodeToString(accessKey)
+}
+
+func (f *fakeAzure) newClient(t *testing.T, opts ...azureappconfig.Option) sm.SecretClient {
+	t.Helper()
+
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{Environment: configs.StagingEnv}}
+	opts = append([]azureappconfig.Option{
+		azureappconfig.WithHTTPClient(f.srv.Client()),
+		azureappconfig.WithKeyVaultDomains("127.0.0.1"),
+	}, opts...)
+
+	c, err := azureappconfig.NewAzureAppConfigSecretClient(cfgs, f.connectionString(), opts...)
+	if err != nil {
+		t.Fatalf("NewAzureAppConfigSecretClient() error = %v", err)
+	}
+
+	return c
+}
+
+func (f *fakeAzure) keyVaultRef() map[string]string {
+	return map[string]string{
+		"key":          "db_password",
+		"content_type": "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8",
+		"value":        `{"uri":"` + f.srv.URL + `/secrets/db-password"}`,
+	}
+}
+
+func vaultToken(context.Context) (string, error) { return "vault-t0ken", nil }
+
+func TestAzureAppConfigSecretClient(t *testing.T) {
+	f := newFakeAzure(t, map[string]string{
+		"key":           "api_token",
+		"value":         "t0ken",
+		"last_modified": "2024-03-01T10:00:00Z",
+	})
+	f.settings = append(f.settings, f.keyVaultRef())
+
+	c := f.newClient(t, azureappconfig.WithKeyVaultToken(vaultToken))
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"api_token": "t0ken", "db_password": "vault-s3cret"} {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+	if _, err := c.GetSecret(ctx, "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want ErrSecretNotFound", err)
+	}
+
+	reporter := c.(sm.LastModifiedReporter)
+	if modified, ok := reporter.LastModified("api_token"); !ok || !modified.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("LastModified() = %v, %v, want the setting modification time", modified, ok)
+	}
+	if _, ok := reporter.LastModified("db_password"); ok {
+		t.Error("LastModified() reported a setting without a modification time")
+	}
+}
+
+func TestAzureAppConfigSecretClientKeyVaultErrors(t *testing.T) {
+	f := newFakeAzure(t)
+
+	untrusted := f.keyVaultRef()
+	untrusted["value"] = `{"uri":"https://attacker.example.com/secrets/db-password"}`
+
+	tests := []struct {
+		name    string
+		setting map[string]string
+		opts    []azureappconfig.Option
+		wantErr error
+	}{
+		{name: "no token source", setting: f.keyVaultRef(), wantErr: azureappconfig.ErrKeyVaultTokenRequired},
+		{
+			name:    "untrusted vault",
+			setting: untrusted,
+			opts:    []azureappconfig.Option{azureappconfig.WithKeyVaultToken(vaultToken)},
+			wantErr: azureappconfig.ErrUntrustedKeyVault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f.settings = []map[string]string{tt.setting}
+
+			err := f.newClient(t, tt.opts...).LoadSecrets(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAzureAppConfigSecretClientFailedLoadKeepsCache(t *testing.T) {
+	f := newFakeAzure(t, map[string]string{"key": "api_token", "value": "t0ken"})
+	c := f.newClient(t)
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	f.status.Store(http.StatusServiceUnavailable)
+	err := c.LoadSecrets(ctx)
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("LoadSecrets() error = %v, want the status reported", err)
+	}
+
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "t0ken" {
+		t.Errorf("GetSecret() = %q, want the cache kept by the failed load", value)
+	}
+}
+
+func TestNewAzureAppConfigSecretClientInvalidConnectionString(t *testing.T) {
+	cfgs := &configs.Configs{AppConfigs: &configs.AppConfigs{}}
+
+	for _, connectionString := range []string{
+		"",
+		"Endpoint=https://store.azconfig.io;Id=key-id",
+		"Endpoint=https://store.azconfig.io;Id=key-id;Secret=not base64!",
+	} {
+		_, err := azureappconfig.NewAzureAppConfigSecretClient(cfgs, connectionString)
+		if !errors.Is(err, azureappconfig.ErrInvalidConnectionString) {
+			t.Errorf("NewAzureAppConfigSecretClient(%q) error = %v, want ErrInvalidConnectionString", connectionString, err)
+		}
+	}
+}
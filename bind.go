@@ -0,0 +1,208 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+const (
+	// secretTag is the struct tag naming the secret key bound to a field.
+	secretTag = "secret"
+
+	// requiredOption marks a tagged field whose secret must exist.
+	requiredOption = "required"
+)
+
+var (
+	// ErrInvalidBindTarget is returned when Bind receives something other than a
+	// non-nil pointer to a struct.
+	ErrInvalidBindTarget = errors.New("bind target must be a non-nil pointer to a struct")
+
+	// ErrAmbiguousKey is returned by Bind and Resolve with WithFuzzyKeys when several keys
+	// of the client match a tag once their case and separators are ignored.
+	ErrAmbiguousKey = errors.New("secret key matches several keys")
+)
+
+type (
+	// binder retrieves the secrets of Bind and Resolve, matching keys as configured.
+	binder struct {
+		client SecretClient
+		fuzzy  bool                // Whether keys are matched ignoring case and separators
+		index  map[string][]string // Client keys by folded form, built on first use
+	}
+
+	// BindOption configures optional behavior of Bind and Resolve.
+	BindOption func(*binder)
+)
+
+// WithFuzzyKeys matches tag keys with the client keys ignoring case and the '_', '-' and
+// '.' separators when no key matches exactly, so a `secret:"DBPassword"` tag binds the
+// db_password key. The client must implement Lister for the other naming styles to be
+// found. A tag matching several keys fails with ErrAmbiguousKey. Exact matching is the
+// default.
+func WithFuzzyKeys() BindOption {
+	return func(b *binder) {
+		b.fuzzy = true
+	}
+}
+
+// Bind maps individual secrets into the fields of the struct pointed to by out.
+//
+// Fields are bound through the `secret` tag holding the secret key, optionally followed by
+// the `required` option:
+//
+//	type DatabaseSecrets struct {
+//		Password string `secret:"db_password,required"`
+//		Replica  string `secret:"db_replica_password"`
+//	}
+//
+// Optional fields whose key doesn't exist keep their current value. Missing required
+// fields are all reported together in the returned error, which matches ErrSecretNotFound
+// through errors.Is. Tagged fields must be of kind string or []byte.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the values
+//   - out: A pointer to the struct to populate
+//   - opts: Optional settings such as WithFuzzyKeys
+//
+// Returns:
+//   - An error aggregating every missing required secret or invalid field
+func Bind(ctx context.Context, c SecretClient, out interface{}, opts ...BindOption) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return ErrInvalidBindTarget
+	}
+
+	b := newBinder(c, opts)
+
+	target = target.Elem()
+	targetType := target.Type()
+
+	var errs []error
+	for i := 0; i < targetType.NumField(); i++ {
+		field := targetType.Field(i)
+
+		tag, ok := field.Tag.Lookup(secretTag)
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+
+		key, required := parseSecretTag(tag)
+
+		if !field.IsExported() {
+			errs = append(errs, fmt.Errorf("field %s is unexported and cannot be bound", field.Name))
+			continue
+		}
+
+		value, err := b.get(ctx, key)
+		if errors.Is(err, ErrSecretNotFound) {
+			if required {
+				errs = append(errs, fmt.Errorf("field %s: required secret %q: %w", field.Name, key, err))
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %s: secret %q: %w", field.Name, key, err))
+			continue
+		}
+
+		if err := setSecretField(target.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w", field.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// newBinder creates a binder reading c with the given options.
+func newBinder(c SecretClient, opts []BindOption) *binder {
+	b := &binder{client: c}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// get retrieves key, falling back to the client key with the same folded form when key
+// doesn't exist and fuzzy matching is enabled.
+func (b *binder) get(ctx context.Context, key string) (string, error) {
+	value, err := b.client.GetSecret(ctx, key)
+	if !b.fuzzy || !errors.Is(err, ErrSecretNotFound) {
+		return value, err
+	}
+
+	if b.index == nil {
+		lister, ok := b.client.(Lister)
+		if !ok {
+			return "", err
+		}
+
+		keys, listErr := lister.ListSecrets(ctx)
+		if listErr != nil {
+			return "", fmt.Errorf("list secrets for fuzzy matching: %w", listErr)
+		}
+
+		b.index = make(map[string][]string, len(keys))
+		for _, k := range keys {
+			folded := foldKey(k)
+			b.index[folded] = append(b.index[folded], k)
+		}
+	}
+
+	switch matches := b.index[foldKey(key)]; len(matches) {
+	case 0:
+		return "", err
+	case 1:
+		return b.client.GetSecret(ctx, matches[0])
+	default:
+		return "", fmt.Errorf("%w: %s", ErrAmbiguousKey, strings.Join(matches, ", "))
+	}
+}
+
+// foldKey lowers key and drops everything but letters and digits, so DBPassword,
+// db_password and db-password share the same form.
+func foldKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+
+		return -1
+	}, key)
+}
+
+// parseSecretTag splits a `secret` tag into the secret key and whether it's required.
+func parseSecretTag(tag string) (key string, required bool) {
+	key, opts, _ := strings.Cut(tag, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if strings.TrimSpace(opt) == requiredOption {
+			required = true
+		}
+	}
+
+	return strings.TrimSpace(key), required
+}
+
+// setSecretField assigns a secret value to a string or []byte field.
+func setSecretField(field reflect.Value, value string) error {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		field.SetBytes([]byte(value))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
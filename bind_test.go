@@ -0,0 +1,176 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+type bindTarget struct {
+	Password string `secret:"db_password,required"`
+	Replica  string `secret:"db_replica_password"`
+	Cert     []byte `secret:"tls_cert"`
+	Ignored  string
+}
+
+func TestBind(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{
+		"db_password": "s3cret",
+		"tls_cert":    "CERTIFICATE",
+	})
+
+	out := bindTarget{Replica: "fallback", Ignored: "kept"}
+	if err := sm.Bind(context.Background(), c, &out); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+
+	want := bindTarget{Password: "s3cret", Replica: "fallback", Cert: []byte("CERTIFICATE"), Ignored: "kept"}
+	if out.Password != want.Password || out.Replica != want.Replica ||
+		string(out.Cert) != string(want.Cert) || out.Ignored != want.Ignored {
+		t.Errorf("Bind() = %+v, want %+v", out, want)
+	}
+}
+
+func TestBindMissingRequired(t *testing.T) {
+	type target struct {
+		Password string `secret:"db_password,required"`
+		Token    string `secret:"api_token, required"`
+		Replica  string `secret:"db_replica_password"`
+	}
+
+	var out target
+	err := sm.Bind(context.Background(), newLoadedClient(t, nil), &out)
+	if !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Fatalf("Bind() error = %v, want ErrSecretNotFound", err)
+	}
+
+	for _, key := range []string{"db_password", "api_token"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Bind() error %q doesn't report %s", err, key)
+		}
+	}
+	if strings.Contains(err.Error(), "db_replica_password") {
+		t.Errorf("Bind() error %q reports an optional field", err)
+	}
+}
+
+func TestBindInvalidTarget(t *testing.T) {
+	c := newLoadedClient(t, nil)
+
+	for name, out := range map[string]interface{}{
+		"nil":            nil,
+		"struct value":   bindTarget{},
+		"nil pointer":    (*bindTarget)(nil),
+		"non-struct ptr": new(string),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if err := sm.Bind(context.Background(), c, out); !errors.Is(err, sm.ErrInvalidBindTarget) {
+				t.Errorf("Bind() error = %v, want ErrInvalidBindTarget", err)
+			}
+		})
+	}
+}
+
+func TestBindUnsupportedField(t *testing.T) {
+	type target struct {
+		Port int `secret:"db_port"`
+	}
+
+	var out target
+	err := sm.Bind(context.Background(), newLoadedClient(t, map[string]string{"db_port": "5432"}), &out)
+	if err == nil || !strings.Contains(err.Error(), "Port") {
+		t.Errorf("Bind() error = %v, want the unsupported field reported", err)
+	}
+}
+
+// listingClient is a mapClient also listing its keys, as fuzzy matching requires.
+type listingClient struct {
+	mapClient
+}
+
+func (l *listingClient) ListSecrets(context.Context) ([]string, error) {
+	keys := make([]string, 0, len(l.values))
+	for key := range l.values {
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func TestBindFuzzyKeys(t *testing.T) {
+	type target struct {
+		Password string `secret:"DBPassword,required"`
+		Token    string `secret:"api-token"`
+		Region   string `secret:"AWS.Region"`
+		Exact    string `secret:"exact_key"`
+	}
+
+	c := &listingClient{mapClient{values: map[string]string{
+		"db_password": "s3cret",
+		"API_TOKEN":   "t0ken",
+		"aws_region":  "eu-west-1",
+		"exact_key":   "exact",
+		"ExactKey":    "ambiguous but unused",
+	}}}
+
+	var out target
+	if err := sm.Bind(context.Background(), c, &out, sm.WithFuzzyKeys()); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+
+	want := target{Password: "s3cret", Token: "t0ken", Region: "eu-west-1", Exact: "exact"}
+	if out != want {
+		t.Errorf("Bind() = %+v, want %+v", out, want)
+	}
+}
+
+func TestBindFuzzyKeysDisabledByDefault(t *testing.T) {
+	type target struct {
+		Password string `secret:"DBPassword,required"`
+	}
+
+	c := &listingClient{mapClient{values: map[string]string{"db_password": "s3cret"}}}
+
+	var out target
+	if err := sm.Bind(context.Background(), c, &out); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("Bind() error = %v, want exact matching by default", err)
+	}
+}
+
+func TestBindFuzzyKeysAmbiguous(t *testing.T) {
+	type target struct {
+		Password string `secret:"DBPassword"`
+	}
+
+	c := &listingClient{mapClient{values: map[string]string{"db_password": "a", "db-password": "b"}}}
+
+	var out target
+	if err := sm.Bind(context.Background(), c, &out, sm.WithFuzzyKeys()); !errors.Is(err, sm.ErrAmbiguousKey) {
+		t.Errorf("Bind() error = %v, want ErrAmbiguousKey", err)
+	}
+}
+
+func TestResolveFuzzyKeys(t *testing.T) {
+	type target struct {
+		Database struct {
+			Password string `secret:"dbPassword"`
+		}
+	}
+
+	c := &listingClient{mapClient{values: map[string]string{"DB_PASSWORD": "s3cret"}}}
+
+	var out target
+	if err := sm.Resolve(context.Background(), c, &out, sm.WithFuzzyKeys()); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if out.Database.Password != "s3cret" {
+		t.Errorf("Resolve() = %+v, want the nested field matched across naming styles", out)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+type (
+	// CacheStore holds the secrets cached by a provider. The default MemoryStore keeps them
+	// in process; other implementations can back the cache with a shared store, such as
+	// Redis, so several processes share a warmed cache. Implementations must be safe for
+	// concurrent use and, since they hold plaintext values, protect them accordingly.
+	CacheStore interface {
+		// Get returns the value cached under key and whether it exists.
+		Get(ctx context.Context, key string) (string, bool, error)
+
+		// SetAll replaces the whole content of the store with secrets.
+		SetAll(ctx context.Context, secrets map[string]string) error
+
+		// Keys returns the cached keys, in any order.
+		Keys(ctx context.Context) ([]string, error)
+	}
+
+	// MemoryStore is the default in-memory CacheStore. Its values are redacted when the
+	// store is formatted, e.g. with %v.
+	MemoryStore struct {
+		mu      sync.RWMutex
+		secrets map[string]redact.String
+	}
+)
+
+// NewMemoryStore creates an empty in-memory CacheStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{secrets: make(map[string]redact.String)}
+}
+
+// Get returns the value cached under key and whether it exists. It never fails.
+func (m *MemoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.secrets[key]
+	return value.Reveal(), ok, nil
+}
+
+// SetAll replaces the content of the store with a copy of secrets. It never fails.
+func (m *MemoryStore) SetAll(_ context.Context, secrets map[string]string) error {
+	cloned := redact.Map(secrets)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.secrets = cloned
+	return nil
+}
+
+// Keys returns the sorted cached keys. It never fails.
+func (m *MemoryStore) Keys(_ context.Context) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(m.secrets)), nil
+}
+
+// StoreSnapshot returns every key-value pair of store.
+//
+// Parameters:
+//   - ctx: Context forwarded to the store
+//   - store: The store to read
+//
+// Returns:
+//   - A map holding the content of the store
+//   - An error if the store cannot be read
+func StoreSnapshot(ctx context.Context, store CacheStore) (map[string]string, error) {
+	keys, err := store.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, ok, err := store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keys removed concurrently by another process are skipped
+		if ok {
+			secrets[key] = value
+		}
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// sharedStore is a CacheStore standing for a shared store, such as Redis, which can
+// fail and lose keys between Keys and Get.
+type sharedStore struct {
+	values  map[string]string
+	ghost   string // Key listed by Keys but missing from Get
+	failGet error
+}
+
+func (s *sharedStore) Get(_ context.Context, key string) (string, bool, error) {
+	if s.failGet != nil {
+		return "", false, s.failGet
+	}
+
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+func (s *sharedStore) SetAll(_ context.Context, secrets map[string]string) error {
+	s.values = maps.Clone(secrets)
+	return nil
+}
+
+func (s *sharedStore) Keys(context.Context) ([]string, error) {
+	keys := slices.Collect(maps.Keys(s.values))
+	if s.ghost != "" {
+		keys = append(keys, s.ghost)
+	}
+
+	return keys, nil
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := sm.NewMemoryStore()
+
+	secrets := map[string]string{"b": "2", "a": "1"}
+	if err := store.SetAll(ctx, secrets); err != nil {
+		t.Fatal(err)
+	}
+
+	// The store keeps its own copy
+	secrets["a"] = "changed"
+
+	if value, ok, _ := store.Get(ctx, "a"); !ok || value != "1" {
+		t.Errorf("Get(a) = %q, %v, want 1", value, ok)
+	}
+	if _, ok, _ := store.Get(ctx, "missing"); ok {
+		t.Error("Get(missing) found a value")
+	}
+	if keys, _ := store.Keys(ctx); !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("Keys() = %v, want sorted keys", keys)
+	}
+
+	_ = store.SetAll(ctx, map[string]string{"c": "s3cret"})
+	if _, ok, _ := store.Get(ctx, "a"); ok {
+		t.Error("Get(a) found a value replaced by SetAll")
+	}
+	if out := fmt.Sprintf("%v %+v", store, store); strings.Contains(out, "s3cret") {
+		t.Errorf("formatted store %s exposes a value", out)
+	}
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	ctx := context.Background()
+	secrets := map[string]string{"a": "1", "b": "2"}
+
+	for name, store := range map[string]sm.CacheStore{
+		"memory": sm.NewMemoryStore(),
+		"shared": &sharedStore{ghost: "evicted"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_ = store.SetAll(ctx, secrets)
+
+			got, err := sm.StoreSnapshot(ctx, store)
+			if err != nil {
+				t.Fatalf("StoreSnapshot() error = %v", err)
+			}
+			if !maps.Equal(got, secrets) {
+				t.Errorf("StoreSnapshot() = %v, want %v", got, secrets)
+			}
+		})
+	}
+}
+
+func TestStoreSnapshotFailure(t *testing.T) {
+	errStore := errors.New("store unavailable")
+	store := &sharedStore{values: map[string]string{"a": "1"}, failGet: errStore}
+
+	if _, err := sm.StoreSnapshot(context.Background(), store); !errors.Is(err, errStore) {
+		t.Errorf("StoreSnapshot() error = %v, want the store failure", err)
+	}
+}
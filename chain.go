@@ -0,0 +1,35 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+// Middleware decorates a SecretClient, such as NewCoalescingClient or a closure around
+// NewAuditClient.
+type Middleware func(SecretClient) SecretClient
+
+// Chain wraps base with the given middlewares, the first one being the outermost: with
+// Chain(base, a, b), a GetSecret call goes through a, then b, then reaches base. Nil
+// middlewares are skipped.
+//
+//	client := secretsmanager.Chain(base,
+//		func(c secretsmanager.SecretClient) secretsmanager.SecretClient { return secretsmanager.NewAuditClient(c, sink) },
+//		secretsmanager.NewCoalescingClient,
+//	)
+//
+// Parameters:
+//   - base: The client holding the secrets
+//   - mws: The middlewares, from the outermost to the innermost
+//
+// Returns:
+//   - The decorated client, or base when no middleware is given
+func Chain(base SecretClient, mws ...Middleware) SecretClient {
+	c := base
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			c = mws[i](c)
+		}
+	}
+
+	return c
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// tracingClient records its name in a shared trace before delegating each lookup.
+type tracingClient struct {
+	sm.SecretClient
+	name  string
+	trace *[]string
+}
+
+func tracing(name string, trace *[]string) sm.Middleware {
+	return func(c sm.SecretClient) sm.SecretClient {
+		return &tracingClient{SecretClient: c, name: name, trace: trace}
+	}
+}
+
+func (c *tracingClient) GetSecret(ctx context.Context, key string) (string, error) {
+	*c.trace = append(*c.trace, c.name)
+	return c.SecretClient.GetSecret(ctx, key)
+}
+
+func TestChainOrder(t *testing.T) {
+	var trace []string
+	base := newLoadedClient(t, map[string]string{"api_token": "t0ken"})
+
+	c := sm.Chain(base, tracing("outer", &trace), nil, tracing("inner", &trace))
+
+	if value, err := c.GetSecret(context.Background(), "api_token"); err != nil || value != "t0ken" {
+		t.Fatalf("GetSecret() = %q, %v, want the base value", value, err)
+	}
+	if !slices.Equal(trace, []string{"outer", "inner"}) {
+		t.Errorf("trace = %v, want the first middleware outermost", trace)
+	}
+}
+
+func TestChainWithoutMiddleware(t *testing.T) {
+	base := newLoadedClient(t, nil)
+
+	if c := sm.Chain(base); c != sm.SecretClient(base) {
+		t.Errorf("Chain() = %v, want base returned as is", c)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"sort"
+)
+
+const (
+	// ChangeAdded reports a key that didn't exist before.
+	ChangeAdded ChangeType = "added"
+
+	// ChangeChanged reports a key whose value changed.
+	ChangeChanged ChangeType = "changed"
+
+	// ChangeRemoved reports a key that no longer exists.
+	ChangeRemoved ChangeType = "removed"
+)
+
+type (
+	// ChangeType classifies how a key changed between two versions of the secrets.
+	ChangeType string
+
+	// ChangeEvent describes a key that changed between two versions of the secrets. It
+	// never carries the old or new value.
+	ChangeEvent struct {
+		Key  string
+		Type ChangeType
+	}
+
+	// Refresher is implemented by providers able to reload their secrets and report which
+	// keys changed, for rotation handling and change notifications.
+	Refresher interface {
+		// Refresh reloads the secrets and returns the changes from the previous cache.
+		Refresh(ctx context.Context) ([]ChangeEvent, error)
+	}
+)
+
+// DiffSecrets compares two versions of the secrets and returns the keys that were added,
+// changed or removed, sorted by key. Keys holding the same value in both versions aren't
+// reported. Values are compared through their SHA-256 hashes and never appear in the
+// events, so the result is safe to log when debugging rotations.
+//
+// Parameters:
+//   - previous: The secrets before the change
+//   - current: The secrets after the change
+//
+// Returns:
+//   - The changed keys, empty when both versions hold the same secrets
+func DiffSecrets(previous, current map[string]string) []ChangeEvent {
+	var events []ChangeEvent
+
+	for key, value := range current {
+		old, ok := previous[key]
+		switch {
+		case !ok:
+			events = append(events, ChangeEvent{Key: key, Type: ChangeAdded})
+		case sha256.Sum256([]byte(old)) != sha256.Sum256([]byte(value)):
+			events = append(events, ChangeEvent{Key: key, Type: ChangeChanged})
+		}
+	}
+
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			events = append(events, ChangeEvent{Key: key, Type: ChangeRemoved})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+
+	return events
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestDiffSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous map[string]string
+		current  map[string]string
+		want     []sm.ChangeEvent
+	}{
+		{
+			name:    "added",
+			current: map[string]string{"a": "1"},
+			want:    []sm.ChangeEvent{{Key: "a", Type: sm.ChangeAdded}},
+		},
+		{
+			name:     "removed",
+			previous: map[string]string{"a": "1"},
+			want:     []sm.ChangeEvent{{Key: "a", Type: sm.ChangeRemoved}},
+		},
+		{
+			name:     "changed",
+			previous: map[string]string{"a": "1"},
+			current:  map[string]string{"a": "2"},
+			want:     []sm.ChangeEvent{{Key: "a", Type: sm.ChangeChanged}},
+		},
+		{
+			name:     "unchanged",
+			previous: map[string]string{"a": "1"},
+			current:  map[string]string{"a": "1"},
+		},
+		{
+			name:     "mixed and sorted",
+			previous: map[string]string{"c": "old", "b": "same", "d": "gone"},
+			current:  map[string]string{"c": "new", "b": "same", "a": "fresh"},
+			want: []sm.ChangeEvent{
+				{Key: "a", Type: sm.ChangeAdded},
+				{Key: "c", Type: sm.ChangeChanged},
+				{Key: "d", Type: sm.ChangeRemoved},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sm.DiffSecrets(tt.previous, tt.current)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffSecrets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffSecretsNeverExposesValues(t *testing.T) {
+	events := sm.DiffSecrets(map[string]string{"key": "old-s3cret"}, map[string]string{"key": "new-s3cret"})
+
+	if out := fmt.Sprintf("%+v", events); strings.Contains(out, "s3cret") {
+		t.Errorf("events %s expose a value", out)
+	}
+}
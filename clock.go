@@ -0,0 +1,23 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import "time"
+
+type (
+	// Clock abstracts the current time so time-based behavior, such as staleness or
+	// expiry checks, can be tested deterministically.
+	Clock interface {
+		Now() time.Time
+	}
+
+	// systemClock is the Clock reading the system time.
+	systemClock struct{}
+)
+
+// SystemClock is the default Clock, reading the system time.
+var SystemClock Clock = systemClock{}
+
+func (systemClock) Now() time.Time { return time.Now() }
@@ -0,0 +1,51 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// coalescingClient decorates a SecretClient so concurrent GetSecret calls for the same
+// key share a single call to the wrapped client.
+type coalescingClient struct {
+	SecretClient
+	group singleflight.Group
+}
+
+// NewCoalescingClient wraps c so that concurrent GetSecret calls for the same key are
+// coalesced into one call to c. Wrapping a LazyClient, a burst of lookups on a cold key
+// then results in a single backend fetch instead of one per caller.
+//
+// The context of the first caller is used for the shared call, so its cancellation is
+// observed by every caller waiting on it.
+//
+// Parameters:
+//   - c: The secret client whose lookups are coalesced
+//
+// Returns:
+//   - A SecretClient coalescing concurrent lookups
+func NewCoalescingClient(c SecretClient) SecretClient {
+	return &coalescingClient{SecretClient: c}
+}
+
+// GetSecret retrieves key through the wrapped client, sharing in-flight calls.
+func (c *coalescingClient) GetSecret(ctx context.Context, key string) (string, error) {
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		return c.SecretClient.GetSecret(ctx, key)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil
+}
+
+// Reload forwards the reload to the wrapped client.
+func (c *coalescingClient) Reload(ctx context.Context) error {
+	return reload(ctx, c.SecretClient)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// TestCoalescingClientSharesColdFetch is meant to run with -race.
+func TestCoalescingClientSharesColdFetch(t *testing.T) {
+	const callers = 50
+
+	var fetches atomic.Int32
+	release := make(chan struct{})
+
+	lazy := sm.NewLazyClient(func(context.Context, string) (string, error) {
+		fetches.Add(1)
+		<-release
+		return "s3cret", nil
+	})
+	c := sm.NewCoalescingClient(lazy)
+
+	var started, done sync.WaitGroup
+	values := make([]string, callers)
+	errs := make([]error, callers)
+
+	for i := range callers {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			values[i], errs[i] = c.GetSecret(context.Background(), "db_password")
+		}()
+	}
+
+	// Give every caller time to join the in-flight fetch before it completes
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	if got := fetches.Load(); got != 1 {
+		t.Errorf("backend fetches = %d, want 1", got)
+	}
+	for i := range callers {
+		if errs[i] != nil || values[i] != "s3cret" {
+			t.Errorf("GetSecret() = %q, %v, want s3cret", values[i], errs[i])
+		}
+	}
+}
+
+func TestCoalescingClientSequentialCalls(t *testing.T) {
+	backend := newCountingFetch(map[string]string{"api_token": "t0ken"})
+	c := sm.NewCoalescingClient(sm.NewLazyClient(backend.Fetch, sm.WithNoCache()))
+
+	for range 3 {
+		if value, _ := c.GetSecret(context.Background(), "api_token"); value != "t0ken" {
+			t.Errorf("GetSecret() = %q, want t0ken", value)
+		}
+	}
+
+	if got := backend.Calls("api_token"); got != 3 {
+		t.Errorf("fetches = %d, want calls that don't overlap left uncoalesced", got)
+	}
+}
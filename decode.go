@@ -0,0 +1,87 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type (
+	// DecodeFunc parses a raw secret value into out, a pointer to the destination value.
+	DecodeFunc func(value string, out interface{}) error
+
+	// DecodingClient decorates a SecretClient with per-key decoders used by GetSecretInto,
+	// for values with bespoke encodings such as "host=db;port=5432" pairs that JSON
+	// decoding cannot handle.
+	DecodingClient struct {
+		SecretClient
+
+		mu       sync.RWMutex
+		decoders map[string]DecodeFunc
+	}
+)
+
+// NewDecodingClient wraps the given client with an empty decoder registry.
+//
+// Parameters:
+//   - c: The secret client whose values are decoded
+//
+// Returns:
+//   - A DecodingClient delegating to c
+func NewDecodingClient(c SecretClient) *DecodingClient {
+	return &DecodingClient{
+		SecretClient: c,
+		decoders:     make(map[string]DecodeFunc),
+	}
+}
+
+// RegisterDecoder registers fn to decode the value of key in GetSecretInto, replacing any
+// decoder previously registered for it.
+func (d *DecodingClient) RegisterDecoder(key string, fn DecodeFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.decoders[key] = fn
+}
+
+// GetSecretInto retrieves the value of key and decodes it into out with the decoder
+// registered for key, or as JSON like GetSecretJSON when none is registered. Decoder
+// errors name the key but should never include the value.
+//
+// Parameters:
+//   - ctx: Context forwarded to the wrapped client's GetSecret
+//   - key: The secret key to look up
+//   - out: A pointer to the value the secret is decoded into
+//
+// Returns:
+//   - An error if the secret cannot be retrieved or decoded
+func (d *DecodingClient) GetSecretInto(ctx context.Context, key string, out interface{}) error {
+	d.mu.RLock()
+	decode, ok := d.decoders[key]
+	d.mu.RUnlock()
+
+	if !ok {
+		return GetSecretJSON(ctx, d.SecretClient, key, out)
+	}
+
+	value, err := d.SecretClient.GetSecret(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if err := decode(value, out); err != nil {
+		return fmt.Errorf("decode secret %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// Reload forwards to the wrapped client when it implements Reloadable, and loads the
+// secrets again otherwise.
+func (d *DecodingClient) Reload(ctx context.Context) error {
+	return reload(ctx, d.SecretClient)
+}
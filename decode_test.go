@@ -0,0 +1,93 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+type endpoint struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// decodeEndpoint parses "host=db;port=5432" pairs into an *endpoint.
+func decodeEndpoint(value string, out interface{}) error {
+	e, ok := out.(*endpoint)
+	if !ok {
+		return fmt.Errorf("unsupported destination %T", out)
+	}
+
+	for _, pair := range strings.Split(value, ";") {
+		name, field, found := strings.Cut(pair, "=")
+		if !found {
+			return errors.New("malformed pair")
+		}
+
+		switch name {
+		case "host":
+			e.Host = field
+		case "port":
+			port, err := strconv.Atoi(field)
+			if err != nil {
+				return errors.New("port is not a number")
+			}
+			e.Port = port
+		}
+	}
+
+	return nil
+}
+
+func TestDecodingClientCustomDecoder(t *testing.T) {
+	c := sm.NewDecodingClient(newLoadedClient(t, map[string]string{
+		"primary": "host=db;port=5432",
+		"replica": `{"host":"replica","port":5433}`,
+	}))
+	c.RegisterDecoder("primary", decodeEndpoint)
+
+	ctx := context.Background()
+
+	var primary endpoint
+	if err := c.GetSecretInto(ctx, "primary", &primary); err != nil {
+		t.Fatalf("GetSecretInto(primary) error = %v", err)
+	}
+	if primary != (endpoint{Host: "db", Port: 5432}) {
+		t.Errorf("GetSecretInto(primary) = %+v, want the custom decoding", primary)
+	}
+
+	var replica endpoint
+	if err := c.GetSecretInto(ctx, "replica", &replica); err != nil {
+		t.Fatalf("GetSecretInto(replica) error = %v", err)
+	}
+	if replica != (endpoint{Host: "replica", Port: 5433}) {
+		t.Errorf("GetSecretInto(replica) = %+v, want JSON decoding without a decoder", replica)
+	}
+}
+
+func TestDecodingClientDecoderError(t *testing.T) {
+	c := sm.NewDecodingClient(newLoadedClient(t, map[string]string{"primary": "host=db;port=s3cret"}))
+	c.RegisterDecoder("primary", decodeEndpoint)
+
+	var out endpoint
+	err := c.GetSecretInto(context.Background(), "primary", &out)
+	if err == nil {
+		t.Fatal("GetSecretInto() error = nil, want the decoder failure")
+	}
+	if !strings.Contains(err.Error(), "primary") || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error %q should name the key without the value", err)
+	}
+
+	if err := c.GetSecretInto(context.Background(), "missing", &out); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecretInto() error = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+type (
+	// DeprecationWarnFunc is called when a deprecated key is accessed, with the deprecated
+	// key and its replacement. It never receives the secret value.
+	DeprecationWarnFunc func(ctx context.Context, key, replacement string)
+
+	// DeprecationClient decorates a SecretClient, warning when deprecated keys are read so
+	// the callers still using them can be found during a key migration.
+	DeprecationClient struct {
+		SecretClient
+		warn DeprecationWarnFunc
+
+		mu         sync.Mutex
+		deprecated map[string]string   // Replacement of each deprecated key
+		warned     map[string]struct{} // Deprecated keys already reported
+	}
+)
+
+// NewDeprecationClient wraps c, calling warn on the first access to each deprecated key.
+// A typical warn function logs the keys through the application logger:
+//
+//	client := secretsmanager.NewDeprecationClient(c, func(_ context.Context, key, replacement string) {
+//		logger.Warn("deprecated secret key", zap.String("key", key), zap.String("replacement", replacement))
+//	})
+//
+// Parameters:
+//   - c: The secret client holding the values
+//   - warn: The function reporting deprecated accesses
+//
+// Returns:
+//   - A DeprecationClient delegating to c
+func NewDeprecationClient(c SecretClient, warn DeprecationWarnFunc) *DeprecationClient {
+	return &DeprecationClient{
+		SecretClient: c,
+		warn:         warn,
+		deprecated:   make(map[string]string),
+		warned:       make(map[string]struct{}),
+	}
+}
+
+// DeprecateKey marks key as deprecated in favor of replacement. Reading key still returns
+// its value; once key no longer exists in the provider, it resolves to the value of
+// replacement instead, so callers keep working after the migration. An empty replacement
+// only warns.
+func (d *DeprecationClient) DeprecateKey(key, replacement string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deprecated[key] = replacement
+	delete(d.warned, key)
+}
+
+// GetSecret retrieves key from the wrapped client, warning once per process when it's
+// deprecated and falling back to its replacement when it doesn't exist.
+func (d *DeprecationClient) GetSecret(ctx context.Context, key string) (string, error) {
+	d.mu.Lock()
+	replacement, deprecated := d.deprecated[key]
+	_, warned := d.warned[key]
+	if deprecated && !warned {
+		d.warned[key] = struct{}{}
+	}
+	d.mu.Unlock()
+
+	if deprecated && !warned && d.warn != nil {
+		d.warn(ctx, key, replacement)
+	}
+
+	value, err := d.SecretClient.GetSecret(ctx, key)
+	if deprecated && replacement != "" && errors.Is(err, ErrSecretNotFound) {
+		return d.SecretClient.GetSecret(ctx, replacement)
+	}
+
+	return value, err
+}
+
+// Reload forwards to the wrapped client when it implements Reloadable, and loads the
+// secrets again otherwise.
+func (d *DeprecationClient) Reload(ctx context.Context) error {
+	return reload(ctx, d.SecretClient)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// warnRecorder records the deprecation warnings it receives.
+type warnRecorder struct {
+	warnings []string
+}
+
+func (w *warnRecorder) Warn(_ context.Context, key, replacement string) {
+	w.warnings = append(w.warnings, key+"->"+replacement)
+}
+
+func TestDeprecationClientWarnsOnce(t *testing.T) {
+	ctx := context.Background()
+	warns := &warnRecorder{}
+
+	c := sm.NewDeprecationClient(newLoadedClient(t, map[string]string{
+		"db_pass":     "old",
+		"db_password": "new",
+	}), warns.Warn)
+	c.DeprecateKey("db_pass", "db_password")
+
+	for range 3 {
+		if value, _ := c.GetSecret(ctx, "db_pass"); value != "old" {
+			t.Errorf("GetSecret(db_pass) = %q, want the deprecated key still served", value)
+		}
+	}
+	_, _ = c.GetSecret(ctx, "db_password")
+
+	if !slices.Equal(warns.warnings, []string{"db_pass->db_password"}) {
+		t.Errorf("warnings = %v, want a single warning for db_pass", warns.warnings)
+	}
+}
+
+func TestDeprecationClientResolvesReplacement(t *testing.T) {
+	ctx := context.Background()
+	warns := &warnRecorder{}
+
+	c := sm.NewDeprecationClient(newLoadedClient(t, map[string]string{"db_password": "new"}), warns.Warn)
+	c.DeprecateKey("db_pass", "db_password")
+	c.DeprecateKey("legacy_token", "")
+
+	if value, err := c.GetSecret(ctx, "db_pass"); err != nil || value != "new" {
+		t.Errorf("GetSecret(db_pass) = %q, %v, want the replacement value", value, err)
+	}
+	if _, err := c.GetSecret(ctx, "legacy_token"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret(legacy_token) error = %v, want ErrSecretNotFound without replacement", err)
+	}
+
+	want := []string{"db_pass->db_password", "legacy_token->"}
+	if !slices.Equal(warns.warnings, want) {
+		t.Errorf("warnings = %v, want %v", warns.warnings, want)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// ExportToEnv sets process environment variables from secrets, for third-party libraries
+// that only read their configuration from the environment.
+//
+// Security caveat: environment variables are inherited by every subprocess and can be
+// read by tools inspecting the process (e.g. /proc/<pid>/environ), so prefer passing
+// secrets explicitly and call the returned restore function as soon as they're consumed.
+//
+// Every secret is retrieved before any variable is set, so a missing secret leaves the
+// environment untouched.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the values
+//   - mapping: Environment variable names indexed by secret key
+//
+// Returns:
+//   - A function restoring the previous value of every variable set, or unsetting those
+//     that didn't exist
+//   - An error if a secret cannot be retrieved or a variable cannot be set
+func ExportToEnv(ctx context.Context, c SecretClient, mapping map[string]string) (func(), error) {
+	values := make(map[string]string, len(mapping))
+	for key, name := range mapping {
+		value, err := c.GetSecret(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("export secret %q to %s: %w", key, name, err)
+		}
+
+		values[name] = value
+	}
+
+	type previous struct {
+		value  string
+		exists bool
+	}
+
+	saved := make(map[string]previous, len(values))
+	restore := func() {
+		for name, prev := range saved {
+			if prev.exists {
+				_ = os.Setenv(name, prev.value)
+			} else {
+				_ = os.Unsetenv(name)
+			}
+		}
+	}
+
+	for name, value := range values {
+		prevValue, exists := os.LookupEnv(name)
+		saved[name] = previous{value: prevValue, exists: exists}
+
+		if err := os.Setenv(name, value); err != nil {
+			restore()
+			return nil, fmt.Errorf("set environment variable %s: %w", name, err)
+		}
+	}
+
+	return restore, nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestExportToEnv(t *testing.T) {
+	// t.Setenv restores both variables once the test ends, whatever ExportToEnv does
+	t.Setenv("SM_TEST_DB_PASSWORD", "previous")
+	t.Setenv("SM_TEST_API_TOKEN", "")
+	os.Unsetenv("SM_TEST_API_TOKEN")
+
+	c := newLoadedClient(t, map[string]string{"db_password": "s3cret", "api_token": "t0ken"})
+
+	restore, err := sm.ExportToEnv(context.Background(), c, map[string]string{
+		"db_password": "SM_TEST_DB_PASSWORD",
+		"api_token":   "SM_TEST_API_TOKEN",
+	})
+	if err != nil {
+		t.Fatalf("ExportToEnv() error = %v", err)
+	}
+
+	if got := os.Getenv("SM_TEST_DB_PASSWORD"); got != "s3cret" {
+		t.Errorf("SM_TEST_DB_PASSWORD = %q, want the secret", got)
+	}
+	if got := os.Getenv("SM_TEST_API_TOKEN"); got != "t0ken" {
+		t.Errorf("SM_TEST_API_TOKEN = %q, want the secret", got)
+	}
+
+	restore()
+
+	if got := os.Getenv("SM_TEST_DB_PASSWORD"); got != "previous" {
+		t.Errorf("SM_TEST_DB_PASSWORD = %q after restore, want the previous value", got)
+	}
+	if _, ok := os.LookupEnv("SM_TEST_API_TOKEN"); ok {
+		t.Error("SM_TEST_API_TOKEN still set after restore, want it unset")
+	}
+}
+
+func TestExportToEnvMissingSecret(t *testing.T) {
+	t.Setenv("SM_TEST_DB_PASSWORD", "previous")
+
+	c := newLoadedClient(t, map[string]string{"db_password": "s3cret"})
+
+	_, err := sm.ExportToEnv(context.Background(), c, map[string]string{
+		"db_password": "SM_TEST_DB_PASSWORD",
+		"missing":     "SM_TEST_MISSING",
+	})
+	if !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Fatalf("ExportToEnv() error = %v, want ErrSecretNotFound", err)
+	}
+
+	if got := os.Getenv("SM_TEST_DB_PASSWORD"); got != "previous" {
+		t.Errorf("SM_TEST_DB_PASSWORD = %q, want the environment left untouched", got)
+	}
+}
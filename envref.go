@@ -0,0 +1,94 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+// referenceSchemes lists the URI schemes recognized as secret references, following the
+// Berglas convention.
+var referenceSchemes = []string{"sm://", "berglas://"}
+
+// envReferenceClient is a SecretClient reading environment variables whose values may be
+// references to secrets stored elsewhere.
+type envReferenceClient struct {
+	resolve FetchFunc
+
+	mu       sync.RWMutex
+	resolved map[string]redact.String // Resolved values indexed by reference
+}
+
+// NewEnvReferenceClient creates a client serving environment variables, resolving the
+// ones holding a Berglas-style reference (`sm://project/secret` or `berglas://bucket/object`).
+//
+// GetSecret("X") returns the value of the environment variable X as is when it's a
+// literal. When it's a reference, the full reference is passed to resolve, typically
+// backed by the Google Secret Manager or Cloud Storage client, and the result is cached.
+// Unset variables are reported as ErrSecretNotFound.
+//
+// Parameters:
+//   - resolve: Function retrieving the secret a reference points to
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the environment
+func NewEnvReferenceClient(resolve FetchFunc) SecretClient {
+	return &envReferenceClient{
+		resolve:  resolve,
+		resolved: make(map[string]redact.String),
+	}
+}
+
+// LoadSecrets does nothing since references are resolved on first access.
+func (c *envReferenceClient) LoadSecrets(_ context.Context) error {
+	return nil
+}
+
+// GetSecret returns the value of the environment variable key, resolving references.
+func (c *envReferenceClient) GetSecret(ctx context.Context, key string) (string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+
+	if !IsSecretReference(value) {
+		return value, nil
+	}
+
+	c.mu.RLock()
+	cached, ok := c.resolved[value]
+	c.mu.RUnlock()
+
+	if ok {
+		return cached.Reveal(), nil
+	}
+
+	resolved, err := c.resolve(ctx, value)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	c.resolved[value] = redact.New(resolved)
+	c.mu.Unlock()
+
+	return resolved, nil
+}
+
+// IsSecretReference reports whether value is a Berglas-style secret reference.
+func IsSecretReference(value string) bool {
+	for _, scheme := range referenceSchemes {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestEnvReferenceClient(t *testing.T) {
+	backend := newCountingFetch(map[string]string{
+		"sm://project/db-password":   "s3cret",
+		"berglas://bucket/api-token": "t0ken",
+	})
+
+	t.Setenv("DB_PASSWORD", "sm://project/db-password")
+	t.Setenv("API_TOKEN", "berglas://bucket/api-token")
+	t.Setenv("LOG_LEVEL", "debug")
+
+	c := sm.NewEnvReferenceClient(backend.Fetch)
+	ctx := context.Background()
+
+	tests := map[string]string{
+		"DB_PASSWORD": "s3cret",
+		"API_TOKEN":   "t0ken",
+		"LOG_LEVEL":   "debug",
+	}
+	for key, want := range tests {
+		for range 2 {
+			if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+				t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+			}
+		}
+	}
+
+	if got := backend.Calls("sm://project/db-password"); got != 1 {
+		t.Errorf("resolutions of a reference = %d, want it cached after the first", got)
+	}
+	if got := backend.Calls("debug"); got != 0 {
+		t.Errorf("resolutions of a literal = %d, want none", got)
+	}
+}
+
+func TestEnvReferenceClientErrors(t *testing.T) {
+	t.Setenv("MISSING_REF", "sm://project/missing")
+
+	c := sm.NewEnvReferenceClient(newCountingFetch(nil).Fetch)
+	ctx := context.Background()
+
+	if _, err := c.GetSecret(ctx, "SECRETS_MANAGER_UNSET_VARIABLE"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v for an unset variable, want ErrSecretNotFound", err)
+	}
+	if _, err := c.GetSecret(ctx, "MISSING_REF"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v for a dangling reference, want ErrSecretNotFound", err)
+	}
+}
+
+func TestIsSecretReference(t *testing.T) {
+	tests := map[string]bool{
+		"sm://project/secret":     true,
+		"berglas://bucket/object": true,
+		"https://example.com":     false,
+		"plain":                   false,
+		"":                        false,
+	}
+
+	for value, want := range tests {
+		if got := sm.IsSecretReference(value); got != want {
+			t.Errorf("IsSecretReference(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import "errors"
+
+var (
+	// ErrSecretNotFound is returned by GetSecret when the requested key does not
+	// exist in the provider. Callers should compare against it using errors.Is.
+	ErrSecretNotFound = errors.New("secret was not found")
+
+	// ErrSecretsNotLoaded is returned by GetSecret when the secrets haven't been loaded yet,
+	// or the cache was cleared with ResetCache, instead of reporting every key as missing.
+	ErrSecretsNotLoaded = errors.New("secrets were not loaded")
+
+	// ErrConflict is returned by conditional writes when the secret was changed since the
+	// expected version was read.
+	ErrConflict = errors.New("secret was modified concurrently")
+
+	// ErrAccessDenied is returned by GetSecret when the client isn't allowed to read the
+	// requested key, even if it exists, so it can be told apart from ErrSecretNotFound.
+	ErrAccessDenied = errors.New("secret access denied")
+)
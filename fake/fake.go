@@ -0,0 +1,178 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package fake provides a deterministic in-memory SecretClient for tests. It separates
+// the simulated backend from the client cache, so tests can rotate values, inject load
+// failures and add latency to exercise refresh and error handling paths.
+package fake
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sync"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+var (
+	// ErrInjectedFailure is the default error returned by a load failing after FailNextLoad.
+	ErrInjectedFailure = errors.New("fake: injected load failure")
+)
+
+type (
+	// FakeClient is a SecretClient backed by an in-memory map acting as the backend.
+	// LoadSecrets copies the backend into the cache served by GetSecret, the same way
+	// real providers do. It is safe for concurrent use.
+	FakeClient struct {
+		mu        sync.RWMutex
+		backend   map[string]string // Simulated provider state
+		secrets   map[string]string // Cache populated by LoadSecrets
+		latency   time.Duration     // Delay added to every load
+		failures  []error           // Errors returned by the next loads, in order
+		loadCount int               // Number of LoadSecrets calls
+	}
+
+	// Option configures a FakeClient.
+	Option func(*FakeClient)
+)
+
+// WithSeed seeds the simulated backend with the given values.
+func WithSeed(secrets map[string]string) Option {
+	return func(f *FakeClient) {
+		maps.Copy(f.backend, secrets)
+	}
+}
+
+// WithLatency delays every load by d.
+func WithLatency(d time.Duration) Option {
+	return func(f *FakeClient) {
+		f.latency = d
+	}
+}
+
+// NewFakeClient creates a FakeClient with an empty cache. Secrets seeded through WithSeed
+// are only served after LoadSecrets is called.
+func NewFakeClient(opts ...Option) *FakeClient {
+	f := &FakeClient{
+		backend: make(map[string]string),
+		secrets: make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// LoadSecrets copies the backend into the cache after the configured latency. When a
+// failure was injected, the cache is left untouched and the failure is returned. The
+// context cancels the simulated latency.
+func (f *FakeClient) LoadSecrets(ctx context.Context) error {
+	f.mu.Lock()
+	f.loadCount++
+	latency := f.latency
+
+	var failure error
+	if len(f.failures) > 0 {
+		failure, f.failures = f.failures[0], f.failures[1:]
+	}
+	f.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if failure != nil {
+		return failure
+	}
+
+	f.mu.Lock()
+	f.secrets = maps.Clone(f.backend)
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Reload loads the secrets again. It implements the secretsmanager.Reloadable interface.
+func (f *FakeClient) Reload(ctx context.Context) error {
+	return f.LoadSecrets(ctx)
+}
+
+// GetSecret retrieves a value from the cache, returning sm.ErrSecretNotFound when missing.
+func (f *FakeClient) GetSecret(_ context.Context, key string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	value, ok := f.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value, nil
+}
+
+// Snapshot returns a copy of the cache. It implements the secretsmanager.Snapshotter interface.
+func (f *FakeClient) Snapshot(_ context.Context) (map[string]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return maps.Clone(f.secrets), nil
+}
+
+// SetSecret sets a value in the simulated backend, e.g. to simulate a rotation.
+// The cache only reflects it after the next successful load.
+func (f *FakeClient) SetSecret(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.backend[key] = value
+}
+
+// DeleteSecret removes a value from the simulated backend.
+func (f *FakeClient) DeleteSecret(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.backend, key)
+}
+
+// FailNextLoad makes the next load fail with ErrInjectedFailure, or with err when given.
+// Calling it several times queues several failures.
+func (f *FakeClient) FailNextLoad(err ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	failure := ErrInjectedFailure
+	if len(err) > 0 && err[0] != nil {
+		failure = err[0]
+	}
+
+	f.failures = append(f.failures, failure)
+}
+
+// AddLatency increases the delay added to every load by d.
+func (f *FakeClient) AddLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.latency += d
+}
+
+// LoadCount returns the number of LoadSecrets calls made so far.
+func (f *FakeClient) LoadCount() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.loadCount
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+func TestFakeClientSeed(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClient(fake.WithSeed(map[string]string{"db_password": "s3cret"}))
+
+	if _, err := c.GetSecret(ctx, "db_password"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v before the first load, want ErrSecretNotFound", err)
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if value, err := c.GetSecret(ctx, "db_password"); err != nil || value != "s3cret" {
+		t.Errorf("GetSecret() = %q, %v, want the seeded value", value, err)
+	}
+
+	snapshot, _ := c.Snapshot(ctx)
+	if !maps.Equal(snapshot, map[string]string{"db_password": "s3cret"}) {
+		t.Errorf("Snapshot() = %v, want the seed", snapshot)
+	}
+}
+
+func TestFakeClientRotation(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewFakeClient(fake.WithSeed(map[string]string{"api_token": "v1", "old": "x"}))
+	_ = c.LoadSecrets(ctx)
+
+	c.SetSecret("api_token", "v2")
+	c.DeleteSecret("old")
+
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v1" {
+		t.Errorf("GetSecret() = %q before reloading, want the cached value", value)
+	}
+
+	if err := c.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v2" {
+		t.Errorf("GetSecret() = %q after reloading, want the rotated value", value)
+	}
+	if _, err := c.GetSecret(ctx, "old"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v for a deleted key, want ErrSecretNotFound", err)
+	}
+	if got := c.LoadCount(); got != 2 {
+		t.Errorf("LoadCount() = %d, want 2", got)
+	}
+}
+
+func TestFakeClientFailNextLoad(t *testing.T) {
+	ctx := context.Background()
+	errBackend := errors.New("backend unavailable")
+
+	c := fake.NewFakeClient(fake.WithSeed(map[string]string{"api_token": "v1"}))
+	_ = c.LoadSecrets(ctx)
+
+	c.SetSecret("api_token", "v2")
+	c.FailNextLoad()
+	c.FailNextLoad(errBackend)
+
+	if err := c.LoadSecrets(ctx); !errors.Is(err, fake.ErrInjectedFailure) {
+		t.Errorf("LoadSecrets() error = %v, want ErrInjectedFailure", err)
+	}
+	if err := c.LoadSecrets(ctx); !errors.Is(err, errBackend) {
+		t.Errorf("LoadSecrets() error = %v, want the queued failure", err)
+	}
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v1" {
+		t.Errorf("GetSecret() = %q, want the cache kept by failed loads", value)
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Errorf("LoadSecrets() error = %v once the failures are consumed", err)
+	}
+}
+
+func TestFakeClientLatency(t *testing.T) {
+	c := fake.NewFakeClient(fake.WithLatency(10 * time.Millisecond))
+	c.AddLatency(10 * time.Millisecond)
+
+	start := time.Now()
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("LoadSecrets() took %v, want at least the configured 20ms", elapsed)
+	}
+
+	c.AddLatency(time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := c.LoadSecrets(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("LoadSecrets() error = %v, want the context error", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+const (
+	// DefaultSecretsDir is where Docker Swarm, Compose and Podman mount container secrets.
+	DefaultSecretsDir = "/run/secrets"
+)
+
+// dirSecretClient is an implementation of the SecretClient interface reading every file
+// of a directory into an in-memory cache keyed by file name.
+type dirSecretClient struct {
+	dir string // Directory holding one file per secret
+
+	mu       sync.RWMutex
+	secrets  map[string]redact.String // In-memory cache of secret key-value pairs
+	modified map[string]time.Time     // Modification time of each secret file
+}
+
+// NewDirSecretClient creates a client reading one secret per file of dir, keyed by the
+// file name, following the Docker and Podman secrets convention. Pass DefaultSecretsDir
+// for the standard mount point.
+//
+// Parameters:
+//   - dir: The directory holding the secret files
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the directory
+func NewDirSecretClient(dir string) sm.SecretClient {
+	return &dirSecretClient{
+		dir:      dir,
+		secrets:  make(map[string]redact.String),
+		modified: make(map[string]time.Time),
+	}
+}
+
+// LoadSecrets reads every regular file of the directory into the in-memory cache. Sub
+// directories and hidden entries, such as the "..data" links of Kubernetes volumes, are
+// skipped. Values are cached exactly as stored, including any trailing newline; use a
+// secretsmanager.TransformClient with TrimSpace to strip it.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//
+// Returns:
+//   - An error if the directory or one of its files cannot be read
+func (c *dirSecretClient) LoadSecrets(_ context.Context) error {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("read secrets directory: %w", err)
+	}
+
+	secrets := make(map[string]string, len(entries))
+	modified := make(map[string]time.Time, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(c.dir, name)
+
+		// Stat follows symlinks, which mounts commonly use for the secret files
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("stat secret file %s: %w", name, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read secret file %s: %w", name, err)
+		}
+
+		secrets[name] = string(data)
+		modified[name] = info.ModTime()
+	}
+
+	c.mu.Lock()
+	c.secrets = redact.Map(secrets)
+	c.modified = modified
+	c.mu.Unlock()
+
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *dirSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
+
+// LastModified returns the modification time of the file key was read from, as of the
+// last load. It implements the secretsmanager.LastModifiedReporter interface.
+func (c *dirSecretClient) LastModified(key string) (time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	modified, ok := c.modified[key]
+	return modified, ok
+}
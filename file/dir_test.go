@@ -0,0 +1,63 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package file_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/file"
+)
+
+func TestDirSecretClient(t *testing.T) {
+	dir := t.TempDir()
+	for name, value := range map[string]string{
+		"db_password": "s3cret\n",
+		"api_token":   "t0ken",
+		".hidden":     "skipped",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	c := file.NewDirSecretClient(dir)
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"db_password": "s3cret\n", "api_token": "t0ken"} {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+
+	for _, key := range []string{".hidden", "nested"} {
+		if _, err := c.GetSecret(ctx, key); !errors.Is(err, sm.ErrSecretNotFound) {
+			t.Errorf("GetSecret(%q) error = %v, want it skipped", key, err)
+		}
+	}
+
+	if _, ok := c.(sm.LastModifiedReporter).LastModified("api_token"); !ok {
+		t.Error("LastModified() reports nothing for a loaded file")
+	}
+}
+
+func TestDirSecretClientMissingDir(t *testing.T) {
+	c := file.NewDirSecretClient(filepath.Join(t.TempDir(), "missing"))
+
+	if err := c.LoadSecrets(context.Background()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadSecrets() error = %v, want os.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package file provides SecretClient implementations reading secrets from a local JSON
+// file, several JSON files merged in order, standard input, or from a directory holding
+// one file per secret such as the /run/secrets mount of Docker and Podman. It is meant for
+// local development, CLI tools and containers that receive secrets from an upstream step.
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+const (
+	// Stdin is the path reading secrets from standard input instead of a file.
+	Stdin = "-"
+)
+
+var (
+	// ErrStdinConsumed is returned when LoadSecrets is called again on a client reading
+	// standard input, which was already drained by the first call.
+	ErrStdinConsumed = errors.New("secrets were already read from stdin")
+)
+
+// fileSecretClient is an implementation of the SecretClient interface that reads a JSON
+// object of string values from a file or standard input into an in-memory cache.
+type fileSecretClient struct {
+	path  string    // Path of the JSON file, or Stdin
+	stdin io.Reader // Reader used when path is Stdin
+	fsys  fs.FS     // File system holding path, nil for the OS file system
+
+	mu      sync.RWMutex
+	drained bool                     // Whether stdin was already read
+	secrets map[string]redact.String // In-memory cache of secret key-value pairs
+}
+
+// NewFileSecretClient creates a client reading secrets from the JSON file at path.
+// Passing Stdin ("-") reads the JSON document from standard input instead.
+//
+// Parameters:
+//   - path: Path of the JSON file holding the secrets, or Stdin
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the file
+func NewFileSecretClient(path string) sm.SecretClient {
+	return &fileSecretClient{
+		path:    path,
+		stdin:   os.Stdin,
+		secrets: make(map[string]redact.String),
+	}
+}
+
+// NewStdinSecretClient creates a client reading secrets piped as JSON on standard input.
+// Since stdin can only be read once, LoadSecrets returns ErrStdinConsumed when called again.
+//
+// Returns:
+//   - A SecretClient interface implementation backed by standard input
+func NewStdinSecretClient() sm.SecretClient {
+	return NewFileSecretClient(Stdin)
+}
+
+// NewFSSecretClient creates a client reading secrets from the JSON file at path within
+// fsys, such as an embed.FS holding a fixture for self-contained test binaries:
+//
+//	//go:embed testdata/secrets.json
+//	var fixtures embed.FS
+//
+//	client := file.NewFSSecretClient(fixtures, "testdata/secrets.json")
+//
+// Parameters:
+//   - fsys: The file system holding the secrets file
+//   - path: Slash-separated path of the JSON file within fsys
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the file
+func NewFSSecretClient(fsys fs.FS, path string) sm.SecretClient {
+	return &fileSecretClient{
+		path:    path,
+		fsys:    fsys,
+		secrets: make(map[string]redact.String),
+	}
+}
+
+// LoadSecrets reads and parses the JSON document into the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//
+// Returns:
+//   - An error if the source cannot be read or parsed
+func (c *fileSecretClient) LoadSecrets(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return fmt.Errorf("parse secrets from %s: %w", c.source(), redact.JSONError(err))
+	}
+
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	c.secrets = redact.Map(secrets)
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *fileSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
+
+// read returns the raw content of the configured source. It must be called with mu held.
+func (c *fileSecretClient) read() ([]byte, error) {
+	if c.fsys != nil {
+		data, err := fs.ReadFile(c.fsys, c.path)
+		if err != nil {
+			return nil, fmt.Errorf("read secrets file: %w", err)
+		}
+
+		return data, nil
+	}
+
+	if c.path != Stdin {
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			return nil, fmt.Errorf("read secrets file: %w", err)
+		}
+
+		return data, nil
+	}
+
+	if c.drained {
+		return nil, ErrStdinConsumed
+	}
+
+	c.drained = true
+
+	data, err := io.ReadAll(c.stdin)
+	if err != nil {
+		return nil, fmt.Errorf("read secrets from stdin: %w", err)
+	}
+
+	return data, nil
+}
+
+// source describes where the secrets are read from, for error messages.
+func (c *fileSecretClient) source() string {
+	if c.path == Stdin && c.fsys == nil {
+		return "stdin"
+	}
+
+	return c.path
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package file_test
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/file"
+)
+
+//go:embed testdata/secrets.json
+var fixtures embed.FS
+
+// pipeStdin replaces os.Stdin with a pipe fed with data for the duration of the test.
+func pipeStdin(t *testing.T, data string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+
+	go func() {
+		_, _ = w.WriteString(data)
+		w.Close()
+	}()
+}
+
+func TestStdinSecretClient(t *testing.T) {
+	pipeStdin(t, `{"db_password":"s3cret","api_token":"t0ken"}`)
+
+	c := file.NewStdinSecretClient()
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"db_password": "s3cret", "api_token": "t0ken"} {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+
+	if err := c.LoadSecrets(ctx); !errors.Is(err, file.ErrStdinConsumed) {
+		t.Errorf("LoadSecrets() error = %v on the second call, want ErrStdinConsumed", err)
+	}
+	if value, _ := c.GetSecret(ctx, "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want the cache kept after the second call", value)
+	}
+}
+
+func TestStdinSecretClientMalformed(t *testing.T) {
+	pipeStdin(t, `{"db_password":s3cret`)
+
+	err := file.NewFileSecretClient(file.Stdin).LoadSecrets(context.Background())
+	if err == nil {
+		t.Fatal("LoadSecrets() error = nil, want a parsing error")
+	}
+}
+
+func TestFileSecretClientMalformedNotDisclosed(t *testing.T) {
+	path := t.TempDir() + "/secrets.json"
+	if err := os.WriteFile(path, []byte(`{"db_password":s3cret}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := file.NewFileSecretClient(path).LoadSecrets(context.Background())
+	if err == nil {
+		t.Fatal("LoadSecrets() error = nil, want a parsing error")
+	}
+	if msg := err.Error(); strings.Contains(msg, "'s'") || !strings.Contains(msg, "byte offset 16") {
+		t.Errorf("LoadSecrets() error = %q, want only the kind and offset of the JSON error", msg)
+	}
+}
+
+func TestFileSecretClient(t *testing.T) {
+	path := t.TempDir() + "/secrets.json"
+	if err := os.WriteFile(path, []byte(`{"db_password":"s3cret"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := file.NewFileSecretClient(path)
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if value, _ := c.GetSecret(ctx, "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want s3cret", value)
+	}
+	if _, err := c.GetSecret(ctx, "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestFSSecretClient(t *testing.T) {
+	c := file.NewFSSecretClient(fixtures, "testdata/secrets.json")
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"db_password": "s3cret", "api_token": "t0ken"} {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+
+	err := file.NewFSSecretClient(fixtures, "testdata/missing.json").LoadSecrets(ctx)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadSecrets() error = %v, want fs.ErrNotExist", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"sync"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+type (
+	// multiFileSecretClient is an implementation of the SecretClient interface merging the
+	// JSON objects of several files into an in-memory cache.
+	multiFileSecretClient struct {
+		paths       []string // Paths of the JSON files, from lowest to highest precedence
+		skipMissing bool     // Whether files that don't exist are skipped
+
+		mu      sync.RWMutex
+		secrets map[string]redact.String // In-memory cache of secret key-value pairs
+	}
+
+	// MultiFileOption configures optional behavior of a client reading several files.
+	MultiFileOption func(*multiFileSecretClient)
+)
+
+// WithSkipMissing skips the files that don't exist instead of failing the load, for
+// optional overrides such as a developer's local file. Files that exist but cannot be
+// read or parsed still fail the load.
+func WithSkipMissing() MultiFileOption {
+	return func(c *multiFileSecretClient) {
+		c.skipMissing = true
+	}
+}
+
+// NewMultiFileSecretClient creates a client merging the JSON files at paths, read in
+// order, so that a key defined in several files takes the value of the last one:
+//
+//	client := file.NewMultiFileSecretClient(
+//		[]string{"secrets.json", "secrets.local.json"},
+//		file.WithSkipMissing(),
+//	)
+//
+// Parameters:
+//   - paths: Paths of the JSON files, from lowest to highest precedence
+//   - opts: Optional settings of the client
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the files
+func NewMultiFileSecretClient(paths []string, opts ...MultiFileOption) sm.SecretClient {
+	c := &multiFileSecretClient{
+		paths:   paths,
+		secrets: make(map[string]redact.String),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// LoadSecrets reads every file in order and replaces the in-memory cache with their
+// merged secrets. The cache is left untouched when any file fails.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//
+// Returns:
+//   - An error if a file cannot be read or parsed, or is missing without WithSkipMissing
+func (c *multiFileSecretClient) LoadSecrets(_ context.Context) error {
+	secrets := make(map[string]string)
+
+	for _, path := range c.paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) && c.skipMissing {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("read secrets file: %w", err)
+		}
+
+		var layer map[string]string
+		if err := json.Unmarshal(data, &layer); err != nil {
+			return fmt.Errorf("parse secrets from %s: %w", path, redact.JSONError(err))
+		}
+
+		maps.Copy(secrets, layer)
+	}
+
+	c.mu.Lock()
+	c.secrets = redact.Map(secrets)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *multiFileSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
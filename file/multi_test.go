@@ -0,0 +1,99 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package file_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goxkit/secretsmanager/file"
+)
+
+// writeFiles writes each JSON document to its own file of a temporary directory and
+// returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestMultiFileSecretClientMerge(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.json":  `{"db_password":"base","api_token":"t0ken"}`,
+		"local.json": `{"db_password":"local","debug_token":"dbg"}`,
+	})
+
+	c := file.NewMultiFileSecretClient([]string{
+		filepath.Join(dir, "base.json"),
+		filepath.Join(dir, "local.json"),
+	})
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	want := map[string]string{"db_password": "local", "api_token": "t0ken", "debug_token": "dbg"}
+	for key, value := range want {
+		if got, err := c.GetSecret(ctx, key); err != nil || got != value {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, got, err, value)
+		}
+	}
+}
+
+func TestMultiFileSecretClientMissingFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"base.json": `{"db_password":"base"}`})
+	paths := []string{filepath.Join(dir, "base.json"), filepath.Join(dir, "local.json")}
+	ctx := context.Background()
+
+	if err := file.NewMultiFileSecretClient(paths).LoadSecrets(ctx); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadSecrets() error = %v, want fs.ErrNotExist without WithSkipMissing", err)
+	}
+
+	c := file.NewMultiFileSecretClient(paths, file.WithSkipMissing())
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v with WithSkipMissing", err)
+	}
+	if value, _ := c.GetSecret(ctx, "db_password"); value != "base" {
+		t.Errorf("GetSecret() = %q, want the existing file loaded", value)
+	}
+}
+
+func TestMultiFileSecretClientMalformedFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"local.json": `{"db_password":`})
+
+	c := file.NewMultiFileSecretClient([]string{filepath.Join(dir, "local.json")}, file.WithSkipMissing())
+	if err := c.LoadSecrets(context.Background()); err == nil {
+		t.Error("LoadSecrets() error = nil, want malformed files failing despite WithSkipMissing")
+	}
+}
+
+func TestMultiFileSecretClientMalformedNotDisclosed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.json":  `{"db_password":"s3cret"}`,
+		"local.json": `{"db_password":["l0cal"]}`,
+	})
+
+	c := file.NewMultiFileSecretClient([]string{filepath.Join(dir, "base.json"), filepath.Join(dir, "local.json")})
+	err := c.LoadSecrets(context.Background())
+	if err == nil {
+		t.Fatal("LoadSecrets() error = nil, want a parsing error")
+	}
+	if msg := err.Error(); strings.Contains(msg, "l0cal") || !strings.Contains(msg, "local.json: value of the wrong type at byte offset 16") {
+		t.Errorf("LoadSecrets() error = %q, want the file with only the kind and offset of the JSON error", msg)
+	}
+}
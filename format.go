@@ -0,0 +1,98 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+)
+
+// Format classifies the encoding of a secret value.
+type Format string
+
+const (
+	// FormatPlain is a value matching none of the other formats.
+	FormatPlain Format = "plain"
+
+	// FormatPEM is a value holding at least one PEM block, such as a certificate or key.
+	FormatPEM Format = "pem"
+
+	// FormatJSON is a value holding a JSON object or array.
+	FormatJSON Format = "json"
+
+	// FormatBase64 is a value holding standard or URL-safe base64 data.
+	FormatBase64 Format = "base64"
+)
+
+// minBase64Length is the shortest value classified as base64. Shorter values are too
+// likely to be plain words that happen to use the base64 alphabet.
+const minBase64Length = 16
+
+// DetectFormat inspects the value of key and classifies it as PEM, JSON, base64 or plain,
+// so generic tooling can decide how to handle it. Only the classification is returned;
+// the value itself is never logged nor included in errors.
+//
+// The classification is a heuristic. A value is base64 only when it's at least 16
+// characters long, decodes cleanly, and contains a digit or one of "+/-_=", since a
+// long word made only of letters is more likely a plain passphrase.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to inspect
+//
+// Returns:
+//   - The detected format of the value
+//   - An error if the secret cannot be retrieved
+func DetectFormat(ctx context.Context, c SecretClient, key string) (Format, error) {
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	return detectFormat(value), nil
+}
+
+// detectFormat classifies value, from the most to the least specific format.
+func detectFormat(value string) Format {
+	trimmed := strings.TrimSpace(value)
+
+	if block, _ := pem.Decode([]byte(trimmed)); block != nil {
+		return FormatPEM
+	}
+
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		if json.Valid([]byte(trimmed)) {
+			return FormatJSON
+		}
+	}
+
+	if isBase64(trimmed) {
+		return FormatBase64
+	}
+
+	return FormatPlain
+}
+
+// isBase64 reports whether value looks like base64 data rather than a plain word.
+func isBase64(value string) bool {
+	if len(value) < minBase64Length || !strings.ContainsAny(value, "0123456789+/-_=") {
+		return false
+	}
+
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.RawStdEncoding,
+		base64.URLEncoding, base64.RawURLEncoding,
+	} {
+		if _, err := enc.DecodeString(value); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
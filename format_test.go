@@ -0,0 +1,60 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestDetectFormat(t *testing.T) {
+	seed := map[string]string{
+		"pem":         "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIU\n-----END CERTIFICATE-----\n",
+		"json_object": ` {"user":"admin","password":"s3cret"}`,
+		"json_array":  `["k1","k2"]`,
+		"base64":      "c2VjcmV0LXZhbHVlLTEyMw==",
+		"base64_url":  "c2VjcmV0LXZhbHVlLTEyMw",
+		"plain":       "correct horse battery staple",
+		"short":       "abc123",
+		"broken_json": `{"user":`,
+		// Decodes as base64 but is made of letters only, so likely a passphrase
+		"ambiguous": "CorrectHorseBatteryStaple",
+	}
+	c := newLoadedClient(t, seed)
+
+	tests := map[string]sm.Format{
+		"pem":         sm.FormatPEM,
+		"json_object": sm.FormatJSON,
+		"json_array":  sm.FormatJSON,
+		"base64":      sm.FormatBase64,
+		"base64_url":  sm.FormatBase64,
+		"plain":       sm.FormatPlain,
+		"short":       sm.FormatPlain,
+		"broken_json": sm.FormatPlain,
+		"ambiguous":   sm.FormatPlain,
+	}
+
+	for key, want := range tests {
+		t.Run(key, func(t *testing.T) {
+			got, err := sm.DetectFormat(context.Background(), c, key)
+			if err != nil {
+				t.Fatalf("DetectFormat() error = %v", err)
+			}
+			if got != want {
+				t.Errorf("DetectFormat() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDetectFormatMissingKey(t *testing.T) {
+	format, err := sm.DetectFormat(context.Background(), newLoadedClient(t, nil), "missing")
+	if !errors.Is(err, sm.ErrSecretNotFound) || format != "" {
+		t.Errorf("DetectFormat() = %q, %v, want ErrSecretNotFound", format, err)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// secretFS exposes a SecretClient as a read-only fs.FS.
+	secretFS struct {
+		client SecretClient
+	}
+
+	// secretFile is an open secret whose content is the secret value.
+	secretFile struct {
+		info   secretFileInfo
+		reader *strings.Reader
+	}
+
+	// secretDir is the root directory listing the cached secret keys.
+	secretDir struct {
+		info    secretFileInfo
+		entries []fs.DirEntry
+		offset  int
+	}
+
+	// secretFileInfo describes a secret file or the root directory.
+	secretFileInfo struct {
+		name  string
+		size  int64
+		isDir bool
+	}
+)
+
+// AsFS presents a SecretClient as a read-only fs.FS, where every secret key is a file
+// whose content is the secret value. It lets libraries that only understand files
+// consume secrets without writing them to disk.
+//
+// Open reads from the client through GetSecret, so secrets must have been loaded first.
+// Keys that don't exist result in fs.ErrNotExist. The root directory lists the cached
+// keys only when the client implements Snapshotter.
+//
+// Parameters:
+//   - c: The secret client backing the file system
+//
+// Returns:
+//   - An fs.FS serving the client's secrets
+func AsFS(c SecretClient) fs.FS {
+	return &secretFS{client: c}
+}
+
+// Open opens the secret named by the key name, or the root directory for ".".
+func (f *secretFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	ctx := context.Background()
+
+	if name == "." {
+		return f.openRoot(ctx)
+	}
+
+	value, err := f.client.GetSecret(ctx, name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &secretFile{
+		info:   secretFileInfo{name: path.Base(name), size: int64(len(value))},
+		reader: strings.NewReader(value),
+	}, nil
+}
+
+// openRoot builds the root directory from the client's snapshot, when available.
+func (f *secretFS) openRoot(ctx context.Context) (fs.File, error) {
+	dir := &secretDir{info: secretFileInfo{name: ".", isDir: true}}
+
+	snapshotter, ok := f.client.(Snapshotter)
+	if !ok {
+		return dir, nil
+	}
+
+	secrets, err := snapshotter.Snapshot(ctx)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: ".", Err: err}
+	}
+
+	for key, value := range secrets {
+		// Keys that aren't a single path element can still be opened but aren't listed
+		if !fs.ValidPath(key) || strings.Contains(key, "/") {
+			continue
+		}
+
+		info := secretFileInfo{name: key, size: int64(len(value))}
+		dir.entries = append(dir.entries, fs.FileInfoToDirEntry(info))
+	}
+
+	sort.Slice(dir.entries, func(i, j int) bool {
+		return dir.entries[i].Name() < dir.entries[j].Name()
+	})
+
+	return dir, nil
+}
+
+func (f *secretFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *secretFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+
+func (f *secretFile) Close() error { return nil }
+
+func (d *secretDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+
+func (d *secretDir) Read(_ []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *secretDir) Close() error { return nil }
+
+// ReadDir implements fs.ReadDirFile following the semantics of os.File.ReadDir.
+func (d *secretDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+
+	d.offset += n
+	return remaining[:n], nil
+}
+
+func (i secretFileInfo) Name() string { return i.name }
+
+func (i secretFileInfo) Size() int64 { return i.size }
+
+func (i secretFileInfo) Mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir | 0o500
+	}
+
+	return 0o400
+}
+
+func (i secretFileInfo) ModTime() time.Time { return time.Time{} }
+
+func (i secretFileInfo) IsDir() bool { return i.isDir }
+
+func (i secretFileInfo) Sys() any { return nil }
@@ -0,0 +1,45 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestAsFSReadFile(t *testing.T) {
+	fsys := sm.AsFS(newLoadedClient(t, map[string]string{"tls.key": "PRIVATE KEY"}))
+
+	data, err := fs.ReadFile(fsys, "tls.key")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != "PRIVATE KEY" {
+		t.Errorf("ReadFile() = %q, want %q", data, "PRIVATE KEY")
+	}
+
+	if _, err := fs.ReadFile(fsys, "missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile() error = %v, want fs.ErrNotExist", err)
+	}
+
+	if _, err := fs.ReadFile(fsys, "../tls.key"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("ReadFile() error = %v, want fs.ErrInvalid", err)
+	}
+}
+
+func TestAsFSConformance(t *testing.T) {
+	fsys := sm.AsFS(newLoadedClient(t, map[string]string{
+		"api_token": "t0ken",
+		"tls.crt":   "CERTIFICATE",
+	}))
+
+	if err := fstest.TestFS(fsys, "api_token", "tls.crt"); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DefaultHealthConcurrency is the default number of providers CheckHealth probes at once.
+	DefaultHealthConcurrency = 4
+
+	// DefaultHealthTimeout is the default time a provider has to answer its probe.
+	DefaultHealthTimeout = 5 * time.Second
+)
+
+type (
+	// healthOptions holds the settings of CheckHealth.
+	healthOptions struct {
+		concurrency int
+		timeout     time.Duration
+	}
+
+	// HealthOption configures optional behavior of CheckHealth.
+	HealthOption func(*healthOptions)
+)
+
+// WithHealthConcurrency bounds the number of providers probed at once. Defaults to
+// DefaultHealthConcurrency.
+func WithHealthConcurrency(n int) HealthOption {
+	return func(o *healthOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithHealthTimeout sets the time each provider has to answer its probe, after which its
+// probe context is canceled. Defaults to DefaultHealthTimeout.
+func WithHealthTimeout(timeout time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// CheckHealth probes the providers implementing HealthChecker concurrently and reports
+// the outcome of each one by name, nil meaning healthy, so a readiness probe can tell
+// which backend is down. Providers not implementing HealthChecker cannot be probed and are
+// left out of the result. A failing or slow provider never cancels the other probes.
+//
+// Parameters:
+//   - ctx: Context bounding every probe
+//   - providers: The providers to probe, indexed by name
+//   - opts: Optional concurrency and timeout settings
+//
+// Returns:
+//   - The probe error of each probed provider, indexed by name
+func CheckHealth(ctx context.Context, providers map[string]SecretClient, opts ...HealthOption) map[string]error {
+	o := &healthOptions{concurrency: DefaultHealthConcurrency, timeout: DefaultHealthTimeout}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var mu sync.Mutex
+	status := make(map[string]error, len(providers))
+
+	var g errgroup.Group
+	g.SetLimit(o.concurrency)
+
+	for name, provider := range providers {
+		checker, ok := provider.(HealthChecker)
+		if !ok {
+			continue
+		}
+
+		g.Go(func() error {
+			probeCtx, cancel := context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+
+			err := checker.HealthCheck(probeCtx)
+
+			mu.Lock()
+			status[name] = err
+			mu.Unlock()
+
+			// Probe failures are reported in status, never through the group
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return status
+}
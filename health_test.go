@@ -0,0 +1,119 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// probeClient is a mapClient whose HealthCheck runs probe.
+type probeClient struct {
+	mapClient
+	probe func(ctx context.Context) error
+}
+
+func (p *probeClient) HealthCheck(ctx context.Context) error {
+	return p.probe(ctx)
+}
+
+func healthy(context.Context) error { return nil }
+
+// hanging blocks until the probe is canceled.
+func hanging(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCheckHealth(t *testing.T) {
+	errDown := errors.New("backend unavailable")
+
+	providers := map[string]sm.SecretClient{
+		"vault":  &probeClient{probe: healthy},
+		"aws":    &probeClient{probe: func(context.Context) error { return errDown }},
+		"slow":   &probeClient{probe: hanging},
+		"static": &mapClient{},
+	}
+
+	start := time.Now()
+	status := sm.CheckHealth(context.Background(), providers, sm.WithHealthTimeout(20*time.Millisecond))
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CheckHealth() took %v, want the slow probe bounded by its timeout", elapsed)
+	}
+
+	if err, ok := status["vault"]; !ok || err != nil {
+		t.Errorf("status[vault] = %v, %v, want healthy", err, ok)
+	}
+	if err := status["aws"]; !errors.Is(err, errDown) {
+		t.Errorf("status[aws] = %v, want the probe failure", err)
+	}
+	if err := status["slow"]; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("status[slow] = %v, want the probe timed out", err)
+	}
+	if _, ok := status["static"]; ok {
+		t.Error("status reports a provider not implementing HealthChecker")
+	}
+}
+
+func TestCheckHealthConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+
+	probe := func(context.Context) error {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			p := peak.Load()
+			if current <= p || peak.CompareAndSwap(p, current) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}
+
+	providers := make(map[string]sm.SecretClient)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		providers[name] = &probeClient{probe: probe}
+	}
+
+	status := sm.CheckHealth(context.Background(), providers, sm.WithHealthConcurrency(2))
+	if len(status) != len(providers) {
+		t.Errorf("CheckHealth() reported %d providers, want %d", len(status), len(providers))
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("concurrent probes = %d, want at most 2", got)
+	}
+}
+
+func TestRoutingClientHealthCheck(t *testing.T) {
+	errDown := errors.New("backend unavailable")
+
+	r := sm.NewRoutingClient(map[string]sm.SecretClient{
+		"vault/": &probeClient{probe: healthy},
+		"aws/":   &probeClient{probe: func(context.Context) error { return errDown }},
+	}, &probeClient{probe: healthy})
+	r.OverrideKey("legacy", &probeClient{probe: healthy})
+
+	status := r.CheckHealth(context.Background())
+	for _, name := range []string{"vault/", "aws/", "key:legacy", "default"} {
+		if _, ok := status[name]; !ok {
+			t.Errorf("CheckHealth() doesn't report %q", name)
+		}
+	}
+
+	err := r.HealthCheck(context.Background())
+	if !errors.Is(err, errDown) || !strings.Contains(err.Error(), "aws/") {
+		t.Errorf("HealthCheck() = %v, want the failure attributed to aws/", err)
+	}
+}
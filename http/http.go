@@ -0,0 +1,203 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package http provides a SecretClient implementation loading secrets from an HTTP(S)
+// endpoint returning a JSON object of string values, such as a bespoke internal secret
+// broker. Bearer token, custom header and mutual TLS authentication are supported.
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+const (
+	// DefaultTimeout bounds each request made by the client.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxBodySize is the largest response body accepted by LoadSecrets.
+	DefaultMaxBodySize = 4 << 20
+)
+
+var (
+	// ErrBodyTooLarge is returned when the response body exceeds the maximum size.
+	ErrBodyTooLarge = errors.New("secrets response body exceeds the maximum size")
+)
+
+type (
+	// httpSecretClient is an implementation of the SecretClient interface fetching a JSON
+	// document over HTTP(S) into an in-memory cache.
+	httpSecretClient struct {
+		url         string
+		client      *nethttp.Client
+		headers     nethttp.Header
+		tlsConfig   *tls.Config
+		maxBodySize int64
+
+		mu      sync.RWMutex
+		secrets map[string]redact.String // In-memory cache of secret key-value pairs
+	}
+
+	// Option configures optional behavior of the HTTP client.
+	Option func(*httpSecretClient)
+)
+
+// WithBearerToken authenticates requests with an "Authorization: Bearer" header.
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHeader adds a header sent with every request, e.g. an API key header.
+func WithHeader(key, value string) Option {
+	return func(c *httpSecretClient) {
+		c.headers.Set(key, value)
+	}
+}
+
+// WithMTLS authenticates the client with a certificate and verifies the server against
+// rootCAs, or against the system pool when rootCAs is nil.
+func WithMTLS(cert tls.Certificate, rootCAs *x509.CertPool) Option {
+	return func(c *httpSecretClient) {
+		c.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      rootCAs,
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client. WithMTLS is ignored when a custom
+// client is provided, since its transport is used as is.
+func WithHTTPClient(client *nethttp.Client) Option {
+	return func(c *httpSecretClient) {
+		c.client = client
+	}
+}
+
+// WithMaxBodySize sets the largest response body, in bytes, accepted by LoadSecrets.
+// Defaults to DefaultMaxBodySize.
+func WithMaxBodySize(size int64) Option {
+	return func(c *httpSecretClient) {
+		c.maxBodySize = size
+	}
+}
+
+// NewHTTPSecretClient creates a client loading secrets with a GET request to url.
+//
+// Parameters:
+//   - url: The endpoint returning the secrets as a JSON object of string values
+//   - opts: Optional settings such as authentication
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the endpoint
+func NewHTTPSecretClient(url string, opts ...Option) sm.SecretClient {
+	c := &httpSecretClient{
+		url:         url,
+		headers:     nethttp.Header{},
+		maxBodySize: DefaultMaxBodySize,
+		secrets:     make(map[string]redact.String),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.client == nil {
+		transport := nethttp.DefaultTransport.(*nethttp.Transport).Clone()
+		if c.tlsConfig != nil {
+			transport.TLSClientConfig = c.tlsConfig
+		}
+
+		c.client = &nethttp.Client{Transport: transport, Timeout: DefaultTimeout}
+	}
+
+	return c
+}
+
+// LoadSecrets fetches the endpoint and parses the JSON body into the in-memory cache.
+// A 404 response is reported as sm.ErrSecretNotFound.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - An error if the request fails, returns a non-2xx status, or the body cannot be parsed
+func (c *httpSecretClient) LoadSecrets(ctx context.Context) error {
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, c.url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header = c.headers.Clone()
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch secrets: %w", err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == nethttp.StatusNotFound:
+		return fmt.Errorf("fetch secrets: %w: status %d", sm.ErrSecretNotFound, res.StatusCode)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return fmt.Errorf("fetch secrets: unexpected status %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.maxBodySize+1))
+	if err != nil {
+		return fmt.Errorf("read secrets response: %w", err)
+	}
+
+	if int64(len(body)) > c.maxBodySize {
+		return ErrBodyTooLarge
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(body, &secrets); err != nil {
+		return fmt.Errorf("parse secrets response: %w", redact.JSONError(err))
+	}
+
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	c.mu.Lock()
+	c.secrets = redact.Map(secrets)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *httpSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package http_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/http"
+)
+
+// newSecretsServer serves body to requests carrying the bearer token, and 401 otherwise.
+func newSecretsServer(t *testing.T, token, body string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestHTTPSecretClient(t *testing.T) {
+	srv := newSecretsServer(t, "t0ken", `{"db_password":"s3cret","api_key":"k3y"}`)
+
+	c := http.NewHTTPSecretClient(srv.URL, http.WithBearerToken("t0ken"))
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for key, want := range map[string]string{"db_password": "s3cret", "api_key": "k3y"} {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+	if _, err := c.GetSecret(ctx, "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestHTTPSecretClientErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		opts    []http.Option
+		wantErr error
+	}{
+		{name: "not found", status: nethttp.StatusNotFound, wantErr: sm.ErrSecretNotFound},
+		{name: "server error", status: nethttp.StatusInternalServerError},
+		{name: "malformed body", status: nethttp.StatusOK, body: `{"db_password":`},
+		{
+			name:    "body too large",
+			status:  nethttp.StatusOK,
+			body:    `{"db_password":"` + strings.Repeat("x", 64) + `"}`,
+			opts:    []http.Option{http.WithMaxBodySize(32)},
+			wantErr: http.ErrBodyTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, _ *nethttp.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			err := http.NewHTTPSecretClient(srv.URL, tt.opts...).LoadSecrets(context.Background())
+			if err == nil {
+				t.Fatal("LoadSecrets() error = nil, want a failure")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadSecrets() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPSecretClientHeaders(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Header.Get("X-Api-Key") != "k3y" || r.Header.Get("Accept") != "application/json" {
+			w.WriteHeader(nethttp.StatusForbidden)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"a":"1"}`))
+	}))
+	defer srv.Close()
+
+	if err := http.NewHTTPSecretClient(srv.URL).LoadSecrets(context.Background()); err == nil {
+		t.Error("LoadSecrets() error = nil without the header, want the request rejected")
+	}
+	if err := http.NewHTTPSecretClient(srv.URL, http.WithHeader("X-Api-Key", "k3y")).LoadSecrets(context.Background()); err != nil {
+		t.Errorf("LoadSecrets() error = %v with the header", err)
+	}
+}
+
+func TestHTTPSecretClientMTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"db_password":"s3cret"}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// The test server certificate doubles as the client certificate and its own CA
+	cert := srv.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	if err := http.NewHTTPSecretClient(srv.URL, http.WithMTLS(tls.Certificate{}, roots)).LoadSecrets(context.Background()); err == nil {
+		t.Error("LoadSecrets() error = nil without a client certificate, want the handshake rejected")
+	}
+
+	c := http.NewHTTPSecretClient(srv.URL, http.WithMTLS(cert, roots))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatalf("LoadSecrets() error = %v with a client certificate", err)
+	}
+	if value, _ := c.GetSecret(context.Background(), "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want s3cret", value)
+	}
+}
+
+func TestHTTPSecretClientMalformedBodyNotDisclosed(t *testing.T) {
+	for _, body := range []string{`{"db_password":s3cret}`, `{"db_password":["s3cret"]}`} {
+		srv := newSecretsServer(t, "t0ken", body)
+
+		err := http.NewHTTPSecretClient(srv.URL, http.WithBearerToken("t0ken")).LoadSecrets(context.Background())
+		if err == nil {
+			t.Fatalf("LoadSecrets() error = nil for %s, want a failure", body)
+		}
+		if msg := err.Error(); strings.Contains(msg, "s3cret") || strings.Contains(msg, "'s'") || !strings.Contains(msg, "byte offset") {
+			t.Errorf("LoadSecrets() error = %q, want only the kind and offset of the JSON error", msg)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCacheDrift reports that the cached secrets no longer match the backend.
+	ErrCacheDrift = errors.New("cached secrets drifted from the backend")
+)
+
+type (
+	// IntegrityVerifier is implemented by providers able to compare their cache with the
+	// backend without exposing or updating any value.
+	IntegrityVerifier interface {
+		// VerifyIntegrity reports whether the cache matches the backend.
+		VerifyIntegrity(ctx context.Context) (bool, error)
+	}
+
+	// IntegrityChecker periodically verifies that a provider's cache still matches its
+	// backend, detecting secrets edited out-of-band. It never exposes secret values.
+	IntegrityChecker struct {
+		verifier IntegrityVerifier
+		interval time.Duration
+		onIssue  func(err error)
+
+		startOnce sync.Once
+		stopOnce  sync.Once
+		stop      chan struct{}
+		done      chan struct{}
+	}
+)
+
+// NewIntegrityChecker creates a checker verifying v every interval once started.
+//
+// onIssue is called with an error matching ErrCacheDrift when the cache drifted, or with
+// the verification error when the check itself failed. It isn't called when the cache
+// matches the backend.
+//
+// Parameters:
+//   - v: The provider to verify
+//   - interval: The delay between two checks
+//   - onIssue: Callback notified of drifts and failed checks
+//
+// Returns:
+//   - An IntegrityChecker ready to be started
+func NewIntegrityChecker(v IntegrityVerifier, interval time.Duration, onIssue func(err error)) *IntegrityChecker {
+	return &IntegrityChecker{
+		verifier: v,
+		interval: interval,
+		onIssue:  onIssue,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Check runs a single verification and returns ErrCacheDrift when the cache drifted.
+func (i *IntegrityChecker) Check(ctx context.Context) error {
+	match, err := i.verifier.VerifyIntegrity(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return ErrCacheDrift
+	}
+
+	return nil
+}
+
+// Start runs the periodic verification in the background until Stop is called or ctx
+// is done. Calling Start more than once has no effect.
+func (i *IntegrityChecker) Start(ctx context.Context) {
+	i.startOnce.Do(func() {
+		go i.run(ctx)
+	})
+}
+
+// Stop ends the periodic verification and waits for the running check, if any, to
+// complete. It is safe to call Stop more than once, or without Start.
+func (i *IntegrityChecker) Stop() {
+	i.stopOnce.Do(func() {
+		started := true
+		i.startOnce.Do(func() { started = false })
+
+		close(i.stop)
+		if started {
+			<-i.done
+		}
+	})
+}
+
+// run checks the verifier every interval.
+func (i *IntegrityChecker) run(ctx context.Context) {
+	defer close(i.done)
+
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-i.stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := i.Check(ctx); err != nil && i.onIssue != nil {
+				i.onIssue(err)
+			}
+		}
+	}
+}
+
+// HashSecrets returns a hex-encoded SHA-256 digest of the secrets, computed over the
+// keys in sorted order so equal maps always produce the same digest.
+func HashSecrets(secrets map[string]string) string {
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		// Length prefixes keep ("ab", "c") and ("a", "bc") from colliding
+		writeField(h, key)
+		writeField(h, secrets[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField writes a length-prefixed field to w.
+func writeField(w io.Writer, field string) {
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(field)))
+
+	_, _ = w.Write(size[:])
+	_, _ = w.Write([]byte(field))
+}
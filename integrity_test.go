@@ -0,0 +1,119 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// driftVerifier compares a cached map with a backend map through their digests.
+type driftVerifier struct {
+	cached  map[string]string
+	backend atomic.Pointer[map[string]string]
+	err     error
+}
+
+func (v *driftVerifier) VerifyIntegrity(context.Context) (bool, error) {
+	if v.err != nil {
+		return false, v.err
+	}
+
+	return sm.HashSecrets(v.cached) == sm.HashSecrets(*v.backend.Load()), nil
+}
+
+func newDriftVerifier(cached, backend map[string]string) *driftVerifier {
+	v := &driftVerifier{cached: cached}
+	v.backend.Store(&backend)
+
+	return v
+}
+
+func TestIntegrityCheckerCheck(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+	cached := map[string]string{"db_password": "s3cret"}
+
+	tests := []struct {
+		name     string
+		verifier *driftVerifier
+		wantErr  error
+	}{
+		{name: "matching", verifier: newDriftVerifier(cached, map[string]string{"db_password": "s3cret"})},
+		{name: "edited value", verifier: newDriftVerifier(cached, map[string]string{"db_password": "rotated"}), wantErr: sm.ErrCacheDrift},
+		{name: "added key", verifier: newDriftVerifier(cached, map[string]string{"db_password": "s3cret", "x": ""}), wantErr: sm.ErrCacheDrift},
+		{name: "failed check", verifier: &driftVerifier{err: errBackend}, wantErr: errBackend},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sm.NewIntegrityChecker(tt.verifier, time.Hour, nil).Check(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Check() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil && strings.Contains(err.Error(), "s3cret") {
+				t.Errorf("error %q exposes a value", err)
+			}
+		})
+	}
+}
+
+func TestIntegrityCheckerReportsDrift(t *testing.T) {
+	v := newDriftVerifier(map[string]string{"db_password": "s3cret"}, map[string]string{"db_password": "s3cret"})
+
+	issues := make(chan error, 16)
+	checker := sm.NewIntegrityChecker(v, time.Millisecond, func(err error) { issues <- err })
+	checker.Start(context.Background())
+	defer checker.Stop()
+
+	select {
+	case err := <-issues:
+		t.Fatalf("onIssue(%v) called while the cache matches", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	v.backend.Store(&map[string]string{"db_password": "edited"})
+
+	select {
+	case err := <-issues:
+		if !errors.Is(err, sm.ErrCacheDrift) {
+			t.Errorf("onIssue(%v), want ErrCacheDrift", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("onIssue not called after the backend drifted")
+	}
+}
+
+func TestIntegrityCheckerStop(t *testing.T) {
+	checker := sm.NewIntegrityChecker(newDriftVerifier(nil, nil), time.Millisecond, nil)
+
+	checker.Stop()
+	checker.Stop()
+
+	started := sm.NewIntegrityChecker(newDriftVerifier(nil, nil), time.Millisecond, nil)
+	started.Start(context.Background())
+	started.Stop()
+	started.Stop()
+}
+
+func TestHashSecrets(t *testing.T) {
+	a := sm.HashSecrets(map[string]string{"a": "1", "b": "2"})
+	if b := sm.HashSecrets(map[string]string{"b": "2", "a": "1"}); a != b {
+		t.Errorf("HashSecrets() differs for equal maps: %s, %s", a, b)
+	}
+
+	// Field boundaries are part of the digest, so moving characters across them matters
+	if b := sm.HashSecrets(map[string]string{"a": "12", "b": ""}); a == b {
+		t.Error("HashSecrets() collides for different maps")
+	}
+	if strings.Contains(sm.HashSecrets(map[string]string{"k": "s3cret"}), "s3cret") {
+		t.Error("HashSecrets() exposes a value")
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package logs holds the logging helpers shared by the providers.
+package logs
+
+import (
+	"github.com/goxkit/configs"
+	"go.uber.org/zap"
+)
+
+// FromConfigs returns the logger of the configurations, or a no-op logger when none is
+// set, so providers built with partial configurations, as in tests, never panic.
+func FromConfigs(cfgs *configs.Configs) *zap.Logger {
+	if cfgs == nil || cfgs.Logger == nil {
+		return zap.NewNop()
+	}
+
+	return cfgs.Logger
+}
@@ -0,0 +1,33 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package logs
+
+import (
+	"testing"
+
+	"github.com/goxkit/configs"
+	"go.uber.org/zap"
+)
+
+func TestFromConfigs(t *testing.T) {
+	for name, cfgs := range map[string]*configs.Configs{
+		"nil configs": nil,
+		"nil logger":  {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			logger := FromConfigs(cfgs)
+			if logger == nil {
+				t.Fatal("FromConfigs() = nil, want a no-op logger")
+			}
+
+			logger.Info("not panicking", zap.String("key", "value"))
+		})
+	}
+
+	configured := zap.NewExample()
+	if got := FromConfigs(&configs.Configs{Logger: configured}); got != configured {
+		t.Errorf("FromConfigs() = %p, want the configured logger %p", got, configured)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package redact holds the redacting string type shared by the in-memory caches of the
+// root package and the providers, and the redacted description of JSON decoding errors.
+package redact
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Redacted replaces cached values wherever they are formatted or encoded.
+const Redacted = "[REDACTED]"
+
+// String holds a cached value that formats as "[REDACTED]" with fmt, including %v, %s, %q
+// and %#v, and encodes as "[REDACTED]" with encoding/json, so a value escaping into an
+// error, a panic or a log line isn't disclosed. Reveal returns the value itself.
+//
+// The value sits behind a pointer because fmt doesn't call String on unexported fields:
+// a struct holding a String in such a field then prints an address, not the value.
+type String struct {
+	value *string
+}
+
+// New wraps value.
+func New(value string) String {
+	return String{value: &value}
+}
+
+// Map wraps every value of values into a new map.
+func Map(values map[string]string) map[string]String {
+	wrapped := make(map[string]String, len(values))
+	for key, value := range values {
+		wrapped[key] = New(value)
+	}
+
+	return wrapped
+}
+
+// Reveal returns the wrapped value. It's the only way to read it.
+func (s String) Reveal() string {
+	if s.value == nil {
+		return ""
+	}
+
+	return *s.value
+}
+
+// RevealMap returns a new map holding the values of wrapped.
+func RevealMap(wrapped map[string]String) map[string]string {
+	values := make(map[string]string, len(wrapped))
+	for key, value := range wrapped {
+		values[key] = value.Reveal()
+	}
+
+	return values
+}
+
+func (String) String() string { return Redacted }
+
+func (String) GoString() string { return Redacted }
+
+func (String) MarshalJSON() ([]byte, error) { return []byte(`"` + Redacted + `"`), nil }
+
+// JSONError describes a JSON decoding error by its kind and byte offset only. The errors
+// of encoding/json quote the offending input, which in a secrets payload may be part of a
+// value, so they must not be wrapped as is.
+func JSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("syntax error at byte offset %d", syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("value of the wrong type at byte offset %d", typeErr.Offset)
+	default:
+		return errors.New("invalid JSON")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStringNeverFormatsTheValue(t *testing.T) {
+	value := New("s3cret")
+	cache := struct {
+		secrets map[string]String
+		Secrets map[string]String
+	}{
+		secrets: map[string]String{"key": value},
+		Secrets: map[string]String{"key": value},
+	}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q"} {
+		for _, arg := range []any{value, &value, cache, &cache} {
+			if out := fmt.Sprintf(verb, arg); strings.Contains(out, "s3cret") {
+				t.Errorf("Sprintf(%q, %T) = %s, leaks the value", verb, arg, out)
+			}
+		}
+	}
+}
+
+func TestStringNeverEncodesTheValue(t *testing.T) {
+	out, err := json.Marshal(map[string]String{"key": New("s3cret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"key":"[REDACTED]"}`; string(out) != want {
+		t.Errorf("Marshal() = %s, want %s", out, want)
+	}
+}
+
+func TestReveal(t *testing.T) {
+	if got := New("s3cret").Reveal(); got != "s3cret" {
+		t.Errorf("Reveal() = %q, want %q", got, "s3cret")
+	}
+
+	var zero String
+	if got := zero.Reveal(); got != "" {
+		t.Errorf("Reveal() of the zero value = %q, want empty", got)
+	}
+
+	values := map[string]string{"a": "1", "b": "2"}
+	if got := RevealMap(Map(values)); fmt.Sprint(got) != fmt.Sprint(values) {
+		t.Errorf("RevealMap(Map()) = %v, want %v", got, values)
+	}
+}
+
+func TestJSONErrorOmitsThePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{name: "syntax", payload: `{"db_password":s3cret}`, want: "syntax error at byte offset 16"},
+		{name: "type", payload: `{"db_password":["s3cret"]}`, want: "value of the wrong type at byte offset 16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var secrets map[string]string
+			err := JSONError(json.Unmarshal([]byte(tt.payload), &secrets))
+
+			if err.Error() != tt.want {
+				t.Errorf("JSONError() = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
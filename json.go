@@ -0,0 +1,132 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotAList is returned by GetSecretList and GetSecretAt when the value isn't a
+	// JSON array.
+	ErrNotAList = errors.New("secret value is not a JSON array")
+
+	// ErrIndexOutOfRange is returned by GetSecretAt when the index is outside the array.
+	ErrIndexOutOfRange = errors.New("secret list index out of range")
+)
+
+// GetSecretJSON retrieves the secret stored under key and unmarshals its value, which is
+// expected to be an embedded JSON document, into out.
+//
+// Values that were encoded one extra time, such as a JSON string literal wrapping the
+// document (`"{\"nested\":true}"`), are unwrapped before being decoded.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//   - out: A pointer to the value the JSON document is decoded into
+//
+// Returns:
+//   - An error if the secret cannot be retrieved or isn't valid JSON
+func GetSecretJSON(ctx context.Context, c SecretClient, key string, out interface{}) error {
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	raw := []byte(value)
+
+	// Unwrap a double-encoded value before decoding the inner document
+	if strings.HasPrefix(strings.TrimSpace(value), `"`) {
+		var inner string
+		if err := json.Unmarshal(raw, &inner); err != nil {
+			return fmt.Errorf("secret %q is not valid JSON: %w", key, redactJSONError(err))
+		}
+
+		raw = []byte(inner)
+	}
+
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("secret %q is not valid JSON: %w", key, redactJSONError(err))
+	}
+
+	return nil
+}
+
+// GetSecretList retrieves the secret stored under key and returns its value, a JSON
+// array, as a slice, e.g. a list of allowed API keys. String elements are returned
+// unquoted and other elements as their JSON text.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The elements of the array
+//   - ErrNotAList if the value isn't a JSON array
+//   - An error if the secret cannot be retrieved
+func GetSecretList(ctx context.Context, c SecretClient, key string) ([]string, error) {
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []json.RawMessage
+	if !strings.HasPrefix(strings.TrimSpace(value), "[") || json.Unmarshal([]byte(value), &raw) != nil {
+		return nil, fmt.Errorf("secret %q: %w", key, ErrNotAList)
+	}
+
+	list := make([]string, len(raw))
+	for i, element := range raw {
+		if err := json.Unmarshal(element, &list[i]); err != nil {
+			list[i] = string(element)
+		}
+	}
+
+	return list, nil
+}
+
+// GetSecretAt returns the element at index i of the JSON array stored under key.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//   - i: The zero-based index of the element
+//
+// Returns:
+//   - The element, as returned by GetSecretList
+//   - ErrNotAList if the value isn't a JSON array
+//   - ErrIndexOutOfRange if i is outside the array
+//   - An error if the secret cannot be retrieved
+func GetSecretAt(ctx context.Context, c SecretClient, key string, i int) (string, error) {
+	list, err := GetSecretList(ctx, c, key)
+	if err != nil {
+		return "", err
+	}
+
+	if i < 0 || i >= len(list) {
+		return "", fmt.Errorf("secret %q: %w: index %d, length %d", key, ErrIndexOutOfRange, i, len(list))
+	}
+
+	return list[i], nil
+}
+
+// redactJSONError replaces the syntax errors of encoding/json, which quote the offending
+// character of the value, by one reporting its byte offset only.
+func redactJSONError(err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("syntax error at byte offset %d", syntaxErr.Offset)
+	}
+
+	return err
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+// newLoadedClient returns a fake client serving seed.
+func newLoadedClient(t *testing.T, seed map[string]string) *fake.FakeClient {
+	t.Helper()
+
+	c := fake.NewFakeClient(fake.WithSeed(seed))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestGetSecretJSON(t *testing.T) {
+	type database struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	c := newLoadedClient(t, map[string]string{
+		"embedded":       `{"host":"db","port":5432}`,
+		"double_encoded": `"{\"host\":\"db\",\"port\":5432}"`,
+	})
+
+	for _, key := range []string{"embedded", "double_encoded"} {
+		t.Run(key, func(t *testing.T) {
+			var got database
+			if err := sm.GetSecretJSON(context.Background(), c, key, &got); err != nil {
+				t.Fatalf("GetSecretJSON() error = %v", err)
+			}
+
+			if got != (database{Host: "db", Port: 5432}) {
+				t.Errorf("GetSecretJSON() = %+v, want host db and port 5432", got)
+			}
+		})
+	}
+}
+
+func TestGetSecretJSONMalformed(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{
+		"truncated":      `{"host":"s3cret`,
+		"bad_character":  `{"host":s3cret}`,
+		"double_encoded": `"{\"host\":s3cret}"`,
+	})
+
+	for _, key := range []string{"truncated", "bad_character", "double_encoded"} {
+		t.Run(key, func(t *testing.T) {
+			var out map[string]string
+			err := sm.GetSecretJSON(context.Background(), c, key, &out)
+			if err == nil {
+				t.Fatal("GetSecretJSON() error = nil, want a decoding error")
+			}
+
+			if !strings.Contains(err.Error(), key) {
+				t.Errorf("error %q doesn't name the key", err)
+			}
+			if strings.Contains(err.Error(), "s3cret") || strings.Contains(err.Error(), "'s'") {
+				t.Errorf("error %q echoes the value", err)
+			}
+		})
+	}
+}
+
+func TestGetSecretList(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{
+		"api_keys": ` ["k1", "k2", 3, {"a":1}]`,
+		"empty":    `[]`,
+		"object":   `{"k1":"v"}`,
+		"plain":    `k1,k2`,
+		"broken":   `["k1",`,
+	})
+	ctx := context.Background()
+
+	list, err := sm.GetSecretList(ctx, c, "api_keys")
+	if err != nil {
+		t.Fatalf("GetSecretList() error = %v", err)
+	}
+	if want := []string{"k1", "k2", "3", `{"a":1}`}; !slices.Equal(list, want) {
+		t.Errorf("GetSecretList() = %q, want %q", list, want)
+	}
+
+	if list, err := sm.GetSecretList(ctx, c, "empty"); err != nil || len(list) != 0 {
+		t.Errorf("GetSecretList(empty) = %q, %v, want an empty list", list, err)
+	}
+
+	for _, key := range []string{"object", "plain", "broken"} {
+		if _, err := sm.GetSecretList(ctx, c, key); !errors.Is(err, sm.ErrNotAList) {
+			t.Errorf("GetSecretList(%q) error = %v, want ErrNotAList", key, err)
+		}
+	}
+}
+
+func TestGetSecretAt(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{"api_keys": `["k1","k2"]`, "plain": "k1"})
+	ctx := context.Background()
+
+	if value, err := sm.GetSecretAt(ctx, c, "api_keys", 1); err != nil || value != "k2" {
+		t.Errorf("GetSecretAt(1) = %q, %v, want k2", value, err)
+	}
+
+	for _, i := range []int{-1, 2} {
+		if _, err := sm.GetSecretAt(ctx, c, "api_keys", i); !errors.Is(err, sm.ErrIndexOutOfRange) {
+			t.Errorf("GetSecretAt(%d) error = %v, want ErrIndexOutOfRange", i, err)
+		}
+	}
+
+	if _, err := sm.GetSecretAt(ctx, c, "plain", 0); !errors.Is(err, sm.ErrNotAList) {
+		t.Errorf("GetSecretAt() error = %v for a non-array, want ErrNotAList", err)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package kubernetes exports the secrets loaded by a SecretClient to a Kubernetes Secret,
+// bridging cloud secret stores to workloads reading Kubernetes Secrets, in the manner of
+// the External Secrets Operator. It talks to the API server directly with the credentials
+// of the pod's service account, without depending on client-go.
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+const (
+	// DefaultFieldManager is the field manager owning the applied Secret fields.
+	DefaultFieldManager = "goxkit-secretsmanager"
+
+	// DefaultTimeout bounds every request made to the API server.
+	DefaultTimeout = 10 * time.Second
+
+	// serviceAccountDir is where Kubernetes mounts the service account credentials.
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+	// applyPatchContentType selects server-side apply, JSON being valid YAML.
+	applyPatchContentType = "application/apply-patch+yaml"
+)
+
+var (
+	// ErrNotInCluster is returned by NewSyncer when the API server cannot be located from
+	// the environment and WithAPIServer wasn't used.
+	ErrNotInCluster = errors.New("kubernetes api server not found: not running in a cluster")
+
+	// ErrSnapshotUnsupported is returned when the client doesn't implement
+	// secretsmanager.Snapshotter, so its cache cannot be exported.
+	ErrSnapshotUnsupported = errors.New("secret client cannot export its secrets")
+
+	// ErrInvalidKey is returned when a cached key isn't a valid Kubernetes Secret key. The
+	// error names the keys, never their values.
+	ErrInvalidKey = errors.New("key is not a valid kubernetes secret key")
+
+	// validKey matches the keys Kubernetes accepts in the data of a Secret.
+	validKey = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+)
+
+type (
+	// Syncer writes the cache of a SecretClient to Kubernetes Secrets.
+	Syncer struct {
+		source       sm.SecretClient
+		client       *http.Client
+		apiServer    string // Base URL of the API server
+		tokenFile    string // File holding the bearer token, read on every sync
+		caFile       string // File holding the API server CA bundle
+		fieldManager string // Field manager owning the applied fields
+	}
+
+	// Option configures optional behavior of a Syncer.
+	Option func(*Syncer)
+
+	// secretApply is the apply configuration of a Secret.
+	secretApply struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Metadata   secretMetadata    `json:"metadata"`
+		Type       string            `json:"type"`
+		Data       map[string][]byte `json:"data"`
+	}
+
+	// secretMetadata is the metadata of an applied Secret.
+	secretMetadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	}
+)
+
+// WithAPIServer sets the base URL of the API server, such as "https://10.0.0.1:443".
+// Defaults to the in-cluster address from KUBERNETES_SERVICE_HOST and
+// KUBERNETES_SERVICE_PORT.
+func WithAPIServer(server string) Option {
+	return func(s *Syncer) {
+		s.apiServer = strings.TrimSuffix(server, "/")
+	}
+}
+
+// WithTokenFile reads the bearer token from path instead of the service account token.
+func WithTokenFile(path string) Option {
+	return func(s *Syncer) {
+		s.tokenFile = path
+	}
+}
+
+// WithCAFile verifies the API server with the CA bundle at path instead of the service
+// account CA. It's ignored when WithHTTPClient is used.
+func WithCAFile(path string) Option {
+	return func(s *Syncer) {
+		s.caFile = path
+	}
+}
+
+// WithHTTPClient replaces the HTTP client used to reach the API server, which must then
+// trust its certificate.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Syncer) {
+		s.client = client
+	}
+}
+
+// WithFieldManager sets the field manager owning the applied fields. Defaults to
+// DefaultFieldManager.
+func WithFieldManager(name string) Option {
+	return func(s *Syncer) {
+		s.fieldManager = name
+	}
+}
+
+// NewSyncer creates a Syncer exporting the cache of c, which must implement
+// secretsmanager.Snapshotter, with the credentials of the pod's service account.
+//
+// Parameters:
+//   - c: The secret client whose cache is exported
+//   - opts: Optional settings such as the API server or token file
+//
+// Returns:
+//   - A Syncer writing to the API server
+//   - ErrNotInCluster if the API server cannot be located
+//   - An error if the CA bundle cannot be read
+func NewSyncer(c sm.SecretClient, opts ...Option) (*Syncer, error) {
+	s := &Syncer{
+		source:       c,
+		tokenFile:    serviceAccountDir + "/token",
+		caFile:       serviceAccountDir + "/ca.crt",
+		fieldManager: DefaultFieldManager,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.apiServer == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, ErrNotInCluster
+		}
+
+		s.apiServer = "https://" + net.JoinHostPort(host, port)
+	}
+
+	if s.client == nil {
+		client, err := newHTTPClient(s.caFile)
+		if err != nil {
+			return nil, err
+		}
+
+		s.client = client
+	}
+
+	return s, nil
+}
+
+// SyncToK8sSecret creates or updates the Opaque Secret name in namespace with the current
+// cache of the client, one data key per secret key.
+//
+// The Secret is written with server-side apply, without forcing: fields owned by another
+// field manager, such as a key also set by kubectl, make the sync fail with an error
+// matching secretsmanager.ErrConflict instead of being overwritten. Keys removed from the
+// cache since the previous sync are removed from the Secret, while labels, annotations
+// and keys managed by others are preserved.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//   - namespace: The namespace of the Secret
+//   - name: The name of the Secret
+//
+// Returns:
+//   - ErrSnapshotUnsupported if the client cannot export its cache
+//   - ErrInvalidKey if a key isn't a valid Kubernetes Secret key
+//   - secretsmanager.ErrConflict if a key is owned by another field manager
+//   - An error if the token cannot be read or the API server rejects the request
+func (s *Syncer) SyncToK8sSecret(ctx context.Context, namespace, name string) error {
+	snapshotter, ok := s.source.(sm.Snapshotter)
+	if !ok {
+		return ErrSnapshotUnsupported
+	}
+
+	secrets, err := snapshotter.Snapshot(ctx)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string][]byte, len(secrets))
+	var invalid []string
+	for key, value := range secrets {
+		if !validKey.MatchString(key) {
+			invalid = append(invalid, key)
+			continue
+		}
+
+		data[key] = []byte(value)
+	}
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return fmt.Errorf("%w: %s", ErrInvalidKey, strings.Join(invalid, ", "))
+	}
+
+	body, err := json.Marshal(secretApply{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   secretMetadata{Name: name, Namespace: namespace},
+		Type:       "Opaque",
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+
+	token, err := os.ReadFile(s.tokenFile)
+	if err != nil {
+		return fmt.Errorf("read service account token: %w", err)
+	}
+
+	query := url.Values{"fieldManager": {s.fieldManager}}
+	endpoint := fmt.Sprintf("%s/api/v1/namespaces/%s/secrets/%s?%s",
+		s.apiServer, url.PathEscape(namespace), url.PathEscape(name), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", applyPatchContentType)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("apply secret %s/%s: %w", namespace, name, err)
+	}
+	defer res.Body.Close()
+
+	// Error bodies aren't reported, since they may echo the applied object
+	switch {
+	case res.StatusCode == http.StatusConflict:
+		return fmt.Errorf("%w: secret %s/%s has keys owned by another field manager", sm.ErrConflict, namespace, name)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return fmt.Errorf("apply secret %s/%s: unexpected status %d", namespace, name, res.StatusCode)
+	}
+
+	return nil
+}
+
+// newHTTPClient creates a client trusting the CA bundle at caFile.
+func newHTTPClient(caFile string) (*http.Client, error) {
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read kubernetes ca bundle: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("parse kubernetes ca bundle %s: no certificate found", caFile)
+	}
+
+	return &http.Client{
+		Timeout: DefaultTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		},
+	}, nil
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package kubernetes_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+	"github.com/goxkit/secretsmanager/kubernetes"
+)
+
+// fakeAPIServer records the Secrets applied to it, answering with status.
+type fakeAPIServer struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (f *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body)
+	status := f.status
+	f.mu.Unlock()
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(`{}`))
+}
+
+// newSyncer starts a fake API server over TLS and returns a Syncer exporting source to
+// it, trusting its certificate through a CA file.
+func newSyncer(t *testing.T, source sm.SecretClient) (*kubernetes.Syncer, *fakeAPIServer) {
+	t.Helper()
+
+	api := &fakeAPIServer{}
+	srv := httptest.NewTLSServer(api)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	tokenFile := filepath.Join(dir, "token")
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tokenFile, []byte("sa-t0ken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := kubernetes.NewSyncer(source,
+		kubernetes.WithAPIServer(srv.URL+"/"),
+		kubernetes.WithCAFile(caFile),
+		kubernetes.WithTokenFile(tokenFile),
+	)
+	if err != nil {
+		t.Fatalf("NewSyncer() error = %v", err)
+	}
+
+	return s, api
+}
+
+func newSource(t *testing.T, seed map[string]string) *fake.FakeClient {
+	t.Helper()
+
+	c := fake.NewFakeClient(fake.WithSeed(seed))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestSyncToK8sSecret(t *testing.T) {
+	s, api := newSyncer(t, newSource(t, map[string]string{"db_password": "s3cret", "tls.crt": "CERT"}))
+
+	if err := s.SyncToK8sSecret(context.Background(), "payments", "app-secrets"); err != nil {
+		t.Fatalf("SyncToK8sSecret() error = %v", err)
+	}
+
+	if len(api.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(api.requests))
+	}
+
+	req := api.requests[0]
+	if req.Method != http.MethodPatch || req.URL.Path != "/api/v1/namespaces/payments/secrets/app-secrets" {
+		t.Errorf("request = %s %s, want a PATCH of the Secret", req.Method, req.URL.Path)
+	}
+	if got := req.URL.Query().Get("fieldManager"); got != kubernetes.DefaultFieldManager {
+		t.Errorf("fieldManager = %q, want %q", got, kubernetes.DefaultFieldManager)
+	}
+	if got := req.URL.Query().Get("force"); got != "" {
+		t.Errorf("force = %q, want the apply never forced", got)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/apply-patch+yaml" {
+		t.Errorf("Content-Type = %q, want server-side apply", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer sa-t0ken" {
+		t.Errorf("Authorization = %q, want the trimmed service account token", got)
+	}
+
+	var applied struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Type       string            `json:"type"`
+		Metadata   map[string]string `json:"metadata"`
+		Data       map[string][]byte `json:"data"`
+	}
+	if err := json.Unmarshal(api.bodies[0], &applied); err != nil {
+		t.Fatal(err)
+	}
+
+	if applied.APIVersion != "v1" || applied.Kind != "Secret" || applied.Type != "Opaque" {
+		t.Errorf("applied %s %s of type %s, want a v1 Opaque Secret", applied.APIVersion, applied.Kind, applied.Type)
+	}
+	if applied.Metadata["name"] != "app-secrets" || applied.Metadata["namespace"] != "payments" {
+		t.Errorf("metadata = %v, want the requested name and namespace", applied.Metadata)
+	}
+	if string(applied.Data["db_password"]) != "s3cret" || string(applied.Data["tls.crt"]) != "CERT" || len(applied.Data) != 2 {
+		t.Errorf("data holds %d keys, want the cache exported", len(applied.Data))
+	}
+}
+
+func TestSyncToK8sSecretConflict(t *testing.T) {
+	s, api := newSyncer(t, newSource(t, map[string]string{"db_password": "s3cret"}))
+	api.status = http.StatusConflict
+
+	err := s.SyncToK8sSecret(context.Background(), "payments", "app-secrets")
+	if !errors.Is(err, sm.ErrConflict) {
+		t.Errorf("SyncToK8sSecret() error = %v, want ErrConflict", err)
+	}
+}
+
+func TestSyncToK8sSecretInvalidKeys(t *testing.T) {
+	s, api := newSyncer(t, newSource(t, map[string]string{"db/password": "s3cret", "ok": "v"}))
+
+	err := s.SyncToK8sSecret(context.Background(), "payments", "app-secrets")
+	if !errors.Is(err, kubernetes.ErrInvalidKey) {
+		t.Fatalf("SyncToK8sSecret() error = %v, want ErrInvalidKey", err)
+	}
+	if !strings.Contains(err.Error(), "db/password") || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error %q should name the key without the value", err)
+	}
+	if len(api.requests) != 0 {
+		t.Errorf("requests = %d, want nothing applied", len(api.requests))
+	}
+}
+
+// staticClient is a SecretClient that cannot export its cache.
+type staticClient struct{}
+
+func (staticClient) LoadSecrets(context.Context) error { return nil }
+
+func (staticClient) GetSecret(context.Context, string) (string, error) {
+	return "", sm.ErrSecretNotFound
+}
+
+func TestSyncToK8sSecretSnapshotUnsupported(t *testing.T) {
+	s, _ := newSyncer(t, staticClient{})
+
+	if err := s.SyncToK8sSecret(context.Background(), "payments", "app-secrets"); !errors.Is(err, kubernetes.ErrSnapshotUnsupported) {
+		t.Errorf("SyncToK8sSecret() error = %v, want ErrSnapshotUnsupported", err)
+	}
+}
+
+func TestNewSyncerNotInCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+
+	if _, err := kubernetes.NewSyncer(staticClient{}); !errors.Is(err, kubernetes.ErrNotInCluster) {
+		t.Errorf("NewSyncer() error = %v, want ErrNotInCluster", err)
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+// DefaultMaxNegativeEntries is the largest number of misses remembered by default with
+// WithNegativeCacheTTL.
+const DefaultMaxNegativeEntries = 10000
+
+type (
+	// FetchFunc retrieves a single secret value by key from a backend that stores secrets
+	// as discrete entries. It should return ErrSecretNotFound when the key doesn't exist.
+	FetchFunc func(ctx context.Context, key string) (string, error)
+
+	// LazyClient is a SecretClient that fetches each secret on first access instead of
+	// loading everything upfront. It suits backends holding many discrete secrets of which
+	// an application only uses a few, reducing startup cost for sparse access patterns.
+	LazyClient struct {
+		fetch       FetchFunc
+		noCache     bool          // Whether every GetSecret fetches from the backend
+		negativeTTL time.Duration // How long a missing key is remembered, zero disables it
+		maxEntries  int           // Largest number of cached values, zero means unbounded
+		maxMisses   int           // Largest number of cached misses
+		skew        time.Duration // Clock skew tolerated before an expiry is considered reached
+		retries     int           // Retries of a failed fetch, zero disables them
+		budget      *RetryBudget  // Budget consumed by retries, nil leaves them unbounded
+		backoff     backoff       // Wait between two retries
+		clock       Clock
+
+		mu       sync.RWMutex
+		secrets  map[string]redact.String // In-memory cache of fetched key-value pairs
+		negative map[string]time.Time     // Expiry of the cached misses
+		misses   *list.List               // Cached misses from oldest to newest
+		missElem map[string]*list.Element // Position of each cached miss in misses
+		recency  *list.List               // Cached keys from most to least recently used
+		elements map[string]*list.Element // Position of each cached key in recency
+		groups   map[string][]string      // Keys prefetched when a trigger key is accessed
+		inFlight map[string]struct{}      // Keys being prefetched in the background
+	}
+
+	// LazyOption configures optional behavior of a LazyClient.
+	LazyOption func(*LazyClient)
+)
+
+// WithNoCache disables caching: every GetSecret fetches the value from the backend, so
+// callers always observe the latest value. Use it only for low-traffic tools, since each
+// lookup costs a backend call.
+func WithNoCache() LazyOption {
+	return func(l *LazyClient) {
+		l.noCache = true
+	}
+}
+
+// WithNegativeCacheTTL remembers keys reported as ErrSecretNotFound for ttl, so repeated
+// lookups of a key that doesn't exist are answered without hitting the backend again.
+// Other fetch errors are never cached. At most DefaultMaxNegativeEntries misses are
+// remembered, the oldest being forgotten first, so lookups of arbitrary keys cannot grow
+// memory without bound. Disabled by default.
+func WithNegativeCacheTTL(ttl time.Duration) LazyOption {
+	return func(l *LazyClient) {
+		l.negativeTTL = ttl
+	}
+}
+
+// WithMaxNegativeEntries bounds the misses remembered with WithNegativeCacheTTL to n.
+// Defaults to DefaultMaxNegativeEntries.
+func WithMaxNegativeEntries(n int) LazyOption {
+	return func(l *LazyClient) {
+		l.maxMisses = n
+	}
+}
+
+// WithMaxEntries bounds the cache to n values, evicting the least recently used one when
+// a new value is fetched past the limit. Evicted keys are fetched again on their next
+// access. It keeps memory bounded for backends with huge key spaces. Unbounded by default.
+func WithMaxEntries(n int) LazyOption {
+	return func(l *LazyClient) {
+		l.maxEntries = n
+	}
+}
+
+// WithClock sets the clock used for expiry checks. Defaults to SystemClock.
+func WithClock(clock Clock) LazyOption {
+	return func(l *LazyClient) {
+		l.clock = clock
+	}
+}
+
+// WithSkewTolerance delays every expiry check by d, so a cached miss is only considered
+// expired once the clock is past its expiry by more than d. It absorbs clock drift
+// between nodes and avoids entries flapping around the boundary. Zero by default.
+func WithSkewTolerance(d time.Duration) LazyOption {
+	return func(l *LazyClient) {
+		l.skew = d
+	}
+}
+
+// WithRetries retries a failed fetch up to retries times, each retry consuming a token of
+// budget, including those of background prefetches. Retries are spaced by a jittered
+// exponential backoff, see WithRetryBackoff. When budget runs out, the fetch fails fast
+// with an error matching both ErrRetryBudgetExhausted and the last fetch error.
+// ErrSecretNotFound and context errors are never retried. Share budget with the provider
+// behind fetch to bound the total retry rate. A nil budget only bounds retries per fetch.
+// Disabled by default.
+func WithRetries(retries int, budget *RetryBudget) LazyOption {
+	return func(l *LazyClient) {
+		l.retries = retries
+		l.budget = budget
+	}
+}
+
+// WithRetryBackoff sets the longest wait before the first retry, doubled on every
+// following retry up to maxDelay. Each wait is picked at random below that limit. A zero
+// base retries immediately. Defaults to DefaultRetryBaseDelay and DefaultRetryMaxDelay.
+func WithRetryBackoff(base, maxDelay time.Duration) LazyOption {
+	return func(l *LazyClient) {
+		l.backoff = backoff{base: base, max: maxDelay}
+	}
+}
+
+// NewLazyClient creates a LazyClient fetching secrets through fetch.
+//
+// Parameters:
+//   - fetch: Function retrieving a single secret from the backend
+//   - opts: Optional settings of the client
+//
+// Returns:
+//   - A LazyClient with an empty cache
+func NewLazyClient(fetch FetchFunc, opts ...LazyOption) *LazyClient {
+	l := &LazyClient{
+		fetch:     fetch,
+		clock:     SystemClock,
+		backoff:   backoff{base: DefaultRetryBaseDelay, max: DefaultRetryMaxDelay},
+		secrets:   make(map[string]redact.String),
+		maxMisses: DefaultMaxNegativeEntries,
+		negative:  make(map[string]time.Time),
+		misses:    list.New(),
+		missElem:  make(map[string]*list.Element),
+		recency:   list.New(),
+		elements:  make(map[string]*list.Element),
+		groups:    make(map[string][]string),
+		inFlight:  make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// LoadSecrets does nothing since secrets are fetched on first access. It exists to
+// satisfy the SecretClient interface.
+func (l *LazyClient) LoadSecrets(_ context.Context) error {
+	return nil
+}
+
+// GetSecret returns the cached value of key, fetching and caching it on first access.
+//
+// Parameters:
+//   - ctx: Context forwarded to the fetch function on a cache miss
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - ErrSecretNotFound, as reported by the fetch function, if the key doesn't exist
+//   - An error if the secret cannot be fetched
+func (l *LazyClient) GetSecret(ctx context.Context, key string) (string, error) {
+	if l.noCache {
+		return l.fetchWithRetries(ctx, key)
+	}
+
+	value, ok, missUntil, missed := l.lookup(key)
+	if ok {
+		l.prefetch(ctx, key)
+		return value, nil
+	}
+
+	if missed && l.clock.Now().Before(missUntil.Add(l.skew)) {
+		return "", ErrSecretNotFound
+	}
+
+	value, err := l.fetchAndCache(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	l.prefetch(ctx, key)
+
+	return value, nil
+}
+
+// PrefetchGroup registers keys that are usually needed together with trigger, such as
+// the parts of a credential. Every successful GetSecret of trigger fetches the grouped
+// keys not cached yet in the background, so their own lookups hit a warm cache. Prefetch
+// failures are ignored and never affect the lookup of trigger; a failed key is simply
+// fetched again on its first access. Calling it again for the same trigger adds keys to
+// its group. Prefetching is disabled with WithNoCache.
+//
+// Parameters:
+//   - trigger: The key whose access starts the prefetch
+//   - others: The keys to fetch in the background
+func (l *LazyClient) PrefetchGroup(trigger string, others ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.groups[trigger] = append(l.groups[trigger], others...)
+}
+
+// prefetch fetches in the background the keys grouped with trigger that aren't cached,
+// remembered as missing, or already being prefetched. The fetches outlive ctx's
+// cancellation, since GetSecret returns before they complete, but keep its values.
+func (l *LazyClient) prefetch(ctx context.Context, trigger string) {
+	l.mu.RLock()
+	grouped := len(l.groups[trigger]) > 0
+	l.mu.RUnlock()
+
+	if !grouped {
+		return
+	}
+
+	l.mu.Lock()
+	var pending []string
+	now := l.clock.Now()
+	for _, key := range l.groups[trigger] {
+		if _, ok := l.secrets[key]; ok {
+			continue
+		}
+		if until, ok := l.negative[key]; ok && now.Before(until.Add(l.skew)) {
+			continue
+		}
+		if _, ok := l.inFlight[key]; ok {
+			continue
+		}
+
+		l.inFlight[key] = struct{}{}
+		pending = append(pending, key)
+	}
+	l.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range pending {
+		go func() {
+			_, _ = l.fetchAndCache(ctx, key)
+
+			l.mu.Lock()
+			delete(l.inFlight, key)
+			l.mu.Unlock()
+		}()
+	}
+}
+
+// fetchAndCache fetches key and caches its value, or its miss when a negative cache TTL
+// is set.
+func (l *LazyClient) fetchAndCache(ctx context.Context, key string) (string, error) {
+	value, err := l.fetchWithRetries(ctx, key)
+	if err != nil {
+		if l.negativeTTL > 0 && errors.Is(err, ErrSecretNotFound) {
+			l.mu.Lock()
+			l.cacheMiss(key)
+			l.mu.Unlock()
+		}
+
+		return "", err
+	}
+
+	l.mu.Lock()
+	l.secrets[key] = redact.New(value)
+	l.forgetMiss(key)
+	l.touch(key)
+	l.mu.Unlock()
+
+	return value, nil
+}
+
+// fetchWithRetries fetches key, retrying failures as configured with WithRetries.
+func (l *LazyClient) fetchWithRetries(ctx context.Context, key string) (string, error) {
+	value, err := l.fetch(ctx, key)
+
+	for attempt := 0; attempt < l.retries && err != nil; attempt++ {
+		if errors.Is(err, ErrSecretNotFound) || ctx.Err() != nil {
+			break
+		}
+
+		if l.budget != nil && !l.budget.Allow() {
+			return "", fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, err)
+		}
+
+		if waitErr := l.backoff.wait(ctx, attempt); waitErr != nil {
+			return "", fmt.Errorf("%w: %w", waitErr, err)
+		}
+
+		value, err = l.fetch(ctx, key)
+	}
+
+	return value, err
+}
+
+// lookup returns the cached value of key and its cached miss, marking a cached value as
+// recently used when the cache is bounded.
+func (l *LazyClient) lookup(key string) (value string, ok bool, missUntil time.Time, missed bool) {
+	if l.maxEntries <= 0 {
+		l.mu.RLock()
+		defer l.mu.RUnlock()
+	} else {
+		// Recording the access mutates the recency list
+		l.mu.Lock()
+		defer l.mu.Unlock()
+	}
+
+	cached, ok := l.secrets[key]
+	missUntil, missed = l.negative[key]
+
+	if ok {
+		l.touch(key)
+	}
+
+	return cached.Reveal(), ok, missUntil, missed
+}
+
+// touch marks key as the most recently used one and evicts the least recently used keys
+// past the limit. It must be called with mu held for writing, and does nothing when the
+// cache is unbounded.
+func (l *LazyClient) touch(key string) {
+	if l.maxEntries <= 0 {
+		return
+	}
+
+	if elem, ok := l.elements[key]; ok {
+		l.recency.MoveToFront(elem)
+	} else {
+		l.elements[key] = l.recency.PushFront(key)
+	}
+
+	for l.recency.Len() > l.maxEntries {
+		oldest := l.recency.Back()
+		evicted := l.recency.Remove(oldest).(string)
+		delete(l.elements, evicted)
+		delete(l.secrets, evicted)
+	}
+}
+
+// cacheMiss remembers key as missing for the negative cache TTL, then forgets the expired
+// misses and the oldest ones past the limit. It must be called with mu held for writing.
+func (l *LazyClient) cacheMiss(key string) {
+	now := l.clock.Now()
+	l.negative[key] = now.Add(l.negativeTTL)
+
+	if elem, ok := l.missElem[key]; ok {
+		l.misses.MoveToBack(elem)
+	} else {
+		l.missElem[key] = l.misses.PushBack(key)
+	}
+
+	// Misses share the same TTL, so the oldest ones expire first
+	for oldest := l.misses.Front(); oldest != nil; oldest = l.misses.Front() {
+		expired := !now.Before(l.negative[oldest.Value.(string)].Add(l.skew))
+		if !expired && l.misses.Len() <= max(l.maxMisses, 1) {
+			break
+		}
+
+		l.forgetMiss(oldest.Value.(string))
+	}
+}
+
+// forgetMiss drops the cached miss of key, if any. It must be called with mu held for
+// writing.
+func (l *LazyClient) forgetMiss(key string) {
+	delete(l.negative, key)
+
+	if elem, ok := l.missElem[key]; ok {
+		l.misses.Remove(elem)
+		delete(l.missElem, key)
+	}
+}
+
+// Invalidate drops key, or its cached miss, so the next GetSecret fetches it again.
+func (l *LazyClient) Invalidate(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.secrets, key)
+	l.forgetMiss(key)
+
+	if elem, ok := l.elements[key]; ok {
+		l.recency.Remove(elem)
+		delete(l.elements, key)
+	}
+}
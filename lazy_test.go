@@ -0,0 +1,365 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+type (
+	// manualClock is a Clock only moving when advanced.
+	manualClock struct {
+		mu  sync.Mutex
+		now time.Time
+	}
+
+	// countingFetch serves values from a map, counting the fetches of each key.
+	countingFetch struct {
+		mu     sync.Mutex
+		values map[string]string
+		calls  map[string]int
+	}
+)
+
+func newManualClock() *manualClock {
+	return &manualClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newCountingFetch(values map[string]string) *countingFetch {
+	return &countingFetch{values: values, calls: map[string]int{}}
+}
+
+func (f *countingFetch) Fetch(_ context.Context, key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[key]++
+
+	value, ok := f.values[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value, nil
+}
+
+func (f *countingFetch) Calls(key string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[key]
+}
+
+func TestLazyClientNegativeCacheExpires(t *testing.T) {
+	clock := newManualClock()
+	backend := newCountingFetch(nil)
+	c := sm.NewLazyClient(backend.Fetch, sm.WithNegativeCacheTTL(time.Minute), sm.WithClock(clock))
+
+	ctx := context.Background()
+	for range 3 {
+		if _, err := c.GetSecret(ctx, "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+			t.Fatalf("GetSecret() error = %v, want ErrSecretNotFound", err)
+		}
+	}
+
+	if got := backend.Calls("missing"); got != 1 {
+		t.Errorf("fetches within the TTL = %d, want 1", got)
+	}
+
+	clock.Advance(2 * time.Minute)
+	_, _ = c.GetSecret(ctx, "missing")
+
+	if got := backend.Calls("missing"); got != 2 {
+		t.Errorf("fetches after the TTL = %d, want 2", got)
+	}
+}
+
+func TestLazyClientSkewTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		skew      time.Duration
+		advance   time.Duration
+		wantCalls int
+	}{
+		{name: "at the expiry without skew", advance: time.Minute, wantCalls: 2},
+		{name: "within the skew", skew: 10 * time.Second, advance: time.Minute + 9*time.Second, wantCalls: 1},
+		{name: "at the end of the skew", skew: 10 * time.Second, advance: time.Minute + 10*time.Second, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newManualClock()
+			backend := newCountingFetch(nil)
+			c := sm.NewLazyClient(backend.Fetch, sm.WithNegativeCacheTTL(time.Minute), sm.WithClock(clock), sm.WithSkewTolerance(tt.skew))
+
+			ctx := context.Background()
+			_, _ = c.GetSecret(ctx, "missing")
+
+			clock.Advance(tt.advance)
+			_, _ = c.GetSecret(ctx, "missing")
+
+			if got := backend.Calls("missing"); got != tt.wantCalls {
+				t.Errorf("fetches = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestLazyClientNegativeCacheIsBounded(t *testing.T) {
+	backend := newCountingFetch(nil)
+	c := sm.NewLazyClient(backend.Fetch,
+		sm.WithNegativeCacheTTL(time.Hour),
+		sm.WithMaxNegativeEntries(2),
+		sm.WithClock(newManualClock()))
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		_, _ = c.GetSecret(ctx, key)
+	}
+
+	// "a" was evicted as the oldest miss, "b" and "c" are still remembered
+	for _, key := range []string{"b", "c", "a"} {
+		_, _ = c.GetSecret(ctx, key)
+	}
+
+	want := map[string]int{"a": 2, "b": 1, "c": 1}
+	for key, calls := range want {
+		if got := backend.Calls(key); got != calls {
+			t.Errorf("fetches of %q = %d, want %d", key, got, calls)
+		}
+	}
+}
+
+func TestLazyClientNegativeCacheForgetsFoundKeys(t *testing.T) {
+	backend := newCountingFetch(nil)
+	c := sm.NewLazyClient(backend.Fetch, sm.WithNegativeCacheTTL(time.Hour), sm.WithClock(newManualClock()))
+
+	ctx := context.Background()
+	_, _ = c.GetSecret(ctx, "late")
+
+	backend.mu.Lock()
+	backend.values = map[string]string{"late": "value"}
+	backend.mu.Unlock()
+
+	c.Invalidate("late")
+
+	value, err := c.GetSecret(ctx, "late")
+	if err != nil || value != "value" {
+		t.Errorf("GetSecret() = %q, %v, want the value once invalidated", value, err)
+	}
+}
+
+func TestLazyClientRetriesAreThrottledByBudget(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+
+	var mu sync.Mutex
+	calls := 0
+	fetch := func(context.Context, string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls++
+		return "", errBackend
+	}
+
+	budget := sm.NewRetryBudget(2, time.Hour)
+	c := sm.NewLazyClient(fetch, sm.WithRetries(5, budget), sm.WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	ctx := context.Background()
+	_, err := c.GetSecret(ctx, "key")
+	if !errors.Is(err, sm.ErrRetryBudgetExhausted) || !errors.Is(err, errBackend) {
+		t.Fatalf("GetSecret() error = %v, want ErrRetryBudgetExhausted wrapping the fetch error", err)
+	}
+
+	// The sustained failure keeps the budget empty, so later fetches fail fast
+	for range 3 {
+		_, _ = c.GetSecret(ctx, "key")
+	}
+
+	if calls != 6 {
+		t.Errorf("fetches = %d, want 3 for the first lookup and 1 for each later one", calls)
+	}
+	if got := budget.Available(); got != 0 {
+		t.Errorf("Available() = %d, want 0", got)
+	}
+}
+
+func TestLazyClientRetryBackoffStopsWithContext(t *testing.T) {
+	fetch := func(context.Context, string) (string, error) {
+		return "", errors.New("backend unavailable")
+	}
+
+	c := sm.NewLazyClient(fetch, sm.WithRetries(3, nil), sm.WithRetryBackoff(time.Hour, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.GetSecret(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetSecret() error = %v, want the backoff interrupted by the context", err)
+	}
+}
+
+func TestLazyClientFetchesOnce(t *testing.T) {
+	backend := newCountingFetch(map[string]string{"db_password": "s3cret", "unused": "value"})
+	c := sm.NewLazyClient(backend.Fetch)
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if got := backend.Calls("db_password") + backend.Calls("unused"); got != 0 {
+		t.Fatalf("fetches on load = %d, want none", got)
+	}
+
+	for range 3 {
+		value, err := c.GetSecret(ctx, "db_password")
+		if err != nil || value != "s3cret" {
+			t.Fatalf("GetSecret() = %q, %v, want s3cret", value, err)
+		}
+	}
+
+	if got := backend.Calls("db_password"); got != 1 {
+		t.Errorf("fetches of db_password = %d, want 1", got)
+	}
+	if got := backend.Calls("unused"); got != 0 {
+		t.Errorf("fetches of unused = %d, want 0", got)
+	}
+}
+
+func TestLazyClientInvalidate(t *testing.T) {
+	backend := newCountingFetch(map[string]string{"api_token": "v1", "db_password": "s3cret"})
+	c := sm.NewLazyClient(backend.Fetch)
+
+	ctx := context.Background()
+	_, _ = c.GetSecret(ctx, "api_token")
+	_, _ = c.GetSecret(ctx, "db_password")
+
+	backend.mu.Lock()
+	backend.values["api_token"] = "v2"
+	backend.mu.Unlock()
+
+	c.Invalidate("api_token")
+
+	if value, _ := c.GetSecret(ctx, "api_token"); value != "v2" {
+		t.Errorf("GetSecret() = %q after Invalidate, want the rotated value", value)
+	}
+	if got := backend.Calls("api_token"); got != 2 {
+		t.Errorf("fetches of the invalidated key = %d, want 2", got)
+	}
+
+	_, _ = c.GetSecret(ctx, "db_password")
+	if got := backend.Calls("db_password"); got != 1 {
+		t.Errorf("fetches of another key = %d, want it still cached", got)
+	}
+}
+
+func TestLazyClientNoCache(t *testing.T) {
+	backend := newCountingFetch(map[string]string{"api_token": "v1"})
+	c := sm.NewLazyClient(backend.Fetch, sm.WithNoCache())
+
+	ctx := context.Background()
+	for i, want := range []string{"v1", "v2", "v3"} {
+		backend.mu.Lock()
+		backend.values["api_token"] = want
+		backend.mu.Unlock()
+
+		if value, _ := c.GetSecret(ctx, "api_token"); value != want {
+			t.Errorf("GetSecret() = %q, want the latest value %q", value, want)
+		}
+		if got := backend.Calls("api_token"); got != i+1 {
+			t.Errorf("fetches = %d after %d lookups, want one per lookup", got, i+1)
+		}
+	}
+}
+
+func TestLazyClientNegativeCacheClearedByLaterLoad(t *testing.T) {
+	clock := newManualClock()
+	backend := newCountingFetch(map[string]string{})
+	c := sm.NewLazyClient(backend.Fetch, sm.WithNegativeCacheTTL(time.Minute), sm.WithClock(clock))
+
+	ctx := context.Background()
+	_, _ = c.GetSecret(ctx, "late")
+	_, _ = c.GetSecret(ctx, "late")
+	if got := backend.Calls("late"); got != 1 {
+		t.Fatalf("fetches within the TTL = %d, want 1", got)
+	}
+
+	backend.mu.Lock()
+	backend.values["late"] = "value"
+	backend.mu.Unlock()
+	clock.Advance(2 * time.Minute)
+
+	if value, err := c.GetSecret(ctx, "late"); err != nil || value != "value" {
+		t.Fatalf("GetSecret() = %q, %v, want the value once the miss expired", value, err)
+	}
+
+	// The positive load replaced the miss, so the key stays served after another TTL
+	clock.Advance(2 * time.Minute)
+	if value, err := c.GetSecret(ctx, "late"); err != nil || value != "value" {
+		t.Errorf("GetSecret() = %q, %v, want the cached value", value, err)
+	}
+	if got := backend.Calls("late"); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
+
+func TestLazyClientPrefetchGroup(t *testing.T) {
+	backend := newCountingFetch(map[string]string{
+		"db_user":     "admin",
+		"db_password": "s3cret",
+		"db_host":     "db.internal",
+	})
+	c := sm.NewLazyClient(backend.Fetch)
+	c.PrefetchGroup("db_user", "db_password", "db_host", "db_missing")
+
+	ctx := context.Background()
+	if value, err := c.GetSecret(ctx, "db_user"); err != nil || value != "admin" {
+		t.Fatalf("GetSecret() = %q, %v, want the trigger served despite a failing prefetch", value, err)
+	}
+
+	// Wait for the background fetches to complete and their values to be cached
+	deadline := time.Now().Add(time.Second)
+	for backend.Calls("db_password") == 0 || backend.Calls("db_host") == 0 || backend.Calls("db_missing") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("grouped keys not prefetched after the trigger")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	for key, want := range map[string]string{"db_password": "s3cret", "db_host": "db.internal"} {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+		if got := backend.Calls(key); got != 1 {
+			t.Errorf("fetches of %q = %d, want it served from the warm cache", key, got)
+		}
+	}
+
+	_, _ = c.GetSecret(ctx, "db_user")
+	if got := backend.Calls("db_password"); got != 1 {
+		t.Errorf("fetches of a cached grouped key = %d after another trigger, want 1", got)
+	}
+}
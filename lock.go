@@ -0,0 +1,29 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import "context"
+
+type (
+	// Locker coordinates writes across processes, such as several instances reacting to
+	// the same rotation. Implementations typically wrap a DynamoDB or Redis lock.
+	Locker interface {
+		// Lock blocks until the lock called name is acquired or ctx is done.
+		Lock(ctx context.Context, name string) error
+
+		// Unlock releases the lock called name acquired by Lock.
+		Unlock(ctx context.Context, name string) error
+	}
+
+	// nopLocker is the Locker acquiring nothing.
+	nopLocker struct{}
+)
+
+// NopLocker is the default Locker, leaving writes serialized within the process only.
+var NopLocker Locker = nopLocker{}
+
+func (nopLocker) Lock(context.Context, string) error { return nil }
+
+func (nopLocker) Unlock(context.Context, string) error { return nil }
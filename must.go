@@ -0,0 +1,55 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// mustError is the panic value of MustGetSecret. Its message names the key only, since
+// the error of a client it wraps may come from a third-party provider whose messages
+// aren't known to be free of secret material.
+type mustError struct {
+	key string
+	err error
+}
+
+func (e *mustError) Error() string {
+	if errors.Is(e.err, ErrSecretNotFound) {
+		return fmt.Sprintf("secretsmanager: required secret %q was not found", e.key)
+	}
+
+	return fmt.Sprintf("secretsmanager: required secret %q cannot be retrieved", e.key)
+}
+
+// Unwrap returns the error of GetSecret, so recovered panics can still be matched.
+func (e *mustError) Unwrap() error {
+	return e.err
+}
+
+// MustGetSecret retrieves the secret stored under key and panics if it cannot be
+// retrieved. It's meant for initialization code where a missing secret is fatal.
+//
+// The panic value is an error whose message only names the key, never a value or the
+// message of the underlying error, so it's safe to print in crash reports. The underlying
+// error remains reachable through errors.Is and errors.As on the recovered value.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value
+func MustGetSecret(ctx context.Context, c SecretClient, key string) string {
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		panic(&mustError{key: key, err: err})
+	}
+
+	return value
+}
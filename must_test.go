@@ -0,0 +1,85 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// leakyClient fails every lookup with an error quoting a secret value, as a careless
+// third-party provider could.
+type leakyClient struct{}
+
+func (leakyClient) LoadSecrets(context.Context) error { return nil }
+
+func (leakyClient) GetSecret(_ context.Context, key string) (string, error) {
+	return "", fmt.Errorf("decode %s: unexpected token in %q", key, "s3cret-value")
+}
+
+// recoverPanic runs fn and returns the value it panicked with, or nil.
+func recoverPanic(fn func()) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+
+	fn()
+	return nil
+}
+
+func TestMustGetSecret(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{"db_password": "s3cret"})
+
+	var value string
+	if r := recoverPanic(func() { value = sm.MustGetSecret(context.Background(), c, "db_password") }); r != nil {
+		t.Fatalf("MustGetSecret() panicked with %v", r)
+	}
+	if value != "s3cret" {
+		t.Errorf("MustGetSecret() = %q, want s3cret", value)
+	}
+}
+
+func TestMustGetSecretPanicsWithoutValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		client sm.SecretClient
+		want   error
+	}{
+		{name: "missing key", client: newLoadedClient(t, map[string]string{"other": "s3cret-value"}), want: sm.ErrSecretNotFound},
+		{name: "leaky provider error", client: leakyClient{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := recoverPanic(func() { sm.MustGetSecret(context.Background(), tt.client, "db_password") })
+			if r == nil {
+				t.Fatal("MustGetSecret() didn't panic")
+			}
+
+			err, ok := r.(error)
+			if !ok {
+				t.Fatalf("panic value %T, want an error", r)
+			}
+
+			for _, out := range []string{err.Error(), fmt.Sprint(r), fmt.Sprintf("%+v", r)} {
+				if strings.Contains(out, "s3cret") {
+					t.Errorf("panic output %q exposes a value", out)
+				}
+				if !strings.Contains(out, "db_password") {
+					t.Errorf("panic output %q doesn't name the key", out)
+				}
+			}
+
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("recovered error = %v, want it to match %v", err, tt.want)
+			}
+		})
+	}
+}
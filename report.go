@@ -0,0 +1,72 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// LoadResult is the outcome of loading one secret identifier.
+	LoadResult struct {
+		SecretId string        // Identifier of the secret in the provider
+		Err      error         // Why the load failed, nil on success
+		Duration time.Duration // Time spent fetching the secret
+		Keys     int           // Number of keys the secret held, zero on failure
+	}
+
+	// LoadReport is the structured outcome of a load across every secret identifier,
+	// meant for startup dashboards and diagnostics. It never holds secret values.
+	LoadReport struct {
+		Results  []LoadResult  // One result per secret identifier, in precedence order
+		Duration time.Duration // Total time spent loading
+	}
+
+	// LoadReporter is implemented by providers able to report the outcome of a load per
+	// secret identifier instead of a single aggregate error.
+	LoadReporter interface {
+		// LoadSecretsReport loads the secrets like LoadSecrets and reports every identifier.
+		LoadSecretsReport(ctx context.Context) (LoadReport, error)
+	}
+)
+
+// Succeeded returns the identifiers that loaded successfully.
+func (r LoadReport) Succeeded() []string {
+	var ids []string
+	for _, res := range r.Results {
+		if res.Err == nil {
+			ids = append(ids, res.SecretId)
+		}
+	}
+
+	return ids
+}
+
+// Failed returns the identifiers that failed to load.
+func (r LoadReport) Failed() []string {
+	var ids []string
+	for _, res := range r.Results {
+		if res.Err != nil {
+			ids = append(ids, res.SecretId)
+		}
+	}
+
+	return ids
+}
+
+// Err joins the errors of the failed identifiers, or returns nil when all succeeded.
+func (r LoadReport) Err() error {
+	var errs []error
+	for _, res := range r.Results {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.SecretId, res.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
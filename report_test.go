@@ -0,0 +1,51 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestLoadReport(t *testing.T) {
+	errDenied := errors.New("access denied")
+
+	report := sm.LoadReport{
+		Results: []sm.LoadResult{
+			{SecretId: "dev/app", Duration: time.Millisecond, Keys: 3},
+			{SecretId: "dev/shared", Err: errDenied, Duration: 2 * time.Millisecond},
+			{SecretId: "dev/extra", Duration: time.Millisecond, Keys: 1},
+		},
+		Duration: 4 * time.Millisecond,
+	}
+
+	if got := report.Succeeded(); !slices.Equal(got, []string{"dev/app", "dev/extra"}) {
+		t.Errorf("Succeeded() = %v, want dev/app and dev/extra", got)
+	}
+	if got := report.Failed(); !slices.Equal(got, []string{"dev/shared"}) {
+		t.Errorf("Failed() = %v, want dev/shared", got)
+	}
+
+	err := report.Err()
+	if !errors.Is(err, errDenied) || !strings.Contains(err.Error(), "dev/shared") {
+		t.Errorf("Err() = %v, want the failure attributed to dev/shared", err)
+	}
+}
+
+func TestLoadReportAllSucceeded(t *testing.T) {
+	report := sm.LoadReport{Results: []sm.LoadResult{{SecretId: "dev/app", Keys: 1}}}
+
+	if err := report.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+	if got := report.Failed(); len(got) != 0 {
+		t.Errorf("Failed() = %v, want none", got)
+	}
+}
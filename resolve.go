@@ -0,0 +1,176 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Resolve walks the struct pointed to by out, including nested structs, pointers to
+// structs, and slices, arrays and maps of them, and replaces the fields tagged with
+// `secret` by the value of their secret. It's meant for an already populated configuration
+// struct, whose untagged fields are left untouched:
+//
+//	type Config struct {
+//		Host     string
+//		Password string `secret:"db_password,required"`
+//		Replicas []struct {
+//			Host     string
+//			Password string `secret:"db_replica_password"`
+//		}
+//		Regions map[string]struct {
+//			Token string `secret:"region_token"`
+//		}
+//	}
+//
+// Tags follow the format of Bind. Optional fields whose key doesn't exist keep their
+// current value, and unexported fields are skipped, since they cannot be set. Values held
+// by interfaces or maps are resolved on a copy stored back in place. Pointers are
+// followed once, so cyclic structures are walked without looping. Errors name the path of
+// the field, such as Replicas[1].Password, but never the value.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the values
+//   - out: A pointer to the struct to resolve
+//   - opts: Optional settings such as WithFuzzyKeys
+//
+// Returns:
+//   - An error aggregating every missing required secret or invalid field
+func Resolve(ctx context.Context, c SecretClient, out interface{}, opts ...BindOption) error {
+	target := reflect.ValueOf(out)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return ErrInvalidBindTarget
+	}
+
+	r := &resolver{
+		binder:  newBinder(c, opts),
+		visited: make(map[visit]struct{}),
+	}
+	r.resolveValue(ctx, target, "")
+
+	return errors.Join(r.errs...)
+}
+
+type (
+	// resolver holds the state of a Resolve walk.
+	resolver struct {
+		*binder
+		visited map[visit]struct{} // Pointers already followed
+		errs    []error
+	}
+
+	// visit identifies a followed pointer. The type is part of it since a struct and its
+	// first field share the same address.
+	visit struct {
+		ptr uintptr
+		typ reflect.Type
+	}
+)
+
+// resolveValue resolves the tagged fields reachable from v, whose path is used in errors.
+// Only addressable values are modified, since the others are copies.
+func (r *resolver) resolveValue(ctx context.Context, v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+
+		seen := visit{ptr: v.Pointer(), typ: v.Type()}
+		if _, ok := r.visited[seen]; ok {
+			return
+		}
+		r.visited[seen] = struct{}{}
+
+		r.resolveValue(ctx, v.Elem(), path)
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+
+		elem := v.Elem()
+		if elem.Kind() == reflect.Pointer {
+			r.resolveValue(ctx, elem, path)
+			return
+		}
+
+		// The value held by an interface isn't addressable: resolve a copy and store it back
+		if !v.CanSet() {
+			return
+		}
+
+		copied := reflect.New(elem.Type()).Elem()
+		copied.Set(elem)
+		r.resolveValue(ctx, copied, path)
+		v.Set(copied)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			r.resolveValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, elemPath := iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())
+			if elem.Kind() == reflect.Pointer {
+				r.resolveValue(ctx, elem, elemPath)
+				continue
+			}
+
+			// Map elements aren't addressable: resolve a copy and store it back
+			copied := reflect.New(elem.Type()).Elem()
+			copied.Set(elem)
+			r.resolveValue(ctx, copied, elemPath)
+			v.SetMapIndex(iter.Key(), copied)
+		}
+	case reflect.Struct:
+		if v.CanAddr() {
+			r.resolveStruct(ctx, v, path)
+		}
+	}
+}
+
+// resolveStruct resolves the tagged fields of v and descends into the other fields.
+func (r *resolver) resolveStruct(ctx context.Context, v reflect.Value, path string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		tag, ok := field.Tag.Lookup(secretTag)
+		if !ok || tag == "" || tag == "-" {
+			r.resolveValue(ctx, v.Field(i), fieldPath)
+			continue
+		}
+
+		key, required := parseSecretTag(tag)
+
+		value, err := r.get(ctx, key)
+		if errors.Is(err, ErrSecretNotFound) {
+			if required {
+				r.errs = append(r.errs, fmt.Errorf("field %s: required secret %q: %w", fieldPath, key, err))
+			}
+			continue
+		}
+		if err != nil {
+			r.errs = append(r.errs, fmt.Errorf("field %s: secret %q: %w", fieldPath, key, err))
+			continue
+		}
+
+		if err := setSecretField(v.Field(i), value); err != nil {
+			r.errs = append(r.errs, fmt.Errorf("field %s: %w", fieldPath, err))
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+type (
+	resolveInner struct {
+		Host     string
+		Password string `secret:"db_password,required"`
+	}
+
+	resolveNode struct {
+		Token string `secret:"api_token"`
+		Next  *resolveNode
+	}
+)
+
+func newResolveClient(t *testing.T) sm.SecretClient {
+	t.Helper()
+
+	c := fake.NewFakeClient(fake.WithSeed(map[string]string{
+		"db_password": "s3cret",
+		"api_token":   "t0ken",
+	}))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestResolveNestedStructs(t *testing.T) {
+	type config struct {
+		Primary  resolveInner
+		Pointer  *resolveInner
+		Replicas []resolveInner
+		Fixed    [1]resolveInner
+		ByName   map[string]*resolveInner
+		ByRegion map[string]resolveInner
+	}
+
+	cfg := config{
+		Primary:  resolveInner{Host: "primary"},
+		Pointer:  &resolveInner{},
+		Replicas: []resolveInner{{Host: "a"}, {Host: "b"}},
+		ByName:   map[string]*resolveInner{"eu": {}},
+		ByRegion: map[string]resolveInner{"us": {Host: "us"}},
+	}
+
+	if err := sm.Resolve(context.Background(), newResolveClient(t), &cfg); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	got := []string{
+		cfg.Primary.Password, cfg.Pointer.Password, cfg.Replicas[0].Password,
+		cfg.Replicas[1].Password, cfg.Fixed[0].Password, cfg.ByName["eu"].Password,
+		cfg.ByRegion["us"].Password,
+	}
+	for i, password := range got {
+		if password != "s3cret" {
+			t.Errorf("password %d = %q, want %q", i, password, "s3cret")
+		}
+	}
+
+	if cfg.Primary.Host != "primary" || cfg.ByRegion["us"].Host != "us" {
+		t.Errorf("untagged fields changed to %q and %q", cfg.Primary.Host, cfg.ByRegion["us"].Host)
+	}
+}
+
+func TestResolveInterfaceHoldingStruct(t *testing.T) {
+	cfg := struct{ Extra interface{} }{Extra: resolveInner{Host: "h"}}
+
+	if err := sm.Resolve(context.Background(), newResolveClient(t), &cfg); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	inner, ok := cfg.Extra.(resolveInner)
+	if !ok || inner.Password != "s3cret" || inner.Host != "h" {
+		t.Errorf("Extra = %#v, want the resolved struct", cfg.Extra)
+	}
+}
+
+func TestResolveInterfaceHoldingPointer(t *testing.T) {
+	inner := &resolveInner{}
+	cfg := struct{ Extra interface{} }{Extra: inner}
+
+	if err := sm.Resolve(context.Background(), newResolveClient(t), &cfg); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if inner.Password != "s3cret" {
+		t.Errorf("Password = %q, want %q", inner.Password, "s3cret")
+	}
+}
+
+func TestResolvePointerCycle(t *testing.T) {
+	first := &resolveNode{}
+	second := &resolveNode{Next: first}
+	first.Next = second
+
+	if err := sm.Resolve(context.Background(), newResolveClient(t), first); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+
+	if first.Token != "t0ken" || second.Token != "t0ken" {
+		t.Errorf("tokens = %q, %q, want both resolved", first.Token, second.Token)
+	}
+}
+
+func TestResolveReportsFieldPaths(t *testing.T) {
+	cfg := struct {
+		Replicas []struct {
+			Password string `secret:"missing,required"`
+		}
+	}{}
+	cfg.Replicas = make([]struct {
+		Password string `secret:"missing,required"`
+	}, 2)
+
+	err := sm.Resolve(context.Background(), newResolveClient(t), &cfg)
+	if !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Fatalf("Resolve() error = %v, want ErrSecretNotFound", err)
+	}
+
+	for _, path := range []string{"Replicas[0].Password", "Replicas[1].Password"} {
+		if !strings.Contains(err.Error(), path) {
+			t.Errorf("error %q doesn't name %s", err, path)
+		}
+	}
+}
+
+func TestResolveMapValues(t *testing.T) {
+	cfg := struct {
+		Extras  map[string]interface{}
+		Missing map[string]struct {
+			Token string `secret:"missing,required"`
+		}
+	}{
+		Extras: map[string]interface{}{"db": resolveInner{Host: "h"}},
+		Missing: map[string]struct {
+			Token string `secret:"missing,required"`
+		}{"eu": {}},
+	}
+
+	err := sm.Resolve(context.Background(), newResolveClient(t), &cfg)
+	if !errors.Is(err, sm.ErrSecretNotFound) || !strings.Contains(err.Error(), "Missing[eu].Token") {
+		t.Errorf("Resolve() error = %v, want ErrSecretNotFound naming Missing[eu].Token", err)
+	}
+
+	if inner, ok := cfg.Extras["db"].(resolveInner); !ok || inner.Password != "s3cret" || inner.Host != "h" {
+		t.Errorf("Extras[db] = %#v, want the resolved struct", cfg.Extras["db"])
+	}
+}
+
+func TestResolveInvalidTarget(t *testing.T) {
+	var cfg resolveInner
+	if err := sm.Resolve(context.Background(), newResolveClient(t), cfg); !errors.Is(err, sm.ErrInvalidBindTarget) {
+		t.Errorf("Resolve() error = %v, want ErrInvalidBindTarget", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRetryBaseDelay is the longest wait before a first retry, doubled on every
+	// following retry.
+	DefaultRetryBaseDelay = 100 * time.Millisecond
+
+	// DefaultRetryMaxDelay is the longest wait between two retries.
+	DefaultRetryMaxDelay = 5 * time.Second
+)
+
+var (
+	// ErrRetryBudgetExhausted is returned instead of retrying a failed operation when its
+	// RetryBudget has no token left.
+	ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+)
+
+// backoff is a jittered exponential backoff spacing the retries of an operation.
+type backoff struct {
+	base time.Duration // Longest wait before the first retry, zero disables waiting
+	max  time.Duration // Longest wait between two retries
+}
+
+// wait sleeps before retry attempt, counted from zero, for a random delay up to
+// base*2^attempt, capped at max when set. The full jitter spreads the retries of
+// concurrent callers failing together. It returns early with the context error when ctx
+// is done.
+func (b backoff) wait(ctx context.Context, attempt int) error {
+	if b.base <= 0 {
+		return ctx.Err()
+	}
+
+	// Past the cap, or once the shift overflows, every wait is bounded by max
+	limit := b.max
+	if attempt < 32 {
+		if d := b.base << attempt; d > 0 && (limit <= 0 || d < limit) {
+			limit = d
+		}
+	}
+	if limit <= 0 {
+		limit = b.base
+	}
+
+	timer := time.NewTimer(rand.N(limit) + 1)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetryBudget is a token bucket bounding the rate of retries, shared by every retrying
+// operation configured with it, such as the lazy fetches of a LazyClient and the API calls
+// of a provider, so a degraded backend isn't overwhelmed by uncoordinated retries. First
+// attempts never consume tokens; only retries do. It is safe for concurrent use.
+type RetryBudget struct {
+	capacity    int
+	refillEvery time.Duration
+	clock       Clock
+
+	mu         sync.Mutex
+	tokens     int
+	lastRefill time.Time
+}
+
+// NewRetryBudget creates a full budget allowing bursts of capacity retries, then one
+// retry per refillEvery.
+//
+// Parameters:
+//   - capacity: The largest number of retries allowed in a burst
+//   - refillEvery: The time after which a consumed token is given back
+//
+// Returns:
+//   - A RetryBudget to share between the retrying operations
+func NewRetryBudget(capacity int, refillEvery time.Duration) *RetryBudget {
+	return &RetryBudget{
+		capacity:    capacity,
+		refillEvery: refillEvery,
+		clock:       SystemClock,
+		tokens:      capacity,
+		lastRefill:  SystemClock.Now(),
+	}
+}
+
+// Allow consumes a token and reports whether a retry may be attempted. Callers must fail
+// fast, typically with ErrRetryBudgetExhausted, when it returns false.
+func (b *RetryBudget) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+
+	if b.tokens == 0 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// Available returns the number of retries currently allowed.
+func (b *RetryBudget) Available() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+
+	return b.tokens
+}
+
+// refill gives back the tokens earned since the last refill. The caller must hold mu.
+func (b *RetryBudget) refill() {
+	if b.refillEvery <= 0 {
+		return
+	}
+
+	now := b.clock.Now()
+
+	earned := int(now.Sub(b.lastRefill) / b.refillEvery)
+	if earned <= 0 {
+		return
+	}
+
+	b.tokens = min(b.capacity, b.tokens+earned)
+	b.lastRefill = b.lastRefill.Add(time.Duration(earned) * b.refillEvery)
+
+	// A full bucket doesn't bank the time spent full
+	if b.tokens == b.capacity {
+		b.lastRefill = now
+	}
+}
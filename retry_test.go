@@ -0,0 +1,68 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stepClock is a Clock only moving when advanced.
+type stepClock struct{ now time.Time }
+
+func (c *stepClock) Now() time.Time { return c.now }
+
+func TestRetryBudgetRefills(t *testing.T) {
+	clock := &stepClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	b := NewRetryBudget(2, time.Second)
+	b.clock = clock
+	b.lastRefill = clock.now
+
+	if !b.Allow() || !b.Allow() {
+		t.Fatal("Allow() = false within the capacity")
+	}
+	if b.Allow() {
+		t.Fatal("Allow() = true with the budget exhausted")
+	}
+
+	clock.now = clock.now.Add(1500 * time.Millisecond)
+	if got := b.Available(); got != 1 {
+		t.Errorf("Available() = %d after one refill period, want 1", got)
+	}
+
+	clock.now = clock.now.Add(time.Hour)
+	if got := b.Available(); got != 2 {
+		t.Errorf("Available() = %d after a long pause, want the capacity", got)
+	}
+}
+
+func TestBackoffWaitIsBounded(t *testing.T) {
+	b := backoff{base: time.Millisecond, max: 4 * time.Millisecond}
+
+	for attempt := range 40 {
+		start := time.Now()
+		if err := b.wait(context.Background(), attempt); err != nil {
+			t.Fatalf("wait(%d) error = %v", attempt, err)
+		}
+
+		// Generous slack, the timer only guarantees a lower bound
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("wait(%d) took %v, want at most about %v", attempt, elapsed, b.max)
+		}
+	}
+}
+
+func TestBackoffWaitStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	b := backoff{base: time.Hour, max: time.Hour}
+	if err := b.wait(ctx, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// route associates a key prefix with the client serving it.
+	route struct {
+		prefix string
+		client SecretClient
+	}
+
+	// RoutingClient is a SecretClient dispatching each key to one of several providers
+	// according to its prefix, so heterogeneous backends can be consumed through a single
+	// client. Keys are forwarded to the selected provider unchanged.
+	RoutingClient struct {
+		routes        []route // Sorted by decreasing prefix length
+		defaultClient SecretClient
+
+		mu        sync.RWMutex
+		overrides map[string]SecretClient // Exact keys routed regardless of their prefix
+	}
+)
+
+// NewRoutingClient creates a client routing keys by prefix.
+//
+// A key is served by the client registered under the longest prefix it starts with, and
+// by defaultClient when no prefix matches. With a nil defaultClient, unmatched keys are
+// reported as ErrSecretNotFound.
+//
+// Parameters:
+//   - routes: Clients indexed by the key prefix they serve
+//   - defaultClient: The client serving keys matching no prefix, may be nil
+//
+// Returns:
+//   - A RoutingClient dispatching to the given clients
+func NewRoutingClient(routes map[string]SecretClient, defaultClient SecretClient) *RoutingClient {
+	r := &RoutingClient{
+		defaultClient: defaultClient,
+		overrides:     make(map[string]SecretClient),
+	}
+
+	for prefix, client := range routes {
+		r.routes = append(r.routes, route{prefix: prefix, client: client})
+	}
+
+	sort.Slice(r.routes, func(i, j int) bool {
+		if len(r.routes[i].prefix) != len(r.routes[j].prefix) {
+			return len(r.routes[i].prefix) > len(r.routes[j].prefix)
+		}
+
+		return r.routes[i].prefix < r.routes[j].prefix
+	})
+
+	return r
+}
+
+// OverrideKey routes key to client, taking precedence over any prefix route. It handles
+// the few keys living in another backend than their prefix suggests without restructuring
+// the prefixes. The client is loaded and reloaded along with the routed ones.
+func (r *RoutingClient) OverrideKey(key string, client SecretClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.overrides[key] = client
+}
+
+// LoadSecrets loads the secrets of every routed client and of the default client.
+// All clients are loaded even when some fail; the failures are returned joined.
+func (r *RoutingClient) LoadSecrets(ctx context.Context) error {
+	var errs []error
+	for _, client := range r.clients() {
+		if err := client.LoadSecrets(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Reload forwards the reload to every client implementing Reloadable and skips the others.
+// All clients are reloaded even when some fail; the failures are returned joined.
+func (r *RoutingClient) Reload(ctx context.Context) error {
+	var errs []error
+	for _, client := range r.clients() {
+		if reloadable, ok := client.(Reloadable); ok {
+			if err := reloadable.Reload(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckHealth probes the routed, override and default clients concurrently with
+// CheckHealth and reports their status by name: the route prefix for routed clients,
+// "key:" followed by the key for override clients, and "default" for the default client.
+//
+// Parameters:
+//   - ctx: Context bounding every probe
+//   - opts: Optional concurrency and timeout settings
+//
+// Returns:
+//   - The probe error of each client implementing HealthChecker, indexed by name
+func (r *RoutingClient) CheckHealth(ctx context.Context, opts ...HealthOption) map[string]error {
+	providers := make(map[string]SecretClient, len(r.routes)+1)
+	for _, rt := range r.routes {
+		providers[rt.prefix] = rt.client
+	}
+
+	r.mu.RLock()
+	for key, client := range r.overrides {
+		providers["key:"+key] = client
+	}
+	r.mu.RUnlock()
+
+	if r.defaultClient != nil {
+		providers["default"] = r.defaultClient
+	}
+
+	return CheckHealth(ctx, providers, opts...)
+}
+
+// HealthCheck probes every client like CheckHealth with the default settings and returns
+// the failures joined, each naming its client. It implements the HealthChecker interface,
+// so routing clients can be nested.
+func (r *RoutingClient) HealthCheck(ctx context.Context) error {
+	status := r.CheckHealth(ctx)
+
+	names := make([]string, 0, len(status))
+	for name := range status {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if err := status[name]; err != nil {
+			errs = append(errs, fmt.Errorf("provider %q: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetSecret retrieves key from the client overriding it, or the client its prefix routes to.
+func (r *RoutingClient) GetSecret(ctx context.Context, key string) (string, error) {
+	client := r.route(key)
+	if client == nil {
+		return "", ErrSecretNotFound
+	}
+
+	return client.GetSecret(ctx, key)
+}
+
+// route returns the client serving key, or nil when there is none.
+func (r *RoutingClient) route(key string) SecretClient {
+	r.mu.RLock()
+	override, ok := r.overrides[key]
+	r.mu.RUnlock()
+
+	if ok {
+		return override
+	}
+
+	for _, rt := range r.routes {
+		if strings.HasPrefix(key, rt.prefix) {
+			return rt.client
+		}
+	}
+
+	return r.defaultClient
+}
+
+// clients returns the routed clients and the override clients, sorted by key, followed
+// by the default client, if any.
+func (r *RoutingClient) clients() []SecretClient {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clients := make([]SecretClient, 0, len(r.routes)+len(r.overrides)+1)
+	for _, rt := range r.routes {
+		clients = append(clients, rt.client)
+	}
+
+	keys := make([]string, 0, len(r.overrides))
+	for key := range r.overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		clients = append(clients, r.overrides[key])
+	}
+
+	if r.defaultClient != nil {
+		clients = append(clients, r.defaultClient)
+	}
+
+	return clients
+}
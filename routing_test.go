@@ -0,0 +1,150 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+// mapClient is a SecretClient serving a fixed map. It doesn't implement Reloadable.
+type mapClient struct {
+	values map[string]string
+	loads  atomic.Int32
+}
+
+func (m *mapClient) LoadSecrets(context.Context) error {
+	m.loads.Add(1)
+	return nil
+}
+
+func (m *mapClient) GetSecret(_ context.Context, key string) (string, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value, nil
+}
+
+func TestRoutingClientReloadSkipsNonReloadableClients(t *testing.T) {
+	ctx := context.Background()
+
+	vault := fake.NewFakeClient(fake.WithSeed(map[string]string{"vault/db": "v1"}))
+	aws := fake.NewFakeClient(fake.WithSeed(map[string]string{"aws/api": "v1"}))
+	static := &mapClient{values: map[string]string{"app": "1"}}
+
+	r := sm.NewRoutingClient(map[string]sm.SecretClient{"vault/": vault, "aws/": aws}, static)
+	if err := r.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	vault.SetSecret("vault/db", "v2")
+	aws.SetSecret("aws/api", "v2")
+
+	if err := r.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if vault.LoadCount() != 2 || aws.LoadCount() != 2 {
+		t.Errorf("loads = %d, %d, want every reloadable child reloaded", vault.LoadCount(), aws.LoadCount())
+	}
+	if got := static.loads.Load(); got != 1 {
+		t.Errorf("non-reloadable loads = %d, want it skipped by Reload", got)
+	}
+
+	for _, key := range []string{"vault/db", "aws/api"} {
+		if value, _ := r.GetSecret(ctx, key); value != "v2" {
+			t.Errorf("GetSecret(%q) = %q after Reload, want v2", key, value)
+		}
+	}
+}
+
+func TestRoutingClientReloadJoinsFailures(t *testing.T) {
+	ctx := context.Background()
+	errVault := errors.New("vault unavailable")
+
+	vault := fake.NewFakeClient()
+	aws := fake.NewFakeClient()
+	vault.FailNextLoad(errVault)
+
+	r := sm.NewRoutingClient(map[string]sm.SecretClient{"vault/": vault}, aws)
+
+	if err := r.Reload(ctx); !errors.Is(err, errVault) {
+		t.Fatalf("Reload() error = %v, want the child failure", err)
+	}
+	if aws.LoadCount() != 1 {
+		t.Errorf("default client loads = %d, want it reloaded despite the other failure", aws.LoadCount())
+	}
+}
+
+func TestRoutingClientRoutesByPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	vault := &mapClient{values: map[string]string{"vault/db": "from vault", "vault/kv/token": "from vault"}}
+	kv := &mapClient{values: map[string]string{"vault/kv/token": "from kv"}}
+	aws := &mapClient{values: map[string]string{"app": "from aws", "vault/db": "from aws"}}
+
+	r := sm.NewRoutingClient(map[string]sm.SecretClient{"vault/": vault, "vault/kv/": kv}, aws)
+	if err := r.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for _, client := range []*mapClient{vault, kv, aws} {
+		if got := client.loads.Load(); got != 1 {
+			t.Errorf("loads = %d, want every client loaded once", got)
+		}
+	}
+
+	tests := map[string]string{
+		"vault/db":       "from vault",
+		"vault/kv/token": "from kv",
+		"app":            "from aws",
+	}
+	for key, want := range tests {
+		if value, err := r.GetSecret(ctx, key); err != nil || value != want {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, value, err, want)
+		}
+	}
+}
+
+func TestRoutingClientWithoutDefault(t *testing.T) {
+	r := sm.NewRoutingClient(map[string]sm.SecretClient{
+		"vault/": &mapClient{values: map[string]string{"vault/db": "v"}},
+	}, nil)
+
+	if _, err := r.GetSecret(context.Background(), "app"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want ErrSecretNotFound for an unrouted key", err)
+	}
+}
+
+func TestRoutingClientOverrideKey(t *testing.T) {
+	ctx := context.Background()
+
+	vault := &mapClient{values: map[string]string{"vault/db": "from vault", "vault/legacy": "stale"}}
+	aws := &mapClient{values: map[string]string{"vault/legacy": "from aws"}}
+
+	r := sm.NewRoutingClient(map[string]sm.SecretClient{"vault/": vault}, nil)
+	r.OverrideKey("vault/legacy", aws)
+
+	if err := r.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+	if got := aws.loads.Load(); got != 1 {
+		t.Errorf("override client loads = %d, want it loaded with the routes", got)
+	}
+
+	if value, _ := r.GetSecret(ctx, "vault/legacy"); value != "from aws" {
+		t.Errorf("GetSecret(vault/legacy) = %q, want the override to win over the prefix", value)
+	}
+	if value, _ := r.GetSecret(ctx, "vault/db"); value != "from vault" {
+		t.Errorf("GetSecret(vault/db) = %q, want other keys still routed by prefix", value)
+	}
+}
@@ -13,7 +13,11 @@
 // deployment scenarios.
 package secretsmanager
 
-import "context"
+import (
+	"context"
+	"io"
+	"time"
+)
 
 type (
 	// SecretClient defines the interface for interacting with secret providers.
@@ -45,4 +49,120 @@ type (
 		//   - An error if the key doesn't exist or if there's a problem accessing the secret
 		GetSecret(ctx context.Context, key string) (string, error)
 	}
+
+	// Writer is implemented by providers able to store secret values.
+	Writer interface {
+		// WriteSecret stores value under key in the provider and updates the cache.
+		WriteSecret(ctx context.Context, key, value string) error
+	}
+
+	// DryRunWriter is implemented by providers able to preview a write without applying it.
+	DryRunWriter interface {
+		// PreviewWriteSecret returns the secret that WriteSecret would store, encoded as
+		// JSON with every value redacted, without writing it.
+		PreviewWriteSecret(ctx context.Context, key, value string) (string, error)
+	}
+
+	// VersionedWriter is implemented by providers able to write a secret only when it
+	// wasn't modified since a known version, so concurrent writers never clobber each other.
+	VersionedWriter interface {
+		// CurrentVersion returns the identifier of the version currently stored.
+		CurrentVersion(ctx context.Context) (string, error)
+
+		// WriteSecretIfVersion stores value under key only if the stored version is still
+		// expectedVersion, and returns ErrConflict otherwise.
+		WriteSecretIfVersion(ctx context.Context, key, value, expectedVersion string) error
+	}
+
+	// CacheResetter is implemented by providers whose cache can be cleared without reloading.
+	CacheResetter interface {
+		// ResetCache empties the cache and marks the client as not loaded, so GetSecret
+		// returns ErrSecretsNotLoaded until the secrets are loaded again.
+		ResetCache()
+	}
+
+	// HealthChecker is implemented by providers able to probe their backend without
+	// loading secrets, for readiness checks.
+	HealthChecker interface {
+		// HealthCheck returns an error when the backend cannot be reached or the client
+		// isn't allowed to use it.
+		HealthCheck(ctx context.Context) error
+	}
+
+	// Lister is implemented by providers able to enumerate the keys they hold.
+	Lister interface {
+		// ListSecrets returns the sorted keys available through GetSecret. It never
+		// returns secret values.
+		ListSecrets(ctx context.Context) ([]string, error)
+	}
+
+	// Streamer is implemented by providers able to write a value without materializing it
+	// as a string first, such as per-key providers streaming the response of their backend.
+	Streamer interface {
+		// WriteSecretTo writes the value of key to w and returns the number of bytes
+		// written. It returns ErrSecretNotFound without writing when key doesn't exist.
+		WriteSecretTo(ctx context.Context, key string, w io.Writer) (int64, error)
+	}
+
+	// Reloadable is implemented by providers able to refresh their whole cache on demand.
+	// Generic tooling can use it to trigger a refresh regardless of the provider in use.
+	Reloadable interface {
+		// Reload fetches the secrets from the provider again and replaces the cache.
+		// On failure the previously cached values are kept.
+		Reload(ctx context.Context) error
+	}
+
+	// Invalidator is implemented by providers able to drop a single key from their cache,
+	// giving callers that know a specific secret rotated a lighter option than a full reload.
+	Invalidator interface {
+		// Invalidate marks key as stale so the next GetSecret for it fetches a fresh value.
+		Invalidate(key string)
+	}
+
+	// SourceReporter is implemented by providers able to tell which secret a cached key
+	// was loaded from, which helps debugging when several secrets define the same key.
+	SourceReporter interface {
+		// SourceOf returns the identifier of the secret that supplied key. It never
+		// exposes the value itself. The boolean is false when the key isn't cached.
+		SourceOf(key string) (string, bool)
+	}
+
+	// LastModifiedReporter is implemented by providers able to tell when the value of a
+	// cached key was last modified, to reason about the freshness of individual keys.
+	LastModifiedReporter interface {
+		// LastModified returns when key was last modified in the provider. Providers
+		// storing every key in a single blob report the modification time of the blob.
+		// The boolean is false when the key isn't cached or the time is unknown.
+		LastModified(key string) (time.Time, bool)
+	}
+
+	// StaleReloader is implemented by providers tracking the age of their cache, letting
+	// applications refresh at natural boundaries (e.g. per request batch) without a timer.
+	StaleReloader interface {
+		// ReloadIfStale reloads the secrets only when the cache is older than maxAge, or was
+		// never loaded, and reports whether a reload happened.
+		ReloadIfStale(ctx context.Context, maxAge time.Duration) (bool, error)
+	}
+
+	// Snapshotter is implemented by providers able to export their whole cache at once.
+	// It is useful for bootstrapping subprocesses or building connection strings from
+	// several secrets.
+	Snapshotter interface {
+		// Snapshot returns a copy of every cached key-value pair. The returned map is never
+		// the provider's live cache, so callers may mutate it freely.
+		//
+		// The map holds plaintext secret values: avoid logging it and drop references to it
+		// as soon as it is no longer needed.
+		Snapshot(ctx context.Context) (map[string]string, error)
+	}
 )
+
+// reload reloads c through Reload when it implements Reloadable, and through
+// LoadSecrets otherwise. Decorators use it to forward reloads to the client they wrap.
+func reload(ctx context.Context, c SecretClient) error {
+	if r, ok := c.(Reloadable); ok {
+		return r.Reload(ctx)
+	}
+
+	return c.LoadSecrets(ctx)
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// sidecarShutdownTimeout bounds the time ServeHTTP waits for in-flight requests once
+	// its context is done.
+	sidecarShutdownTimeout = 5 * time.Second
+
+	// bearerPrefix precedes the token in the Authorization header.
+	bearerPrefix = "Bearer "
+)
+
+var (
+	// ErrNonLoopbackAddress is returned by ServeHTTP when asked to listen on an address
+	// reachable from other hosts.
+	ErrNonLoopbackAddress = errors.New("sidecar address must be a loopback address")
+
+	// ErrSidecarTokenRequired is returned when the sidecar is configured without a token.
+	ErrSidecarTokenRequired = errors.New("sidecar token must not be empty")
+)
+
+// NewSidecarHandler returns a handler serving the values of c to local processes, for
+// language-agnostic access to secrets through a sidecar. It answers GET /secret/{key},
+// where key may contain slashes, with the plain value of key.
+//
+// Requests must carry the shared token in an "Authorization: Bearer <token>" header and
+// come from a loopback address, otherwise they're rejected with 401 or 403. Missing keys
+// are answered with 404 and other failures with 500; error responses never include the
+// error message, and successful ones are marked as not cacheable.
+//
+// Parameters:
+//   - c: The secret client holding the values
+//   - token: The token shared with the trusted callers
+//
+// Returns:
+//   - The handler serving the secrets
+//   - ErrSidecarTokenRequired if token is empty
+func NewSidecarHandler(c SecretClient, token string) (http.Handler, error) {
+	if token == "" {
+		return nil, ErrSidecarTokenRequired
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /secret/{key...}", func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackRemote(r.RemoteAddr) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		if !validBearer(r.Header.Get("Authorization"), token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		value, err := c.GetSecret(r.Context(), r.PathValue("key"))
+		switch {
+		case errors.Is(err, ErrSecretNotFound):
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		case errors.Is(err, ErrAccessDenied):
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		case err != nil:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+
+		// The caller went away once the headers were sent, there's nobody left to tell
+		_, _ = io.WriteString(w, value)
+	})
+
+	return mux, nil
+}
+
+// ServeHTTP serves the values of c with NewSidecarHandler on addr, such as
+// "127.0.0.1:8200", until ctx is done. In-flight requests are then given a few seconds
+// to complete.
+//
+// Parameters:
+//   - ctx: Context stopping the server when done
+//   - c: The secret client holding the values
+//   - addr: The loopback address to listen on
+//   - token: The token shared with the trusted callers
+//
+// Returns:
+//   - ErrNonLoopbackAddress if addr isn't a loopback address
+//   - ErrSidecarTokenRequired if token is empty
+//   - An error if the server cannot listen or fails, nil once stopped through ctx
+func ServeHTTP(ctx context.Context, c SecretClient, addr, token string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("parse sidecar address: %w", err)
+	}
+	if !isLoopbackHost(host) {
+		return fmt.Errorf("%w: %s", ErrNonLoopbackAddress, addr)
+	}
+
+	handler, err := NewSidecarHandler(c, token)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	// Also stops the shutdown goroutine when Serve fails on its own
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sidecarShutdownTimeout)
+		defer cancel()
+
+		// Requests still running after the timeout are cut off by Close
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			_ = srv.Close()
+		}
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	<-stopped
+	return nil
+}
+
+// isLoopbackHost reports whether host, without port, only resolves to loopback addresses.
+// An empty host listens on every interface and is rejected.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// isLoopbackRemote reports whether the remote address of a request is a loopback address.
+func isLoopbackRemote(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// validBearer reports whether header carries token as a bearer token, comparing in
+// constant time.
+func validBearer(header, token string) bool {
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return false
+	}
+
+	provided := strings.TrimPrefix(header, bearerPrefix)
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
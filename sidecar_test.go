@@ -0,0 +1,136 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+func newSidecarHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	c := fake.NewFakeClient(fake.WithSeed(map[string]string{"db/password": "s3cret"}))
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := sm.NewSidecarHandler(c, "t0ken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return handler
+}
+
+func TestSidecarHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		remoteAddr string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "with token",
+			path:       "/secret/db/password",
+			remoteAddr: "127.0.0.1:40000",
+			auth:       "Bearer t0ken",
+			wantStatus: http.StatusOK,
+			wantBody:   "s3cret",
+		},
+		{
+			name:       "without token",
+			path:       "/secret/db/password",
+			remoteAddr: "127.0.0.1:40000",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong token",
+			path:       "/secret/db/password",
+			remoteAddr: "127.0.0.1:40000",
+			auth:       "Bearer other",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "remote caller",
+			path:       "/secret/db/password",
+			remoteAddr: "192.0.2.1:40000",
+			auth:       "Bearer t0ken",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing key",
+			path:       "/secret/missing",
+			remoteAddr: "[::1]:40000",
+			auth:       "Bearer t0ken",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	handler := newSidecarHandler(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+
+			body := rec.Body.String()
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+			if tt.wantBody == "" && strings.Contains(body, "s3cret") {
+				t.Errorf("error response %q leaks the value", body)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("Cache-Control = %q, want no-store", rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestNewSidecarHandlerRequiresToken(t *testing.T) {
+	if _, err := sm.NewSidecarHandler(fake.NewFakeClient(), ""); !errors.Is(err, sm.ErrSidecarTokenRequired) {
+		t.Errorf("NewSidecarHandler() error = %v, want ErrSidecarTokenRequired", err)
+	}
+}
+
+func TestServeHTTPRejectsNonLoopbackAddress(t *testing.T) {
+	for _, addr := range []string{":8200", "0.0.0.0:8200", "192.0.2.1:8200"} {
+		err := sm.ServeHTTP(context.Background(), fake.NewFakeClient(), addr, "t0ken")
+		if !errors.Is(err, sm.ErrNonLoopbackAddress) {
+			t.Errorf("ServeHTTP(%q) error = %v, want ErrNonLoopbackAddress", addr, err)
+		}
+	}
+}
+
+func TestServeHTTPStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+	go func() { done <- sm.ServeHTTP(ctx, fake.NewFakeClient(), "127.0.0.1:0", "t0ken") }()
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("ServeHTTP() error = %v, want nil once stopped", err)
+	}
+}
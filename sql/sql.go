@@ -0,0 +1,108 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package sql provides a SecretClient reading secrets from a database table through
+// database/sql, for legacy services that keep their credentials in a database. It works
+// with any driver, such as PostgreSQL or MySQL, since the query is supplied by the caller.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+// sqlSecretClient is an implementation of the SecretClient interface caching the rows of
+// a query returning key and value columns.
+type sqlSecretClient struct {
+	db    *sql.DB // Database holding the secrets table
+	query string  // Query returning (key, value) rows
+
+	mu      sync.RWMutex
+	secrets map[string]redact.String // In-memory cache of secret key-value pairs
+}
+
+// NewSQLSecretClient creates a client loading secrets with query, which must return two
+// string columns, the key and the value, such as:
+//
+//	SELECT name, value FROM app_secrets WHERE environment = 'production'
+//
+// The query is run as is on every load, without arguments, so it must not be built from
+// untrusted input. The caller owns db and remains responsible for closing it.
+//
+// Parameters:
+//   - db: The database holding the secrets
+//   - query: The query returning (key, value) rows
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the query
+func NewSQLSecretClient(db *sql.DB, query string) sm.SecretClient {
+	return &sqlSecretClient{
+		db:      db,
+		query:   query,
+		secrets: make(map[string]redact.String),
+	}
+}
+
+// LoadSecrets runs the query and replaces the in-memory cache with its rows. When a key
+// appears in several rows, the last one wins. The cache is left untouched when the query
+// fails.
+//
+// Parameters:
+//   - ctx: Context for controlling the query lifecycle
+//
+// Returns:
+//   - An error if the query fails or a row cannot be scanned
+func (c *sqlSecretClient) LoadSecrets(ctx context.Context) error {
+	rows, err := c.db.QueryContext(ctx, c.query)
+	if err != nil {
+		return fmt.Errorf("query secrets: %w", err)
+	}
+	defer rows.Close()
+
+	secrets := make(map[string]string)
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		if err := rows.Scan(&key, &value); err != nil {
+			return fmt.Errorf("scan secret row: %w", err)
+		}
+
+		secrets[key] = value.String
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read secret rows: %w", err)
+	}
+
+	c.mu.Lock()
+	c.secrets = redact.Map(secrets)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *sqlSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	smsql "github.com/goxkit/secretsmanager/sql"
+)
+
+// fakeDriver is a database/sql driver answering every query of a data source with the
+// result registered for it, standing in for a real database.
+type fakeDriver struct {
+	mu      sync.Mutex
+	results map[string]fakeResult // Query result by data source name
+}
+
+type (
+	fakeResult struct {
+		rows [][]driver.Value
+		err  error
+	}
+
+	fakeConn struct {
+		driver *fakeDriver
+		dsn    string
+	}
+
+	fakeRows struct {
+		rows [][]driver.Value
+		next int
+	}
+)
+
+var testDriver = &fakeDriver{results: map[string]fakeResult{}}
+
+func init() {
+	sql.Register("secretsmanager-fake", testDriver)
+}
+
+// openDB opens a database whose queries return result.
+func openDB(t *testing.T, result fakeResult) *sql.DB {
+	t.Helper()
+
+	testDriver.mu.Lock()
+	testDriver.results[t.Name()] = result
+	testDriver.mu.Unlock()
+
+	db, err := sql.Open("secretsmanager-fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// setResult replaces the result of the database opened by the test.
+func setResult(t *testing.T, result fakeResult) {
+	testDriver.mu.Lock()
+	testDriver.results[t.Name()] = result
+	testDriver.mu.Unlock()
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	return &fakeConn{driver: d, dsn: dsn}, nil
+}
+
+func (c *fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	c.driver.mu.Lock()
+	result := c.driver.results[c.dsn]
+	c.driver.mu.Unlock()
+
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	return &fakeRows{rows: result.rows}, nil
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (r *fakeRows) Columns() []string { return []string{"name", "value"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows[r.next])
+	r.next++
+
+	return nil
+}
+
+func TestSQLSecretClient(t *testing.T) {
+	db := openDB(t, fakeResult{rows: [][]driver.Value{
+		{"db_password", "old"},
+		{"api_token", "t0ken"},
+		{"db_password", "s3cret"},
+		{"empty", nil},
+	}})
+
+	c := smsql.NewSQLSecretClient(db, "SELECT name, value FROM app_secrets")
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	want := map[string]string{"db_password": "s3cret", "api_token": "t0ken", "empty": ""}
+	for key, value := range want {
+		if got, err := c.GetSecret(ctx, key); err != nil || got != value {
+			t.Errorf("GetSecret(%q) = %q, %v, want %q", key, got, err, value)
+		}
+	}
+	if _, err := c.GetSecret(ctx, "missing"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSQLSecretClientQueryFailure(t *testing.T) {
+	errQuery := errors.New("relation app_secrets does not exist")
+
+	db := openDB(t, fakeResult{rows: [][]driver.Value{{"db_password", "s3cret"}}})
+	c := smsql.NewSQLSecretClient(db, "SELECT name, value FROM app_secrets")
+	ctx := context.Background()
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	setResult(t, fakeResult{err: errQuery})
+
+	if err := c.LoadSecrets(ctx); !errors.Is(err, errQuery) {
+		t.Errorf("LoadSecrets() error = %v, want the query failure", err)
+	}
+	if value, _ := c.GetSecret(ctx, "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want the cache kept after a failed load", value)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+// DefaultStreamConcurrency is the default number of secrets a StreamingClient fetches
+// at once.
+const DefaultStreamConcurrency = 8
+
+type (
+	// ListPageFunc lists one page of the secret keys of a paginated backend, such as SSM
+	// Parameter Store or Azure Key Vault. It receives the token returned with the previous
+	// page, empty for the first one, and returns an empty next token on the last page.
+	ListPageFunc func(ctx context.Context, token string) (keys []string, next string, err error)
+
+	// StreamingClient is a SecretClient loading the secrets of a backend holding a large
+	// number of discrete secrets page by page, so the working set stays bounded by the page
+	// size and the fetch concurrency rather than the number of secrets.
+	StreamingClient struct {
+		list        ListPageFunc
+		fetch       FetchFunc
+		concurrency int
+		onlyKeys    map[string]struct{} // Keys to load, nil loads every listed key
+
+		mu      sync.RWMutex
+		secrets map[string]redact.String // In-memory cache of secret key-value pairs
+	}
+
+	// StreamOption configures optional behavior of a StreamingClient.
+	StreamOption func(*StreamingClient)
+)
+
+// WithStreamConcurrency bounds the number of secrets fetched at once. Defaults to
+// DefaultStreamConcurrency.
+func WithStreamConcurrency(n int) StreamOption {
+	return func(s *StreamingClient) {
+		if n > 0 {
+			s.concurrency = n
+		}
+	}
+}
+
+// WithStreamKeys loads only the given keys; the other listed keys are skipped without
+// being fetched, so only a small subset of a huge backend is held in memory.
+func WithStreamKeys(keys ...string) StreamOption {
+	return func(s *StreamingClient) {
+		s.onlyKeys = make(map[string]struct{}, len(keys))
+		for _, key := range keys {
+			s.onlyKeys[key] = struct{}{}
+		}
+	}
+}
+
+// NewStreamingClient creates a StreamingClient listing keys through list and fetching
+// their values through fetch.
+//
+// Parameters:
+//   - list: Function listing one page of secret keys
+//   - fetch: Function retrieving a single secret value
+//   - opts: Optional settings of the client
+//
+// Returns:
+//   - A StreamingClient with an empty cache
+func NewStreamingClient(list ListPageFunc, fetch FetchFunc, opts ...StreamOption) *StreamingClient {
+	s := &StreamingClient{
+		list:        list,
+		fetch:       fetch,
+		concurrency: DefaultStreamConcurrency,
+		secrets:     make(map[string]redact.String),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// LoadSecrets lists the keys page by page and fetches the values of each page with at
+// most the configured concurrency before listing the next one. The cache is replaced only
+// when every page loaded, so a failure keeps the previous secrets.
+//
+// Parameters:
+//   - ctx: Context for controlling the load, cancelled fetches abort it
+//
+// Returns:
+//   - The first listing or fetch error encountered
+func (s *StreamingClient) LoadSecrets(ctx context.Context) error {
+	secrets := make(map[string]redact.String)
+
+	var token string
+	for {
+		keys, next, err := s.list(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		if err := s.loadPage(ctx, keys, secrets); err != nil {
+			return err
+		}
+
+		if next == "" {
+			break
+		}
+
+		token = next
+	}
+
+	s.mu.Lock()
+	s.secrets = secrets
+	s.mu.Unlock()
+
+	return nil
+}
+
+// loadPage fetches the values of keys concurrently into secrets.
+func (s *StreamingClient) loadPage(ctx context.Context, keys []string, secrets map[string]redact.String) error {
+	var mu sync.Mutex
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.concurrency)
+
+	for _, key := range keys {
+		if s.onlyKeys != nil {
+			if _, ok := s.onlyKeys[key]; !ok {
+				continue
+			}
+		}
+
+		g.Go(func() error {
+			value, err := s.fetch(gctx, key)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			secrets[key] = redact.New(value)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - ErrSecretNotFound if the key doesn't exist in the cache
+func (s *StreamingClient) GetSecret(_ context.Context, key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.secrets[key]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
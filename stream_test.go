@@ -0,0 +1,131 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// pagedBackend serves keys in pages and tracks how many fetches run at once.
+type pagedBackend struct {
+	keys     []string
+	pageSize int
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	fetches     atomic.Int32
+	failKey     string
+}
+
+func newPagedBackend(n, pageSize int) *pagedBackend {
+	b := &pagedBackend{pageSize: pageSize}
+	for i := range n {
+		b.keys = append(b.keys, fmt.Sprintf("key-%03d", i))
+	}
+
+	return b
+}
+
+func (b *pagedBackend) List(_ context.Context, token string) ([]string, string, error) {
+	start := 0
+	if token != "" {
+		start, _ = strconv.Atoi(token)
+	}
+
+	end := min(start+b.pageSize, len(b.keys))
+	if end == len(b.keys) {
+		return b.keys[start:end], "", nil
+	}
+
+	return b.keys[start:end], strconv.Itoa(end), nil
+}
+
+func (b *pagedBackend) Fetch(_ context.Context, key string) (string, error) {
+	current := b.inFlight.Add(1)
+	defer b.inFlight.Add(-1)
+
+	for {
+		peak := b.maxInFlight.Load()
+		if current <= peak || b.maxInFlight.CompareAndSwap(peak, current) {
+			break
+		}
+	}
+
+	b.fetches.Add(1)
+	time.Sleep(time.Millisecond)
+
+	if key == b.failKey {
+		return "", errors.New("backend unavailable")
+	}
+
+	return "value of " + key, nil
+}
+
+func TestStreamingClientLoadsEveryPage(t *testing.T) {
+	backend := newPagedBackend(95, 10)
+	c := sm.NewStreamingClient(backend.List, backend.Fetch, sm.WithStreamConcurrency(3))
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	for _, key := range backend.keys {
+		if value, err := c.GetSecret(ctx, key); err != nil || value != "value of "+key {
+			t.Fatalf("GetSecret(%q) = %q, %v, want its value", key, value, err)
+		}
+	}
+
+	if peak := backend.maxInFlight.Load(); peak > 3 {
+		t.Errorf("concurrent fetches = %d, want at most 3", peak)
+	}
+}
+
+func TestStreamingClientOnlyKeys(t *testing.T) {
+	backend := newPagedBackend(50, 10)
+	c := sm.NewStreamingClient(backend.List, backend.Fetch, sm.WithStreamKeys("key-007", "key-042"))
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if got := backend.fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want only the selected keys fetched", got)
+	}
+	if _, err := c.GetSecret(ctx, "key-001"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecret() error = %v for a skipped key, want ErrSecretNotFound", err)
+	}
+	if value, _ := c.GetSecret(ctx, "key-042"); value != "value of key-042" {
+		t.Errorf("GetSecret() = %q, want the selected key loaded", value)
+	}
+}
+
+func TestStreamingClientKeepsCacheOnFailure(t *testing.T) {
+	backend := newPagedBackend(20, 5)
+	c := sm.NewStreamingClient(backend.List, backend.Fetch)
+
+	ctx := context.Background()
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	backend.failKey = "key-013"
+	if err := c.LoadSecrets(ctx); err == nil {
+		t.Fatal("LoadSecrets() error = nil, want the fetch failure")
+	}
+
+	if value, _ := c.GetSecret(ctx, "key-001"); value != "value of key-001" {
+		t.Errorf("GetSecret() = %q, want the previous cache kept", value)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// sharedMemoryDir is a memory-backed file system available on most Linux hosts.
+const sharedMemoryDir = "/dev/shm"
+
+// GetSecretFile writes the value of key to a private temporary file and returns its path,
+// bridging external tools that only accept credentials as a file path, such as a
+// kubeconfig or a certificate.
+//
+// The file is only readable and writable by the current user (0600) and is created in
+// /dev/shm when it exists, so the value stays in memory, or in the default temporary
+// directory otherwise. The returned cleanup overwrites the file with zeros before removing
+// it; it's safe to call several times and should be deferred by the caller.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to write
+//
+// Returns:
+//   - The path of the file holding the value
+//   - A function zeroing and removing the file
+//   - An error if the secret cannot be retrieved or the file cannot be written
+func GetSecretFile(ctx context.Context, c SecretClient, key string) (string, func(), error) {
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	f, err := os.CreateTemp(tempDir(), "secret-*")
+	if err != nil {
+		// The memory-backed directory may exist without being writable
+		f, err = os.CreateTemp(os.TempDir(), "secret-*")
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("create secret file for %q: %w", key, err)
+	}
+
+	path := f.Name()
+
+	var once sync.Once
+	cleanup := func() {
+		once.Do(func() { shred(path, len(value)) })
+	}
+
+	// CreateTemp already uses 0600 on Unix, make it explicit regardless of the platform
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("restrict secret file for %q: %w", key, err)
+	}
+
+	if _, err := f.WriteString(value); err != nil {
+		f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("write secret file for %q: %w", key, err)
+	}
+
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("write secret file for %q: %w", key, err)
+	}
+
+	return path, cleanup, nil
+}
+
+// tempDir returns the memory-backed directory when available, and the default temporary
+// directory otherwise.
+func tempDir() string {
+	if info, err := os.Stat(sharedMemoryDir); err == nil && info.IsDir() {
+		return sharedMemoryDir
+	}
+
+	return os.TempDir()
+}
+
+// shred overwrites the first size bytes of the file with zeros and removes it. Errors are
+// ignored since the file may already have been removed by the tool consuming it.
+func shred(path string, size int) {
+	if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+		_, _ = f.Write(make([]byte, size))
+		_ = f.Sync()
+		_ = f.Close()
+	}
+
+	_ = os.Remove(path)
+}
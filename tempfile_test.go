@@ -0,0 +1,59 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestGetSecretFile(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{"kubeconfig": "apiVersion: v1"})
+
+	path, cleanup, err := sm.GetSecretFile(context.Background(), c, "kubeconfig")
+	if err != nil {
+		t.Fatalf("GetSecretFile() error = %v", err)
+	}
+	defer cleanup()
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "apiVersion: v1" {
+		t.Fatalf("file holds %q, %v, want the secret value", data, err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("file permissions = %o, want 600", perm)
+		}
+	}
+
+	cleanup()
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Stat() error = %v after cleanup, want the file removed", err)
+	}
+
+	// Cleanup is idempotent, even once the file is gone
+	cleanup()
+}
+
+func TestGetSecretFileMissingKey(t *testing.T) {
+	path, cleanup, err := sm.GetSecretFile(context.Background(), newLoadedClient(t, nil), "missing")
+	if !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecretFile() error = %v, want ErrSecretNotFound", err)
+	}
+	if path != "" || cleanup != nil {
+		t.Errorf("GetSecretFile() = %q, %v, want no file on failure", path, cleanup != nil)
+	}
+}
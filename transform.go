@@ -0,0 +1,105 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+type (
+	// TransformFunc converts a raw secret value into the form expected by its consumers.
+	TransformFunc func(value string) (string, error)
+
+	// TransformClient decorates a SecretClient, applying the transforms registered for a
+	// key to its value on every GetSecret. It centralizes value cleanup such as trimming
+	// or decoding instead of repeating it at every call site.
+	TransformClient struct {
+		SecretClient
+
+		mu         sync.RWMutex
+		transforms map[string][]TransformFunc
+	}
+)
+
+// NewTransformClient wraps the given client with an empty transform registry.
+//
+// Parameters:
+//   - c: The secret client whose values are transformed
+//
+// Returns:
+//   - A TransformClient delegating to c
+func NewTransformClient(c SecretClient) *TransformClient {
+	return &TransformClient{
+		SecretClient: c,
+		transforms:   make(map[string][]TransformFunc),
+	}
+}
+
+// RegisterTransform registers fn to run on the value of key. Several transforms
+// registered for the same key run in registration order.
+func (t *TransformClient) RegisterTransform(key string, fn TransformFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.transforms[key] = append(t.transforms[key], fn)
+}
+
+// GetSecret retrieves the value of key from the wrapped client and applies its transforms.
+// Transform errors name the key but never include the value.
+func (t *TransformClient) GetSecret(ctx context.Context, key string) (string, error) {
+	value, err := t.SecretClient.GetSecret(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	t.mu.RLock()
+	transforms := t.transforms[key]
+	t.mu.RUnlock()
+
+	for _, fn := range transforms {
+		if value, err = fn(value); err != nil {
+			return "", fmt.Errorf("transform secret %q: %w", key, err)
+		}
+	}
+
+	return value, nil
+}
+
+// Reload forwards to the wrapped client when it implements Reloadable, and loads the
+// secrets again otherwise.
+func (t *TransformClient) Reload(ctx context.Context) error {
+	return reload(ctx, t.SecretClient)
+}
+
+// TrimSpace removes leading and trailing white space, such as a trailing newline
+// left when the secret was pasted.
+func TrimSpace(value string) (string, error) {
+	return strings.TrimSpace(value), nil
+}
+
+// URLDecode decodes a percent-encoded value. The decoding error is replaced by a generic
+// one since url errors quote the offending part of the value.
+func URLDecode(value string) (string, error) {
+	decoded, err := url.QueryUnescape(value)
+	if err != nil {
+		return "", errors.New("value is not valid percent-encoding")
+	}
+
+	return decoded, nil
+}
+
+// NormalizePEM turns escaped line breaks (`\n`, `\r\n`) of a PEM block pasted on a
+// single line into real newlines.
+func NormalizePEM(value string) (string, error) {
+	value = strings.ReplaceAll(value, `\r\n`, "\n")
+	value = strings.ReplaceAll(value, `\n`, "\n")
+
+	return strings.TrimSpace(value) + "\n", nil
+}
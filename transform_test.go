@@ -0,0 +1,64 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestTransformNormalizesPEM(t *testing.T) {
+	pasted := `-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUQ2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n`
+
+	c := sm.NewTransformClient(newLoadedClient(t, map[string]string{"tls.crt": pasted}))
+	c.RegisterTransform("tls.crt", sm.NormalizePEM)
+
+	value, err := c.GetSecret(context.Background(), "tls.crt")
+	if err != nil {
+		t.Fatalf("GetSecret() error = %v", err)
+	}
+
+	want := "-----BEGIN CERTIFICATE-----\nMIIBszCCAVmgAwIBAgIUQ2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n"
+	if value != want {
+		t.Errorf("GetSecret() = %q, want %q", value, want)
+	}
+
+	if block, _ := pem.Decode([]byte(value)); block == nil || block.Type != "CERTIFICATE" {
+		t.Errorf("GetSecret() = %q, want a decodable PEM block", value)
+	}
+}
+
+func TestTransformChain(t *testing.T) {
+	c := sm.NewTransformClient(newLoadedClient(t, map[string]string{
+		"dsn":   " user%3Apass \n",
+		"plain": " kept ",
+	}))
+	c.RegisterTransform("dsn", sm.TrimSpace)
+	c.RegisterTransform("dsn", sm.URLDecode)
+
+	if value, _ := c.GetSecret(context.Background(), "dsn"); value != "user:pass" {
+		t.Errorf("GetSecret(dsn) = %q, want transforms applied in order", value)
+	}
+	if value, _ := c.GetSecret(context.Background(), "plain"); value != " kept " {
+		t.Errorf("GetSecret(plain) = %q, want keys without transforms untouched", value)
+	}
+}
+
+func TestTransformErrorHidesValue(t *testing.T) {
+	c := sm.NewTransformClient(newLoadedClient(t, map[string]string{"token": "s3cret%zz"}))
+	c.RegisterTransform("token", sm.URLDecode)
+
+	_, err := c.GetSecret(context.Background(), "token")
+	if err == nil {
+		t.Fatal("GetSecret() error = nil, want the decoding failure")
+	}
+	if !strings.Contains(err.Error(), "token") || strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("error %q should name the key without the value", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidValue is returned by the typed accessors when a value cannot be parsed as
+	// the expected type.
+	ErrInvalidValue = errors.New("secret value has an invalid format")
+)
+
+// GetSecretInt retrieves the secret stored under key and parses it as a base 10 integer.
+// Surrounding white space is ignored.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The parsed integer
+//   - An error matching ErrInvalidValue, which never includes the value, if it isn't an integer
+//   - An error if the secret cannot be retrieved
+func GetSecretInt(ctx context.Context, c SecretClient, key string) (int, error) {
+	return getTyped(ctx, c, key, "int", strconv.Atoi)
+}
+
+// GetSecretBool retrieves the secret stored under key and parses it as a boolean, accepting
+// the forms of strconv.ParseBool. Surrounding white space is ignored.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The parsed boolean
+//   - An error matching ErrInvalidValue, which never includes the value, if it isn't a boolean
+//   - An error if the secret cannot be retrieved
+func GetSecretBool(ctx context.Context, c SecretClient, key string) (bool, error) {
+	return getTyped(ctx, c, key, "bool", strconv.ParseBool)
+}
+
+// GetSecretDuration retrieves the secret stored under key and parses it as a duration,
+// such as "1h30m", accepting the forms of time.ParseDuration. Surrounding white space is
+// ignored.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the value
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The parsed duration
+//   - An error matching ErrInvalidValue, which never includes the value, if it isn't a duration
+//   - An error if the secret cannot be retrieved
+func GetSecretDuration(ctx context.Context, c SecretClient, key string) (time.Duration, error) {
+	return getTyped(ctx, c, key, "duration", time.ParseDuration)
+}
+
+// getTyped retrieves key and converts it with parse. Parse errors are replaced by one
+// naming the key, the expected type and the value length, since the errors of strconv and
+// time quote the input.
+func getTyped[T any](ctx context.Context, c SecretClient, key, typeName string, parse func(string) (T, error)) (T, error) {
+	var zero T
+
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+
+	parsed, err := parse(strings.TrimSpace(value))
+	if err != nil {
+		return zero, fmt.Errorf("%w: secret %q is not a valid %s (%d bytes, value redacted)",
+			ErrInvalidValue, key, typeName, len(value))
+	}
+
+	return parsed, nil
+}
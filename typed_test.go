@@ -0,0 +1,79 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestTypedAccessors(t *testing.T) {
+	c := newLoadedClient(t, map[string]string{
+		"port":    " 5432\n",
+		"enabled": "true",
+		"timeout": "1m30s",
+	})
+	ctx := context.Background()
+
+	if port, err := sm.GetSecretInt(ctx, c, "port"); err != nil || port != 5432 {
+		t.Errorf("GetSecretInt() = %d, %v, want 5432", port, err)
+	}
+	if enabled, err := sm.GetSecretBool(ctx, c, "enabled"); err != nil || !enabled {
+		t.Errorf("GetSecretBool() = %v, %v, want true", enabled, err)
+	}
+	if timeout, err := sm.GetSecretDuration(ctx, c, "timeout"); err != nil || timeout != 90*time.Second {
+		t.Errorf("GetSecretDuration() = %v, %v, want 1m30s", timeout, err)
+	}
+}
+
+func TestTypedAccessorsRedactValue(t *testing.T) {
+	const value = "hunter2-s3cret"
+
+	c := newLoadedClient(t, map[string]string{"db_password": value})
+	ctx := context.Background()
+
+	tests := map[string]func() error{
+		"int": func() error {
+			_, err := sm.GetSecretInt(ctx, c, "db_password")
+			return err
+		},
+		"bool": func() error {
+			_, err := sm.GetSecretBool(ctx, c, "db_password")
+			return err
+		},
+		"duration": func() error {
+			_, err := sm.GetSecretDuration(ctx, c, "db_password")
+			return err
+		},
+	}
+
+	for typeName, get := range tests {
+		t.Run(typeName, func(t *testing.T) {
+			err := get()
+			if !errors.Is(err, sm.ErrInvalidValue) {
+				t.Fatalf("error = %v, want ErrInvalidValue", err)
+			}
+
+			msg := err.Error()
+			if strings.Contains(msg, value) || strings.Contains(msg, "hunter2") {
+				t.Errorf("error %q exposes the value", msg)
+			}
+			if !strings.Contains(msg, "db_password") || !strings.Contains(msg, typeName) {
+				t.Errorf("error %q should name the key and the expected type", msg)
+			}
+		})
+	}
+}
+
+func TestTypedAccessorsMissingKey(t *testing.T) {
+	if _, err := sm.GetSecretInt(context.Background(), newLoadedClient(t, nil), "port"); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("GetSecretInt() error = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrInvalidTemplate is returned by BuildURL when a placeholder isn't closed or is empty.
+	ErrInvalidTemplate = errors.New("invalid url template")
+)
+
+// BuildURL expands the {key} placeholders of template with the value of their secret,
+// producing a ready to use connection string:
+//
+//	url, err := BuildURL(ctx, client, "postgres://{user}:{password}@{host}:{port}/{db}")
+//
+// Every value is percent-encoded, so reserved characters such as '@', ':' or '/' in a
+// password cannot alter the structure of the URL. Text outside placeholders is kept as is.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client's GetSecret
+//   - c: The secret client holding the values
+//   - template: The URL with {key} placeholders
+//
+// Returns:
+//   - The expanded URL
+//   - ErrInvalidTemplate if a placeholder is malformed
+//   - An error aggregating every placeholder whose secret cannot be retrieved
+func BuildURL(ctx context.Context, c SecretClient, template string) (string, error) {
+	var b strings.Builder
+	var errs []error
+
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			b.WriteString(rest)
+			break
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: unclosed placeholder at offset %d", ErrInvalidTemplate, len(template)-len(rest)+start)
+		}
+		end += start
+
+		key := rest[start+1 : end]
+		if key == "" {
+			return "", fmt.Errorf("%w: empty placeholder at offset %d", ErrInvalidTemplate, len(template)-len(rest)+start)
+		}
+
+		b.WriteString(rest[:start])
+
+		value, err := c.GetSecret(ctx, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("url placeholder %q: %w", key, err))
+		} else {
+			b.WriteString(escapeURLComponent(value))
+		}
+
+		rest = rest[end+1:]
+	}
+
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+
+	return b.String(), nil
+}
+
+// escapeURLComponent percent-encodes value so it's safe in any part of a URL. Spaces are
+// encoded as %20 rather than '+', which is only meaningful in query strings.
+func escapeURLComponent(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+)
+
+var (
+	// ErrWeakSecret is returned when a secret value fails its ValueRule.
+	ErrWeakSecret = errors.New("secret value is too weak")
+)
+
+// ValueRule describes the minimum quality expected from a secret value, catching
+// provisioning mistakes such as blank, truncated or single-character passwords.
+// Zero fields disable the corresponding check.
+type ValueRule struct {
+	MinLength  int     // Minimum number of characters
+	MinEntropy float64 // Minimum Shannon entropy, in bits per character
+}
+
+// Validate checks value against the rule. The returned error names the key and the
+// failed check, matches ErrWeakSecret through errors.Is, and never includes the value.
+//
+// Parameters:
+//   - key: The secret key, used in the error message
+//   - value: The secret value to check
+//
+// Returns:
+//   - An error if the value fails the rule
+func (r ValueRule) Validate(key, value string) error {
+	if length := utf8.RuneCountInString(value); length < r.MinLength {
+		return fmt.Errorf("%w: %q has %d characters, minimum is %d", ErrWeakSecret, key, length, r.MinLength)
+	}
+
+	if r.MinEntropy > 0 {
+		if entropy := ShannonEntropy(value); entropy < r.MinEntropy {
+			return fmt.Errorf(
+				"%w: %q has an entropy of %.2f bits per character, minimum is %.2f",
+				ErrWeakSecret, key, entropy, r.MinEntropy,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ValidateSecrets checks every secret that has a rule and returns all failures joined.
+// Rules for keys absent from secrets are ignored.
+//
+// Parameters:
+//   - secrets: The secret values to check
+//   - rules: The rules indexed by secret key
+//
+// Returns:
+//   - An error aggregating every failed rule
+func ValidateSecrets(secrets map[string]string, rules map[string]ValueRule) error {
+	var errs []error
+	for key, rule := range rules {
+		if value, ok := secrets[key]; ok {
+			if err := rule.Validate(key, value); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ShannonEntropy returns the Shannon entropy of value in bits per character.
+func ShannonEntropy(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range value {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
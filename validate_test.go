@@ -0,0 +1,87 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+func TestValueRuleValidate(t *testing.T) {
+	rule := sm.ValueRule{MinLength: 8, MinEntropy: 2.5}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "strong", value: "x7#Kq9!vLm2$", wantErr: false},
+		{name: "blank", value: "", wantErr: true},
+		{name: "single character", value: "Z", wantErr: true},
+		{name: "repeated character", value: "ZZZZZZZZZZZZ", wantErr: true},
+		{name: "long but low entropy", value: "ZqZqZqZqZqZq", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rule.Validate("db_password", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+
+			if !errors.Is(err, sm.ErrWeakSecret) {
+				t.Errorf("Validate() error = %v, want ErrWeakSecret", err)
+			}
+			if !strings.Contains(err.Error(), "db_password") {
+				t.Errorf("error %q doesn't name the key", err)
+			}
+			if tt.value != "" && strings.Contains(err.Error(), tt.value) {
+				t.Errorf("error %q echoes the value", err)
+			}
+		})
+	}
+}
+
+func TestValidateSecrets(t *testing.T) {
+	secrets := map[string]string{"db_password": "a", "api_token": "zzz", "unchecked": ""}
+	rules := map[string]sm.ValueRule{
+		"db_password": {MinLength: 8},
+		"api_token":   {MinLength: 8},
+		"absent":      {MinLength: 8},
+	}
+
+	err := sm.ValidateSecrets(secrets, rules)
+	for _, key := range []string{"db_password", "api_token"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("ValidateSecrets() error = %v, want %s reported", err, key)
+		}
+	}
+	if strings.Contains(err.Error(), "absent") {
+		t.Errorf("ValidateSecrets() error = %v, want keys without values ignored", err)
+	}
+}
+
+func TestShannonEntropy(t *testing.T) {
+	tests := map[string]float64{
+		"":         0,
+		"aaaa":     0,
+		"abab":     1,
+		"abcd":     2,
+		"abcdefgh": 3,
+	}
+
+	for value, want := range tests {
+		if got := sm.ShannonEntropy(value); math.Abs(got-want) > 1e-9 {
+			t.Errorf("ShannonEntropy(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
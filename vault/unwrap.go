@@ -0,0 +1,230 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package vault provides a SecretClient implementation retrieving secrets handed over by
+// an orchestrator as a HashiCorp Vault response-wrapping token. The token is exchanged
+// once through the sys/wrapping/unwrap endpoint and the unwrapped secret is cached.
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/internal/redact"
+)
+
+const (
+	// DefaultTimeout bounds the unwrap request made by the client.
+	DefaultTimeout = 10 * time.Second
+
+	// unwrapPath is the Vault endpoint exchanging a wrapping token for the wrapped response.
+	unwrapPath = "/v1/sys/wrapping/unwrap"
+
+	// maxBodySize is the largest unwrap response accepted.
+	maxBodySize = 4 << 20
+)
+
+var (
+	// ErrWrappingTokenInvalid is returned when Vault rejects the wrapping token because it
+	// expired, was already unwrapped, or never existed.
+	ErrWrappingTokenInvalid = errors.New("wrapping token is not valid or does not exist")
+
+	// ErrWrappingTokenConsumed is returned when LoadSecrets is called again, since a
+	// wrapping token can only be unwrapped once.
+	ErrWrappingTokenConsumed = errors.New("wrapping token was already unwrapped")
+)
+
+type (
+	// unwrapSecretClient is an implementation of the SecretClient interface caching the
+	// secret obtained by unwrapping a Vault wrapping token.
+	unwrapSecretClient struct {
+		addr   string
+		token  string
+		client *http.Client
+
+		mu       sync.RWMutex
+		consumed bool                     // Whether the wrapping token was already used
+		secrets  map[string]redact.String // In-memory cache of secret key-value pairs
+	}
+
+	// Option configures optional behavior of the Vault client.
+	Option func(*unwrapSecretClient)
+
+	// unwrapResponse is the part of the Vault unwrap response holding the secret.
+	unwrapResponse struct {
+		Data   map[string]json.RawMessage `json:"data"`
+		Errors []string                   `json:"errors"`
+	}
+)
+
+// WithHTTPClient replaces the HTTP client used to reach Vault, e.g. to trust a private CA.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *unwrapSecretClient) {
+		c.client = client
+	}
+}
+
+// NewUnwrapSecretClient creates a client retrieving its secrets by unwrapping token
+// against the Vault server at addr.
+//
+// Parameters:
+//   - addr: The Vault address, e.g. "https://vault.internal:8200"
+//   - token: The response-wrapping token handed to the application
+//   - opts: Optional settings of the client
+//
+// Returns:
+//   - A SecretClient interface implementation backed by the wrapped secret
+func NewUnwrapSecretClient(addr, token string, opts ...Option) sm.SecretClient {
+	c := &unwrapSecretClient{
+		addr:    strings.TrimSuffix(addr, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: DefaultTimeout},
+		secrets: make(map[string]redact.String),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// LoadSecrets unwraps the token and caches the wrapped secret. The secret of a KV version
+// 2 read is taken from its nested "data" object. Values that aren't strings are cached
+// as their JSON encoding.
+//
+// Since the token is single-use, once Vault answered with the secret or rejected the
+// token, later calls return ErrWrappingTokenConsumed and keep the cache. A call failing
+// before Vault used the token, on a network or server error, can be retried.
+//
+// Parameters:
+//   - ctx: Context for controlling the request lifecycle
+//
+// Returns:
+//   - ErrWrappingTokenInvalid if the token expired or was already used
+//   - An error if the request fails or the response cannot be parsed
+func (c *unwrapSecretClient) LoadSecrets(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.consumed {
+		return ErrWrappingTokenConsumed
+	}
+
+	res, err := c.unwrap(ctx)
+	if err != nil {
+		return err
+	}
+
+	secrets, err := parseData(res.Data)
+	if err != nil {
+		return fmt.Errorf("parse unwrapped secret: %w", err)
+	}
+
+	c.secrets = redact.Map(secrets)
+	return nil
+}
+
+// GetSecret retrieves a specific secret value by its key from the in-memory cache.
+//
+// Parameters:
+//   - ctx: Context (not used in this implementation)
+//   - key: The secret key to look up
+//
+// Returns:
+//   - The secret value as a string if found
+//   - sm.ErrSecretNotFound if the key doesn't exist in the cache
+func (c *unwrapSecretClient) GetSecret(_ context.Context, key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.secrets[key]
+	if !ok {
+		return "", sm.ErrSecretNotFound
+	}
+
+	return value.Reveal(), nil
+}
+
+// unwrap calls the unwrap endpoint with the wrapping token, marking it consumed once Vault
+// used or rejected it. The caller must hold mu.
+func (c *unwrapSecretClient) unwrap(ctx context.Context) (*unwrapResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.addr+unwrapPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", c.token)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap secret: %w", err)
+	}
+	defer res.Body.Close()
+
+	// Vault used the token once it answers with the secret, or rejected it for good; other
+	// statuses, such as a sealed or unavailable server, leave the token usable
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode <= 299,
+		res.StatusCode == http.StatusBadRequest, res.StatusCode == http.StatusForbidden:
+		c.consumed = true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("read unwrap response: %w", err)
+	}
+
+	var out unwrapResponse
+	// Error responses are decoded on a best-effort basis to report Vault's message
+	_ = json.Unmarshal(body, &out)
+
+	switch {
+	case res.StatusCode == http.StatusBadRequest, res.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("unwrap secret: %w: status %d", ErrWrappingTokenInvalid, res.StatusCode)
+	case res.StatusCode < 200 || res.StatusCode > 299:
+		return nil, fmt.Errorf("unwrap secret: unexpected status %d: %s", res.StatusCode, strings.Join(out.Errors, "; "))
+	}
+
+	if out.Data == nil {
+		return nil, errors.New("unwrap secret: response holds no data")
+	}
+
+	return &out, nil
+}
+
+// parseData converts the unwrapped data into string values, descending into the nested
+// "data" object of a KV version 2 response.
+func parseData(data map[string]json.RawMessage) (map[string]string, error) {
+	if inner, ok := data["data"]; ok {
+		if _, isKV2 := data["metadata"]; isKV2 {
+			var nested map[string]json.RawMessage
+			if err := json.Unmarshal(inner, &nested); err != nil {
+				return nil, err
+			}
+
+			data = nested
+		}
+	}
+
+	secrets := make(map[string]string, len(data))
+	for key, raw := range data {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+
+		secrets[key] = value
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/vault"
+)
+
+// mockVault serves the unwrap endpoint, each wrapping token being usable once.
+type mockVault struct {
+	mu     sync.Mutex
+	tokens map[string]string // Unwrap response body by wrapping token
+}
+
+func (v *mockVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/sys/wrapping/unwrap" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	v.mu.Lock()
+	body, ok := v.tokens[r.Header.Get("X-Vault-Token")]
+	delete(v.tokens, r.Header.Get("X-Vault-Token"))
+	v.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":["wrapping token is not valid or does not exist"]}`))
+		return
+	}
+
+	_, _ = w.Write([]byte(body))
+}
+
+func newMockVault(t *testing.T, tokens map[string]string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(&mockVault{tokens: tokens})
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestUnwrapSecretClient(t *testing.T) {
+	srv := newMockVault(t, map[string]string{
+		"kv1": `{"data":{"db_password":"s3cret","port":5432}}`,
+		"kv2": `{"data":{"data":{"db_password":"s3cret"},"metadata":{"version":3}}}`,
+	})
+
+	tests := map[string]map[string]string{
+		"kv1": {"db_password": "s3cret", "port": "5432"},
+		"kv2": {"db_password": "s3cret"},
+	}
+
+	for token, want := range tests {
+		t.Run(token, func(t *testing.T) {
+			c := vault.NewUnwrapSecretClient(srv.URL+"/", token)
+			ctx := context.Background()
+
+			if err := c.LoadSecrets(ctx); err != nil {
+				t.Fatalf("LoadSecrets() error = %v", err)
+			}
+
+			for key, value := range want {
+				if got, err := c.GetSecret(ctx, key); err != nil || got != value {
+					t.Errorf("GetSecret(%q) = %q, %v, want %q", key, got, err, value)
+				}
+			}
+			if _, err := c.GetSecret(ctx, "metadata"); !errors.Is(err, sm.ErrSecretNotFound) {
+				t.Errorf("GetSecret(metadata) error = %v, want ErrSecretNotFound", err)
+			}
+		})
+	}
+}
+
+func TestUnwrapSecretClientTokenUsedOnce(t *testing.T) {
+	srv := newMockVault(t, map[string]string{"wrap": `{"data":{"db_password":"s3cret"}}`})
+	ctx := context.Background()
+
+	c := vault.NewUnwrapSecretClient(srv.URL, "wrap")
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v", err)
+	}
+
+	if err := c.LoadSecrets(ctx); !errors.Is(err, vault.ErrWrappingTokenConsumed) {
+		t.Errorf("LoadSecrets() error = %v on the second call, want ErrWrappingTokenConsumed", err)
+	}
+	if value, _ := c.GetSecret(ctx, "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want the cache kept", value)
+	}
+
+	// Another client handed the same, now used, token is rejected by Vault
+	err := vault.NewUnwrapSecretClient(srv.URL, "wrap").LoadSecrets(ctx)
+	if !errors.Is(err, vault.ErrWrappingTokenInvalid) {
+		t.Errorf("LoadSecrets() error = %v for a used token, want ErrWrappingTokenInvalid", err)
+	}
+}
+
+func TestUnwrapSecretClientExpiredToken(t *testing.T) {
+	srv := newMockVault(t, nil)
+
+	err := vault.NewUnwrapSecretClient(srv.URL, "expired").LoadSecrets(context.Background())
+	if !errors.Is(err, vault.ErrWrappingTokenInvalid) {
+		t.Errorf("LoadSecrets() error = %v, want ErrWrappingTokenInvalid", err)
+	}
+}
+
+// flakyTransport fails the first request before it reaches the server.
+type flakyTransport struct {
+	failed atomic.Bool
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !f.failed.Swap(true) {
+		return nil, errors.New("connection reset by peer")
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestUnwrapSecretClientRetryAfterTransportFailure(t *testing.T) {
+	srv := newMockVault(t, map[string]string{"wrap": `{"data":{"db_password":"s3cret"}}`})
+	ctx := context.Background()
+
+	c := vault.NewUnwrapSecretClient(srv.URL, "wrap", vault.WithHTTPClient(&http.Client{Transport: &flakyTransport{}}))
+
+	err := c.LoadSecrets(ctx)
+	if err == nil || errors.Is(err, vault.ErrWrappingTokenConsumed) {
+		t.Fatalf("LoadSecrets() error = %v, want the transport failure", err)
+	}
+
+	if err := c.LoadSecrets(ctx); err != nil {
+		t.Fatalf("LoadSecrets() error = %v on retry, want the token still usable", err)
+	}
+	if value, _ := c.GetSecret(ctx, "db_password"); value != "s3cret" {
+		t.Errorf("GetSecret() = %q, want s3cret", value)
+	}
+}
+
+func TestUnwrapSecretClientRetryAfterServerError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"errors":["Vault is sealed"]}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"db_password":"s3cret"}}`))
+	}))
+	defer srv.Close()
+
+	c := vault.NewUnwrapSecretClient(srv.URL, "wrap")
+	if err := c.LoadSecrets(context.Background()); err == nil {
+		t.Fatal("LoadSecrets() succeeded against a sealed Vault")
+	}
+	if err := c.LoadSecrets(context.Background()); err != nil {
+		t.Errorf("LoadSecrets() error = %v on retry, want the token still usable", err)
+	}
+}
+
+func TestUnwrapSecretClientRejectedTokenConsumed(t *testing.T) {
+	srv := newMockVault(t, nil)
+	c := vault.NewUnwrapSecretClient(srv.URL, "expired")
+
+	_ = c.LoadSecrets(context.Background())
+	if err := c.LoadSecrets(context.Background()); !errors.Is(err, vault.ErrWrappingTokenConsumed) {
+		t.Errorf("LoadSecrets() error = %v after Vault rejected the token, want ErrWrappingTokenConsumed", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+)
+
+// WarmAsync starts loading the secrets of c in the background so startup can carry on
+// with other work, and returns a channel reporting the outcome.
+//
+// The channel receives exactly one value, nil on success or the error returned by
+// LoadSecrets, and is then closed. It's buffered so the load never blocks when the
+// result isn't read. Cancelling ctx aborts the load as LoadSecrets would.
+//
+// Parameters:
+//   - ctx: Context forwarded to LoadSecrets
+//   - c: The secret client to load
+//
+// Returns:
+//   - A channel delivering the load result
+func WarmAsync(ctx context.Context, c SecretClient) <-chan error {
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+		done <- c.LoadSecrets(ctx)
+	}()
+
+	return done
+}
+
+// AwaitReady blocks until the load started by WarmAsync completes or ctx is done.
+//
+// Parameters:
+//   - ctx: Context bounding the wait
+//   - ready: The channel returned by WarmAsync
+//
+// Returns:
+//   - The load error, or ctx.Err() if the wait is abandoned first
+func AwaitReady(ctx context.Context, ready <-chan error) error {
+	select {
+	case err := <-ready:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
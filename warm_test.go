@@ -0,0 +1,57 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sm "github.com/goxkit/secretsmanager"
+	"github.com/goxkit/secretsmanager/fake"
+)
+
+func TestWarmAsync(t *testing.T) {
+	c := fake.NewFakeClient(fake.WithSeed(map[string]string{"api_token": "t0ken"}))
+
+	ready := sm.WarmAsync(context.Background(), c)
+	if err := sm.AwaitReady(context.Background(), ready); err != nil {
+		t.Fatalf("AwaitReady() error = %v", err)
+	}
+
+	if value, _ := c.GetSecret(context.Background(), "api_token"); value != "t0ken" {
+		t.Errorf("GetSecret() = %q once warm, want t0ken", value)
+	}
+	if _, ok := <-ready; ok {
+		t.Error("ready channel still open after delivering the result")
+	}
+}
+
+func TestWarmAsyncFailure(t *testing.T) {
+	errBackend := errors.New("backend unavailable")
+
+	c := fake.NewFakeClient()
+	c.FailNextLoad(errBackend)
+
+	if err := sm.AwaitReady(context.Background(), sm.WarmAsync(context.Background(), c)); !errors.Is(err, errBackend) {
+		t.Errorf("AwaitReady() error = %v, want the load failure", err)
+	}
+}
+
+func TestAwaitReadyAbandoned(t *testing.T) {
+	c := fake.NewFakeClient(fake.WithLatency(time.Hour))
+
+	loadCtx, cancelLoad := context.WithCancel(context.Background())
+	defer cancelLoad()
+	ready := sm.WarmAsync(loadCtx, c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := sm.AwaitReady(ctx, ready); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AwaitReady() error = %v, want the wait abandoned", err)
+	}
+}
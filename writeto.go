@@ -0,0 +1,39 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// WriteSecretTo writes the value of key to w, for large binary secrets such as
+// certificate bundles or keystores that are consumed as a stream. Providers implementing
+// Streamer write the value themselves; for the others the cached value is written
+// directly, without copying it into a byte slice. Values are written as the provider
+// decoded them, e.g. the aws client's binary secrets with WithBase64Binary.
+//
+// Parameters:
+//   - ctx: Context forwarded to the client
+//   - c: The secret client holding the value
+//   - key: The secret key to write
+//   - w: The destination of the value
+//
+// Returns:
+//   - The number of bytes written to w
+//   - An error if the secret cannot be retrieved or w fails
+func WriteSecretTo(ctx context.Context, c SecretClient, key string, w io.Writer) (int64, error) {
+	if s, ok := c.(Streamer); ok {
+		return s.WriteSecretTo(ctx, key, w)
+	}
+
+	value, err := c.GetSecret(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	return strings.NewReader(value).WriteTo(w)
+}
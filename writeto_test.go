@@ -0,0 +1,69 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package secretsmanager_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	sm "github.com/goxkit/secretsmanager"
+)
+
+// streamingClient is a mapClient writing its values itself, as a per-key provider
+// streaming the backend response would.
+type streamingClient struct {
+	mapClient
+	streamed int
+}
+
+func (s *streamingClient) WriteSecretTo(ctx context.Context, key string, w io.Writer) (int64, error) {
+	s.streamed++
+
+	value, err := s.GetSecret(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.WriteString(w, value)
+	return int64(n), err
+}
+
+func TestWriteSecretTo(t *testing.T) {
+	keystore := string(bytes.Repeat([]byte{0x00, 0xff, 0x7f, 'k'}, 64<<10))
+	c := newLoadedClient(t, map[string]string{"keystore": keystore})
+
+	var buf bytes.Buffer
+	n, err := sm.WriteSecretTo(context.Background(), c, "keystore", &buf)
+	if err != nil {
+		t.Fatalf("WriteSecretTo() error = %v", err)
+	}
+
+	if n != int64(len(keystore)) {
+		t.Errorf("WriteSecretTo() = %d bytes, want %d", n, len(keystore))
+	}
+	if buf.String() != keystore {
+		t.Error("WriteSecretTo() wrote different bytes than the value")
+	}
+
+	if _, err := sm.WriteSecretTo(context.Background(), c, "missing", &buf); !errors.Is(err, sm.ErrSecretNotFound) {
+		t.Errorf("WriteSecretTo() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestWriteSecretToStreamer(t *testing.T) {
+	c := &streamingClient{mapClient: mapClient{values: map[string]string{"tls.crt": "CERTIFICATE"}}}
+
+	var buf bytes.Buffer
+	n, err := sm.WriteSecretTo(context.Background(), c, "tls.crt", &buf)
+	if err != nil || n != int64(len("CERTIFICATE")) || buf.String() != "CERTIFICATE" {
+		t.Errorf("WriteSecretTo() = %d, %v, wrote %q, want the value", n, err, buf.String())
+	}
+	if c.streamed != 1 {
+		t.Errorf("streamed = %d, want the provider's own WriteSecretTo used", c.streamed)
+	}
+}